@@ -16,6 +16,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -23,6 +24,10 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/CloudCoreo/cli/client/content"
 )
 
 const cspURL = "https://console.cloud.vmware.com"
@@ -31,10 +36,22 @@ const cspResource = "/csp/gateway/am/api/auth/api-tokens/authorize"
 // Auth struct for API and secret key
 type Auth struct {
 	RefreshToken string
+	// CSPEndpoint is the CSP identity endpoint the refresh token is exchanged
+	// at, it is discovered from the API or defaults to cspURL when empty.
+	CSPEndpoint string
+	// CacheFile is where the access token is persisted between runs. The
+	// token is only kept in memory when it is empty.
+	CacheFile string
+
+	mu       sync.Mutex
+	token    *cachedToken
+	discover func(ctx context.Context) string
+	client   *http.Client
 }
 
 type cspToken struct {
 	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
 }
 
 // SignRequest method to sign all requests
@@ -48,40 +65,110 @@ func (a *Auth) SignRequest(req *http.Request) error {
 		req.ContentLength = int64(len(body))
 	}
 
-	cspToken, err := a.getCspAuthToken()
+	accessToken, err := a.accessToken(req.Context())
 	if err != nil {
 		return err
 	}
-	req.Header.Add("csp-auth-token", cspToken.AccessToken)
+	req.Header.Set("csp-auth-token", accessToken)
 	return nil
 }
 
-func (a *Auth) getCspAuthToken() (*cspToken, error) {
+// Invalidate drops the cached access token so that the next request
+// exchanges the refresh token again.
+func (a *Auth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = nil
+	a.removeCachedToken()
+}
+
+func (a *Auth) accessToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.token == nil {
+		a.token = a.loadCachedToken()
+	}
+	if a.token.valid(a.RefreshToken, now) {
+		return a.token.AccessToken, nil
+	}
+
+	cspToken, err := a.getCspAuthToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token = newCachedToken(cspToken, a.RefreshToken, now)
+	// The cache only saves round trips, a failure to persist it must not
+	// fail the request.
+	a.saveCachedToken()
+
+	return a.token.AccessToken, nil
+}
+
+func (a *Auth) httpClient() *http.Client {
+	if a.client != nil {
+		return a.client
+	}
+	return http.DefaultClient
+}
+
+// cspEndpoint returns the configured CSP endpoint, discovering it on first use.
+// The refresh token is sent to the endpoint, so a discovered one must use
+// https, other schemes have to be configured explicitly.
+func (a *Auth) cspEndpoint(ctx context.Context) (string, error) {
+	if a.CSPEndpoint != "" {
+		return a.CSPEndpoint, nil
+	}
+
+	var endpoint string
+	if a.discover != nil {
+		endpoint = a.discover(ctx)
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+	if endpoint == "" {
+		endpoint = cspURL
+	}
+	if u, err := url.Parse(endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", NewError(fmt.Sprintf(content.ErrorInsecureCSPEndpoint, endpoint))
+	}
+	a.CSPEndpoint = endpoint
+	return a.CSPEndpoint, nil
+}
+
+func (a *Auth) getCspAuthToken(ctx context.Context) (*cspToken, error) {
 	cspToken := new(cspToken)
 
 	data := url.Values{}
 	data.Set("refresh_token", a.RefreshToken)
 
-	url, _ := url.ParseRequestURI(cspURL)
-	url.Path = cspResource
+	endpoint, err := a.cspEndpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	url.Path = strings.TrimRight(url.Path, "/") + cspResource
 
-	req, err := http.NewRequest("POST", url.String(), strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", url.String(), strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Content-Length", strconv.Itoa(len(data.Encode())))
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := a.httpClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		message := new(bytes.Buffer)
-		message.ReadFrom(resp.Body)
-		msg := fmt.Sprintf("%s", message.String())
-		return nil, NewError(msg)
+		return nil, newAPIError(resp)
 	}
 
 	err = json.NewDecoder(resp.Body).Decode(cspToken)
@@ -17,8 +17,12 @@ package client
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 
@@ -45,3 +49,76 @@ func TestSignRequest(t *testing.T) {
 
 	assert.Contains(t, authToken, "fake-access-token", "Request Authorization header doesn't contain csp-auth-token.")
 }
+
+func TestSignRequestReusesCachedToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	auth := &Auth{RefreshToken: "asdf"}
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", "", nil)
+		assert.Nil(t, auth.SignRequest(req), "SignRequest shouldn't return error.")
+		assert.Equal(t, "fake-access-token", req.Header.Get("csp-auth-token"))
+	}
+
+	assert.Equal(t, 1, httpmock.GetTotalCallCount(), "refresh token should be exchanged only once.")
+}
+
+func TestSignRequestRefreshesExpiringToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	auth := &Auth{RefreshToken: "asdf"}
+	auth.token = &cachedToken{
+		AccessToken:      "old-access-token",
+		Expiry:           time.Now().Add(tokenExpirySkew / 2),
+		RefreshTokenHash: hashRefreshToken("asdf"),
+	}
+	req, _ := http.NewRequest("GET", "", nil)
+	auth.SignRequest(req)
+
+	assert.Equal(t, "fake-access-token", req.Header.Get("csp-auth-token"))
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func TestSignRequestPersistsToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	dir, err := ioutil.TempDir("", "vss-token-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "tokens", "default.json")
+
+	req, _ := http.NewRequest("GET", "", nil)
+	(&Auth{RefreshToken: "asdf", CacheFile: cacheFile}).SignRequest(req)
+
+	info, err := os.Stat(cacheFile)
+	assert.Nil(t, err, "token cache file should be written.")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	req, _ = http.NewRequest("GET", "", nil)
+	(&Auth{RefreshToken: "asdf", CacheFile: cacheFile}).SignRequest(req)
+	assert.Equal(t, "fake-access-token", req.Header.Get("csp-auth-token"))
+	assert.Equal(t, 1, httpmock.GetTotalCallCount(), "cached token should be loaded from file.")
+
+	req, _ = http.NewRequest("GET", "", nil)
+	(&Auth{RefreshToken: "other", CacheFile: cacheFile}).SignRequest(req)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount(), "token of another refresh token shouldn't be used.")
+}
+
+func TestSignRequestUsesCSPEndpoint(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost:8080/csp"+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	auth := &Auth{RefreshToken: "asdf", CSPEndpoint: "http://localhost:8080/csp/"}
+	req, _ := http.NewRequest("GET", "", nil)
+	err := auth.SignRequest(req)
+
+	assert.Nil(t, err, "SignRequest shouldn't return error.")
+	assert.Equal(t, "fake-access-token", req.Header.Get("csp-auth-token"))
+}
@@ -28,7 +28,14 @@ import (
 )
 
 type clientOptions struct {
-	interceptor Interceptor
+	// interceptor signs requests, it runs before the interceptors added with WithInterceptor.
+	interceptor    Interceptor
+	interceptors   []Interceptor
+	httpClient     *http.Client
+	userAgent      string
+	tokenCacheFile string
+	cspEndpoint    string
+	retry          RetryPolicy
 }
 
 // Option type
@@ -39,31 +46,70 @@ type Option func(*clientOptions)
 type Interceptor func(*http.Request) error
 
 // WithInterceptor returns a ClientOption for adding an interceptor
-// to a Client.
+// to a Client. Interceptors run in the order they were added.
 func WithInterceptor(ci Interceptor) Option {
 	return func(opts *clientOptions) {
-		opts.interceptor = ci
+		opts.interceptors = append(opts.interceptors, ci)
+	}
+}
+
+// WithHTTPClient returns a ClientOption for sending the requests of a Client,
+// including the CSP token exchange, with the given http.Client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(opts *clientOptions) {
+		opts.httpClient = hc
+	}
+}
+
+// WithUserAgent returns a ClientOption for setting the User-Agent header
+// of the requests of a Client.
+func WithUserAgent(userAgent string) Option {
+	return func(opts *clientOptions) {
+		opts.userAgent = userAgent
+	}
+}
+
+// WithCSPEndpoint returns a ClientOption for exchanging the refresh token of
+// a Client at the given CSP endpoint instead of the discovered one.
+func WithCSPEndpoint(endpoint string) Option {
+	return func(opts *clientOptions) {
+		opts.cspEndpoint = endpoint
+	}
+}
+
+// WithTokenCacheFile returns a ClientOption for persisting the CSP access
+// token of a Client in the given file.
+func WithTokenCacheFile(path string) Option {
+	return func(opts *clientOptions) {
+		opts.tokenCacheFile = path
 	}
 }
 
 // Client struct
 type Client struct {
-	client   http.Client
+	client   *http.Client
 	endpoint string
 	opts     clientOptions
-	auth     Auth
+	auth     *Auth
 }
 
 // MakeClient make client
-func MakeClient(refreshToken, endpoint string) (*Client, error) {
+func MakeClient(refreshToken, endpoint string, opts ...Option) (*Client, error) {
 
 	if refreshToken == "None" || refreshToken == "" {
 		return nil, NewError(content.ErrorMissingAPIOrSecretKey)
 	}
 
-	a := Auth{RefreshToken: refreshToken}
-	i := Interceptor(a.SignRequest)
-	c := newClient(endpoint, WithInterceptor(i))
+	c := newClient(endpoint, opts...)
+	a := &Auth{
+		RefreshToken: refreshToken,
+		CSPEndpoint:  c.opts.cspEndpoint,
+		CacheFile:    c.opts.tokenCacheFile,
+		discover:     c.discoverCSPEndpoint,
+		client:       c.client,
+	}
+	c.auth = a
+	c.opts.interceptor = Interceptor(a.SignRequest)
 
 	return c, nil
 }
@@ -79,25 +125,55 @@ func newClient(endpoint string, opts ...Option) *Client {
 		opt(&client.opts)
 	}
 
+	client.client = client.opts.httpClient
+	if client.client == nil {
+		client.client = &http.Client{}
+	}
+
 	return client
 }
 
 // Do performs an HTTP request with a given context - the response will be decoded
 // into obj.
 func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, obj interface{}) error {
+	// The body is buffered so that the request can be sent again.
+	var payload []byte
+	if body != nil {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		payload = b
+	}
 
-	resp, err := c.makeRequest(ctx, method, path, body)
+	if c.opts.retry.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.retry.Timeout)
+		defer cancel()
+	}
+
+	resp, err := c.doWithRetry(ctx, method, path, payload)
 	if err != nil {
 		return err
 	}
 
+	if resp.StatusCode == http.StatusUnauthorized && c.auth != nil {
+		// The cached access token may have been revoked before it expired,
+		// exchange the refresh token again and retry once.
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		c.auth.Invalidate()
+
+		resp, err = c.doWithRetry(ctx, method, path, payload)
+		if err != nil {
+			return err
+		}
+	}
+
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		message := new(bytes.Buffer)
-		message.ReadFrom(resp.Body)
-		msg := fmt.Sprintf("%s", message.String())
-		return NewError(msg)
+		return newAPIError(resp)
 	}
 
 	// Read all of resp.Body regardless of status code so we don't leak connections.
@@ -112,17 +188,22 @@ func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, ob
 	return err
 }
 
-func (c *Client) makeRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
-	req, err := c.buildRequest(method, path, body)
+func (c *Client) makeRequest(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := c.buildRequest(ctx, method, path, body)
 	if err != nil {
 		return nil, err
 	}
-	return ctxhttp.Do(ctx, &c.client, req)
+	return ctxhttp.Do(ctx, c.client, req)
 }
 
-func (c *Client) buildRequest(method, path string, body io.Reader) (*http.Request, error) {
+// buildRequest returns the signed request, ctx also bounds the token exchange signing it
+func (c *Client) buildRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	urlPath := fmt.Sprintf("%s/%s", c.endpoint, path)
-	req, err := http.NewRequest(method, urlPath, body)
+	req, err := http.NewRequestWithContext(ctx, method, urlPath, body)
 	if err != nil {
 		return nil, err
 	}
@@ -130,11 +211,20 @@ func (c *Client) buildRequest(method, path string, body io.Reader) (*http.Reques
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	if c.opts.userAgent != "" {
+		req.Header.Set("User-Agent", c.opts.userAgent)
+	}
+
 	if c.opts.interceptor != nil {
 		if err := c.opts.interceptor(req); err != nil {
 			return nil, err
 		}
 	}
+	for _, interceptor := range c.opts.interceptors {
+		if err := interceptor(req); err != nil {
+			return nil, err
+		}
+	}
 
 	return req, nil
 }
@@ -16,6 +16,7 @@ package client
 
 // Basic imports
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -107,6 +108,27 @@ func (suite *DoTestSuite) TestDo() {
 	assert.Equal(suite.T(), "cloudAccountID", accounts[0].ID)
 }
 
+func (suite *DoTestSuite) TestDoRefreshesTokenAfterUnauthorized() {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return httpmock.NewStringResponse(http.StatusUnauthorized, `{}`), nil
+		}
+		return httpmock.NewStringResponse(http.StatusOK, `[]`), nil
+	})
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint)
+	accounts := make([]*CloudAccount, 0)
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, &accounts)
+	assert.Nil(suite.T(), err, "Do shouldn't return error after refreshing the token.")
+	assert.Equal(suite.T(), 2, calls)
+	assert.Equal(suite.T(), 2, httpmock.GetCallCountInfo()["POST "+cspURL+cspResource])
+}
+
 // TestDoTestSuite Execute TestDoTestSuite test suite
 func TestDoTestSuite(t *testing.T) {
 	setupTester := new(DoTestSuite)
@@ -117,7 +139,139 @@ func TestBuildRequest(t *testing.T) {
 
 	i := Interceptor(func(req *http.Request) error { return fmt.Errorf("Return error") })
 	c := newClient("http://test.com", WithInterceptor(i))
-	_, err := c.buildRequest("GET", "http://test.com", nil)
+	_, err := c.buildRequest(context.Background(), "GET", "http://test.com", nil)
 
 	assert.NotNil(t, err, "buildRequest should return error.")
 }
+
+func TestDoReturnsAPIError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts/missing", func(req *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusNotFound, `{"code": 4041, "message": "Cloud account not found"}`)
+		resp.Header.Set("X-Request-Id", "request-id")
+		return resp, nil
+	})
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint)
+	err := client.Do(context.Background(), "GET", "cloudaccounts/missing", nil, nil)
+
+	apiErr, ok := err.(*APIError)
+	assert.True(t, ok, "Do should return an APIError.")
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "4041", apiErr.Code)
+	assert.Equal(t, "Cloud account not found", apiErr.Message)
+	assert.Equal(t, "request-id", apiErr.RequestID)
+	assert.Equal(t, "Cloud account not found (request id: request-id)", err.Error())
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsUnauthorized(err))
+}
+
+func TestDoReturnsAPIErrorWithPlainBody(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", httpmock.NewStringResponder(http.StatusForbidden, "Access denied\n"))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint)
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+
+	assert.Equal(t, "Access denied", err.Error())
+	assert.True(t, IsForbidden(err))
+}
+
+func TestMakeClientDiscoversCSPEndpoint(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/.well-known/vss-configuration", httpmock.NewStringResponder(http.StatusOK, `{"cspEndpoint": "https://csp.staging.test"}`))
+	httpmock.RegisterResponder("POST", "https://csp.staging.test"+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", httpmock.NewStringResponder(http.StatusOK, `[]`))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint)
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+
+	assert.Nil(t, err, "Do shouldn't return error.")
+	assert.Equal(t, "https://csp.staging.test", client.auth.CSPEndpoint)
+}
+
+func TestMakeClientRejectsInsecureCSPEndpoint(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/.well-known/vss-configuration", httpmock.NewStringResponder(http.StatusOK, `{"cspEndpoint": "http://csp.attacker.test"}`))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint)
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+
+	assert.Contains(t, err.Error(), "http://csp.attacker.test discovered from the API doesn't use https")
+	assert.Equal(t, 1, httpmock.GetTotalCallCount(), "the refresh token mustn't be sent.")
+}
+
+func TestMakeClientCancelsCSPDiscovery(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/.well-known/vss-configuration", httpmock.NewStringResponder(http.StatusOK, `{"cspEndpoint": "https://csp.staging.test"}`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint)
+	err := client.Do(ctx, "GET", "cloudaccounts", nil, nil)
+
+	// the token exchange has no responder, sending it would fail differently
+	assert.True(t, errors.Is(err, context.Canceled), "%v", err)
+	assert.Equal(t, "", client.auth.CSPEndpoint, "a cancelled discovery isn't cached.")
+}
+
+func TestMakeClientPrefersConfiguredCSPEndpoint(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "https://csp.local.test"+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", httpmock.NewStringResponder(http.StatusOK, `[]`))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint, WithCSPEndpoint("https://csp.local.test"))
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+
+	assert.Nil(t, err, "Do shouldn't return error.")
+	assert.Equal(t, 0, httpmock.GetCallCountInfo()["GET "+defaultAPIEndpoint+"/.well-known/vss-configuration"])
+}
+
+func TestMakeClientSharesHTTPClient(t *testing.T) {
+	hc := &http.Client{}
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "vss-cli/test", req.UserAgent())
+		assert.Equal(t, "signed,first,second", req.Header.Get("X-Trace"))
+		return httpmock.NewStringResponse(http.StatusOK, `[]`), nil
+	})
+
+	trace := func(value string) Interceptor {
+		return func(req *http.Request) error {
+			if v := req.Header.Get("X-Trace"); v != "" {
+				value = v + "," + value
+			}
+			req.Header.Set("X-Trace", value)
+			return nil
+		}
+	}
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint,
+		WithHTTPClient(hc),
+		WithCSPEndpoint(cspURL),
+		WithUserAgent("vss-cli/test"),
+		WithInterceptor(trace("first")),
+		WithInterceptor(trace("second")))
+	signer := client.opts.interceptor
+	client.opts.interceptor = func(req *http.Request) error {
+		if err := signer(req); err != nil {
+			return err
+		}
+		req.Header.Set("X-Trace", "signed")
+		return nil
+	}
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+
+	assert.Nil(t, err, "Do shouldn't return error.")
+	assert.Equal(t, 2, httpmock.GetTotalCallCount(), "token exchange and request should use the given http.Client")
+}
@@ -17,9 +17,14 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"math/big"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/imdario/mergo"
@@ -53,43 +58,116 @@ type CreateCloudAccountInput struct {
 	ApplicationID  string
 	DirectoryID    string
 	SubscriptionID string
-	Tags           string
+	// GCP service account, authenticated either with ServiceAccountKey, the
+	// JSON key of the service account, or with WorkloadIdentityProvider
+	ProjectID                string
+	ServiceAccountEmail      string
+	ServiceAccountKey        string
+	WorkloadIdentityProvider string
+	Tags                     string
+	// TagList replaces Tags when it isn't nil, an empty list removes all tags of an updated account
+	TagList Tags
+	// ScanSettings replaces the default scan settings when set
+	ScanSettings *ScanSettings
+}
+
+//ScanSettings are the scan settings of a cloud account, empty Interval and Region keep their current value
+type ScanSettings struct {
+	Enabled  bool
+	Interval string
+	Region   string
+}
+
+func (s *ScanSettings) apply(info *CloudInfo) {
+	if s == nil {
+		return
+	}
+	info.ScanEnabled = s.Enabled
+	if s.Interval != "" {
+		info.ScanInterval = s.Interval
+	}
+	if s.Region != "" {
+		info.ScanRegion = s.Region
+	}
 }
 
 //CloudInfo listed all info of cloud accounts
 type CloudInfo struct {
-	Name                string   `json:"name,omitempty"`
-	Arn                 string   `json:"arn,omitempty"`
-	ScanEnabled         bool     `json:"scanEnabled"`
-	ScanInterval        string   `json:"scanInterval"`
-	ScanRegion          string   `json:"scanRegion"`
-	ExternalID          string   `json:"externalId,omitempty"`
-	IsDraft             bool     `json:"isDraft"`
-	Provider            string   `json:"provider"`
-	Email               string   `json:"email,omitempty"`
-	UserName            string   `json:"username,omitempty"`
-	Environment         string   `json:"environment,omitempty"`
-	KeyValue            string   `json:"key,omitempty"`
-	ApplicationID       string   `json:"appId,omitempty"`
-	DirectoryID         string   `json:"directoryId,omitempty"`
-	SubscriptionID      string   `json:"subscriptionId,omitempty"`
-	Tags                []string `json:"tags,omitempty"`
-	IsValid             bool     `json:"isValid"`
-	LastValidationCheck string   `json:"lastValidationCheck"`
+	Name                     string `json:"name,omitempty"`
+	Arn                      string `json:"arn,omitempty"`
+	ScanEnabled              bool   `json:"scanEnabled"`
+	ScanInterval             string `json:"scanInterval"`
+	ScanRegion               string `json:"scanRegion"`
+	ExternalID               string `json:"externalId,omitempty"`
+	IsDraft                  bool   `json:"isDraft"`
+	Provider                 string `json:"provider"`
+	Email                    string `json:"email,omitempty"`
+	UserName                 string `json:"username,omitempty"`
+	Environment              string `json:"environment,omitempty"`
+	KeyValue                 string `json:"key,omitempty"`
+	ApplicationID            string `json:"appId,omitempty"`
+	DirectoryID              string `json:"directoryId,omitempty"`
+	SubscriptionID           string `json:"subscriptionId,omitempty"`
+	ProjectID                string `json:"projectId,omitempty"`
+	ServiceAccountEmail      string `json:"serviceAccountEmail,omitempty"`
+	ServiceAccountKey        string `json:"serviceAccountKey,omitempty"`
+	WorkloadIdentityProvider string `json:"workloadIdentityProvider,omitempty"`
+	Tags                     Tags   `json:"tags,omitempty"`
+	IsValid                  bool   `json:"isValid"`
+	LastValidationCheck      string `json:"lastValidationCheck"`
 }
 
+// wellKnownConfigPath is the path of the public VSS configuration document
+const wellKnownConfigPath = ".well-known/vss-configuration"
+
 type defaultID struct {
-	AccountID  string `json:"accountId"`
-	ExternalID string `json:"externalId"`
-	Domain     string `json:"domain"`
+	AccountID   string `json:"accountId"`
+	ExternalID  string `json:"externalId"`
+	Domain      string `json:"domain"`
+	CSPEndpoint string `json:"cspEndpoint"`
+	// PolicyCatalog are the actions the role of an AWS cloud account needs, the CLI ships a default
+	PolicyCatalog PolicyCatalog `json:"policyCatalog,omitempty"`
 }
 
+//PolicyCatalog lists the IAM actions VSS calls by AWS service prefix, e.g.
+//"ec2": ["DescribeInstances"]. The inline policy of a role allows exactly these.
+type PolicyCatalog map[string][]string
+
 //RoleCreationInfo contains the info required for role creation
 type RoleCreationInfo struct {
 	AwsAccount string
 	ExternalID string
 	RoleName   string
 	Policy     string
+	// SubscriptionID is the Azure subscription the service principal is assigned roles in
+	SubscriptionID string
+	// InlinePolicy puts an inline policy allowing the actions of Catalog in place of attaching Policy
+	InlinePolicy bool
+	Catalog      PolicyCatalog
+}
+
+//ServicePrincipal is the Azure service principal created for a cloud account
+type ServicePrincipal struct {
+	ApplicationID string
+	DirectoryID   string
+	KeyValue      string
+}
+
+//ServiceAccount is the GCP service account created for a cloud account, Key is
+//its JSON key, empty when a workload identity provider impersonates it
+type ServiceAccount struct {
+	Email string
+	Key   string
+}
+
+//Secret is a client secret of an Azure application. Value is only known when
+//the secret is created, Hint are its first characters.
+type Secret struct {
+	KeyID       string
+	DisplayName string
+	Hint        string
+	EndDateTime string
+	Value       string
 }
 
 //RoleReValidationResult is the result for role re-validation
@@ -102,25 +180,92 @@ type RoleReValidationResult struct {
 type UpdateCloudAccountInput struct {
 	CreateCloudAccountInput
 	CloudID string
+	// Fields are the JSON names of the CloudInfo fields to update. When set, only
+	// these fields are taken from the input, which may also set them to false or
+	// empty, and all others keep their current value.
+	Fields []string
 }
 
-// GetCloudAccounts method for cloud command
-func (c *Client) GetCloudAccounts(ctx context.Context) ([]*CloudAccount, error) {
-	// clouds := []*CloudAccount{}
-	clouds := make([]*CloudAccount, 0)
+// CloudAccountIterator iterates over cloud accounts, fetching pages as needed
+type CloudAccountIterator struct {
+	it iterator
+}
 
-	err := c.Do(ctx, "GET", "cloudaccounts", nil, &clouds)
-	if err != nil {
-		return nil, NewError(err.Error())
-	}
-	for _, account := range clouds {
-		if account.Provider == "Azure" {
-			account.AccountID = account.SubscriptionID
+// Next advances to the next cloud account, it returns false when there are no more or on error.
+func (i *CloudAccountIterator) Next(ctx context.Context) bool {
+	return i.it.next(ctx)
+}
+
+// CloudAccount returns the current cloud account
+func (i *CloudAccountIterator) CloudAccount() *CloudAccount {
+	account, _ := i.it.cur.(*CloudAccount)
+	return account
+}
+
+// Err returns the error that stopped the iteration
+func (i *CloudAccountIterator) Err() error {
+	return i.it.err
+}
+
+// CloudAccounts returns an iterator over the cloud accounts, no request is sent before Next is called
+func (c *Client) CloudAccounts(opts ListOptions) *CloudAccountIterator {
+	// Endpoints that don't support offset/limit return all accounts on every
+	// request and pages may overlap while accounts are added, seen skips the
+	// accounts already returned and stops the iteration on a page of only those.
+	seen := make(map[string]bool)
+
+	fetch := func(ctx context.Context, cursor pageCursor, pageSize int) ([]interface{}, pageCursor, bool, error) {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(pageSize))
+		if cursor.ContinuationToken != "" {
+			query.Set("continuationToken", cursor.ContinuationToken)
+		} else {
+			query.Set("offset", strconv.Itoa(cursor.Offset))
 		}
+
+		var raw json.RawMessage
+		if err := c.Do(ctx, "GET", "cloudaccounts?"+query.Encode(), nil, &raw); err != nil {
+			return nil, cursor, false, err
+		}
+		clouds := make([]*CloudAccount, 0)
+		token, err := decodePage(raw, &clouds)
+		if err != nil {
+			return nil, cursor, false, err
+		}
+
+		items := make([]interface{}, 0, len(clouds))
+		for _, account := range clouds {
+			if account.ID != "" && seen[account.ID] {
+				continue
+			}
+			seen[account.ID] = true
+			account.setAccountID()
+			items = append(items, account)
+		}
+
+		if len(items) == 0 {
+			return items, cursor, false, nil
+		}
+		if token != "" {
+			return items, pageCursor{ContinuationToken: token}, true, nil
+		}
+		// a full page may be followed by more accounts
+		return items, pageCursor{Offset: cursor.Offset + len(clouds)}, len(clouds) >= pageSize, nil
 	}
 
-	if len(clouds) == 0 {
-		return nil, NewError(content.ErrorNoCloudAccountsFound)
+	return &CloudAccountIterator{it: newIterator(fetch, opts)}
+}
+
+// GetCloudAccounts returns the cloud accounts up to opts.Limit, fetching all pages
+func (c *Client) GetCloudAccounts(ctx context.Context, opts ListOptions) ([]*CloudAccount, error) {
+	clouds := make([]*CloudAccount, 0)
+
+	it := c.CloudAccounts(opts)
+	for it.Next(ctx) {
+		clouds = append(clouds, it.CloudAccount())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 
 	return clouds, nil
@@ -163,29 +308,75 @@ func (c *Client) sendCloudCreateRequest(ctx context.Context, input *CloudInfo) (
 func (c *Client) GetRoleCreationInfo(ctx context.Context, input *CreateCloudAccountInput) (*RoleCreationInfo, error) {
 
 	id := defaultID{}
-	err := c.Do(ctx, "GET", ".well-known/vss-configuration", nil, &id)
+	err := c.Do(ctx, "GET", wellKnownConfigPath, nil, &id)
 	if err != nil {
 		return nil, err
 	}
 
+	prefix, err := c.genRandomString(10)
+	if err != nil {
+		return nil, err
+	}
 	createNewRoleInfo := &RoleCreationInfo{
 		RoleName: input.RoleName,
-		//Need to find out the right way to create external id.
-		ExternalID: c.genRandomString(10) + id.ExternalID,
+		// the well-known suffix tells VSS the external id is one of its own
+		ExternalID: prefix + id.ExternalID,
 		AwsAccount: id.AccountID,
 		Policy:     input.Policy,
+
+		SubscriptionID: input.SubscriptionID,
+		Catalog:        id.PolicyCatalog,
 	}
 
 	return createNewRoleInfo, nil
 }
 
-func (c *Client) genRandomString(n int) string {
-	var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-	b := make([]rune, n)
+// GetPolicyCatalog returns the policy catalog of the VSS configuration, nil when it has none
+func (c *Client) GetPolicyCatalog(ctx context.Context) (PolicyCatalog, error) {
+	id := defaultID{}
+	if err := c.Do(ctx, "GET", wellKnownConfigPath, nil, &id); err != nil {
+		return nil, err
+	}
+	return id.PolicyCatalog, nil
+}
+
+// discoverCSPEndpoint reads the CSP endpoint from the VSS configuration
+// document. The document is public, so the request is sent unsigned.
+func (c *Client) discoverCSPEndpoint(ctx context.Context) string {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s", c.endpoint, wellKnownConfigPath), nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return ""
+	}
+
+	id := defaultID{}
+	if err := json.NewDecoder(resp.Body).Decode(&id); err != nil {
+		return ""
+	}
+	return id.CSPEndpoint
+}
+
+// genRandomString returns n random letters, the external ids made of them
+// must not be guessable so they come from crypto/rand
+func (c *Client) genRandomString(n int) (string, error) {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	max := big.NewInt(int64(len(letters)))
+	b := make([]byte, n)
 	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+		k, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = letters[k.Int64()]
 	}
-	return string(b)
+	return string(b), nil
 }
 
 // CreateCloudAccount method to create a cloud object
@@ -195,6 +386,10 @@ func (c *Client) CreateCloudAccount(ctx context.Context, input *CreateCloudAccou
 	if input.Provider == "AWS" && input.RoleArn == "" {
 		return nil, NewError(content.ErrorMissingRoleInformation)
 	}
+	if input.Provider == "GCP" && (input.ProjectID == "" || input.ServiceAccountEmail == "" ||
+		(input.ServiceAccountKey == "" && input.WorkloadIdentityProvider == "")) {
+		return nil, NewError(content.ErrorMissingServiceAccountInformation)
+	}
 	cloudCreateInput := CloudInfo{
 		ExternalID:     input.ExternalID,
 		Name:           input.CloudName,
@@ -210,17 +405,26 @@ func (c *Client) CreateCloudAccount(ctx context.Context, input *CreateCloudAccou
 		DirectoryID:    input.DirectoryID,
 		SubscriptionID: input.SubscriptionID,
 		Environment:    input.Environment,
+
+		ProjectID:                input.ProjectID,
+		ServiceAccountEmail:      input.ServiceAccountEmail,
+		ServiceAccountKey:        input.ServiceAccountKey,
+		WorkloadIdentityProvider: input.WorkloadIdentityProvider,
 	}
 	if input.Tags != "" {
 		cloudCreateInput.Tags = strings.Split(input.Tags, "|")
 	}
+	if input.TagList != nil {
+		cloudCreateInput.Tags = input.TagList
+	}
 	if input.Provider == "AWS" {
 		cloudCreateInput.ScanInterval = "Weekly"
-	} else if input.Provider == "Azure" {
+	} else if input.Provider == "Azure" || input.Provider == "GCP" {
 		cloudCreateInput.ScanInterval = "Daily"
 	} else {
 		return nil, NewError("Unsupported CloudAccount type")
 	}
+	input.ScanSettings.apply(&cloudCreateInput)
 	cloudAccount, err := c.sendCloudCreateRequest(ctx, &cloudCreateInput)
 	if err != nil {
 		return nil, err
@@ -229,12 +433,21 @@ func (c *Client) CreateCloudAccount(ctx context.Context, input *CreateCloudAccou
 	if cloudAccount.ID == "" {
 		return nil, NewError(content.ErrorFailedToCreateCloudAccount)
 	}
-	if cloudAccount.Provider == "Azure" {
-		cloudAccount.AccountID = cloudAccount.SubscriptionID
-	}
+	cloudAccount.setAccountID()
 	return cloudAccount, nil
 }
 
+// setAccountID sets the account ID of Azure and GCP accounts, which are
+// identified by their subscription and project
+func (t *CloudAccount) setAccountID() {
+	switch t.Provider {
+	case "Azure":
+		t.AccountID = t.SubscriptionID
+	case "GCP":
+		t.AccountID = t.ProjectID
+	}
+}
+
 // DeleteCloudAccountByID method to delete cloud object
 func (c *Client) DeleteCloudAccountByID(ctx context.Context, cloudID string) error {
 	err := c.Do(ctx, "DELETE", fmt.Sprintf("cloudaccounts/%s", cloudID), nil, nil)
@@ -271,6 +484,10 @@ func (c *Client) UpdateCloudAccount(ctx context.Context, input *UpdateCloudAccou
 }
 
 func (t *UpdateCloudAccountInput) mergeAndGetJSON(account *CloudAccount) ([]byte, error) {
+	if t.Fields != nil {
+		return t.patchJSON(account)
+	}
+
 	updateInfo := t.toCloudInfo()
 	err := mergo.Merge(updateInfo, *(account.toCloudInfo()))
 	if err != nil {
@@ -278,32 +495,106 @@ func (t *UpdateCloudAccountInput) mergeAndGetJSON(account *CloudAccount) ([]byte
 	}
 	// mergo package will override false to true
 	updateInfo.IsDraft = t.IsDraft
-	updateInfo.Environment = t.Environment
-	if t.Tags != "" {
-		updateInfo.Tags = strings.Split(t.Tags, "|")
+	t.ScanSettings.apply(updateInfo)
+	if updateInfo.ScanRegion == "" {
+		updateInfo.ScanRegion = "All"
 	}
 	jsonStr, err := json.Marshal(updateInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	if t.TagList != nil && len(t.TagList) == 0 {
+		// omitempty leaves out the empty list, which would keep the current tags
+		return clearTags(jsonStr)
+	}
 	return jsonStr, nil
 }
 
+//Patch returns the updated fields keyed by their JSON name
+func (t *UpdateCloudAccountInput) Patch() map[string]interface{} {
+	info := t.toCloudInfo()
+	t.ScanSettings.apply(info)
+	if info.Tags == nil {
+		// an empty list removes the tags, null would be ignored
+		info.Tags = Tags{}
+	}
+
+	patch := map[string]interface{}{}
+	v := reflect.ValueOf(info).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		for _, field := range t.Fields {
+			if field == name {
+				patch[name] = v.Field(i).Interface()
+			}
+		}
+	}
+	return patch
+}
+
+//RedactedPatch returns the patch with the secrets of cloud accounts replaced, for printing it
+func (t *UpdateCloudAccountInput) RedactedPatch() map[string]interface{} {
+	patch := t.Patch()
+	for name := range patch {
+		if redactedFields[strings.ToLower(name)] || redactedAccountFields[strings.ToLower(name)] {
+			patch[name] = redacted
+		}
+	}
+	return patch
+}
+
+// patchJSON returns the current cloud info of the account with the fields of the patch replaced
+func (t *UpdateCloudAccountInput) patchJSON(account *CloudAccount) ([]byte, error) {
+	current, err := json.Marshal(account.toCloudInfo())
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range t.Patch() {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// clearTags sets the tags of the JSON cloud info to an empty list
+func clearTags(info []byte) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(info, &fields); err != nil {
+		return nil, err
+	}
+	fields["tags"] = json.RawMessage("[]")
+	return json.Marshal(fields)
+}
+
 func (t *UpdateCloudAccountInput) toCloudInfo() *CloudInfo {
 	cloudInfo := &CloudInfo{
 		Name:           t.CloudName,
 		Arn:            t.RoleArn,
 		ScanEnabled:    t.ScanEnabled,
-		ScanRegion:     "All",
 		ExternalID:     t.ExternalID,
 		IsDraft:        t.IsDraft,
 		Email:          t.Email,
 		UserName:       t.UserName,
+		Environment:    t.Environment,
 		SubscriptionID: t.SubscriptionID,
 		KeyValue:       t.KeyValue,
 		ApplicationID:  t.ApplicationID,
 		DirectoryID:    t.DirectoryID,
+
+		ProjectID:                t.ProjectID,
+		ServiceAccountEmail:      t.ServiceAccountEmail,
+		ServiceAccountKey:        t.ServiceAccountKey,
+		WorkloadIdentityProvider: t.WorkloadIdentityProvider,
+	}
+	if t.Tags != "" {
+		cloudInfo.Tags = strings.Split(t.Tags, "|")
+	}
+	if t.TagList != nil {
+		cloudInfo.Tags = t.TagList
 	}
 
 	return cloudInfo
@@ -327,6 +618,11 @@ func (t *CloudAccount) toCloudInfo() *CloudInfo {
 		ApplicationID:  t.ApplicationID,
 		DirectoryID:    t.DirectoryID,
 		Tags:           t.Tags,
+
+		ProjectID:                t.ProjectID,
+		ServiceAccountEmail:      t.ServiceAccountEmail,
+		ServiceAccountKey:        t.ServiceAccountKey,
+		WorkloadIdentityProvider: t.WorkloadIdentityProvider,
 	}
 	return cloudInfo
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -94,7 +95,7 @@ func TestGetCloudAccountsSuccess(t *testing.T) {
 	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
 
 	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
-	_, err := client.GetCloudAccounts(context.Background())
+	_, err := client.GetCloudAccounts(context.Background(), ListOptions{})
 	assert.Nil(t, err, "GetCloudAccounts shouldn't return error.")
 }
 
@@ -105,22 +106,21 @@ func TestGetCloudAccountsFailureInvalidCloudAccountresponse(t *testing.T) {
 	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
 
 	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
-	_, err := client.GetCloudAccounts(context.Background())
+	_, err := client.GetCloudAccounts(context.Background(), ListOptions{})
 	assert.NotNil(t, err, "GetCloudAccounts should return error.")
 	assert.Equal(t, "json: cannot unmarshal object into Go value of type []*client.CloudAccount", err.Error())
 }
 
-func TestGetCloudAccountsFailedNoCloudAccountsFound(t *testing.T) {
+func TestGetCloudAccountsNoCloudAccountsFound(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", httpmock.NewStringResponder(http.StatusOK, `[]`))
 	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
 
 	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
-	_, err := client.GetCloudAccounts(context.Background())
-	assert.NotNil(t, err, "GetCloudAccounts should return error.")
-	assert.Equal(t, "No cloud accounts found.", err.Error())
-
+	clouds, err := client.GetCloudAccounts(context.Background(), ListOptions{})
+	assert.Nil(t, err, "GetCloudAccounts shouldn't return error for no cloud accounts.")
+	assert.Empty(t, clouds)
 }
 
 func TestGetCloudAccountByIDSuccess(t *testing.T) {
@@ -194,6 +194,42 @@ func TestCreateCloudAccountFailureMissingRoleArn(t *testing.T) {
 
 }
 
+func TestCreateCloudAccountGCP(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	var sent CloudInfo
+	httpmock.RegisterResponder("POST", defaultAPIEndpoint+"/cloudaccounts", func(req *http.Request) (*http.Response, error) {
+		json.NewDecoder(req.Body).Decode(&sent)
+		return httpmock.NewStringResponse(http.StatusCreated, `{"_id": "gcp-1", "provider": "GCP", "projectId": "project-1"}`), nil
+	})
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
+	cloud, err := client.CreateCloudAccount(context.Background(), &CreateCloudAccountInput{
+		CloudName:           "gcp",
+		Provider:            "GCP",
+		ProjectID:           "project-1",
+		ServiceAccountEmail: "vss@project-1.iam.gserviceaccount.com",
+		ServiceAccountKey:   `{"type": "service_account"}`,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "project-1", cloud.AccountID)
+	assert.Equal(t, "project-1", sent.ProjectID)
+	assert.Equal(t, "vss@project-1.iam.gserviceaccount.com", sent.ServiceAccountEmail)
+	assert.Equal(t, `{"type": "service_account"}`, sent.ServiceAccountKey)
+	assert.Equal(t, "Daily", sent.ScanInterval)
+}
+
+func TestCreateCloudAccountGCPMissingServiceAccount(t *testing.T) {
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
+	_, err := client.CreateCloudAccount(context.Background(), &CreateCloudAccountInput{
+		Provider:            "GCP",
+		ProjectID:           "project-1",
+		ServiceAccountEmail: "vss@project-1.iam.gserviceaccount.com",
+	})
+	assert.EqualError(t, err, content.ErrorMissingServiceAccountInformation)
+}
+
 func TestCreateCloudAccountFailureBadRequest(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -253,8 +289,40 @@ func TestGetRoleCreationInfoSuccess(t *testing.T) {
 	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
 
 	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
-	_, err := client.GetRoleCreationInfo(context.Background(), &CreateCloudAccountInput{})
+	info, err := client.GetRoleCreationInfo(context.Background(), &CreateCloudAccountInput{})
 	assert.Nil(t, err, "GetRoleCreationInfo shouldn't return error.")
+	assert.Regexp(t, "^[a-zA-Z]{10}Fake-external-id$", info.ExternalID)
+
+	other, err := client.GetRoleCreationInfo(context.Background(), &CreateCloudAccountInput{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, info.ExternalID, other.ExternalID)
+	assert.Nil(t, info.Catalog)
+}
+
+func TestGetRoleCreationInfoPolicyCatalog(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/.well-known/vss-configuration", httpmock.NewStringResponder(http.StatusOK,
+		`{"accountId": "Fake-aws-account-id", "externalId": "Fake-external-id", "policyCatalog": {"ec2": ["DescribeInstances"]}}`))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
+	info, err := client.GetRoleCreationInfo(context.Background(), &CreateCloudAccountInput{})
+	assert.Nil(t, err)
+	assert.Equal(t, PolicyCatalog{"ec2": {"DescribeInstances"}}, info.Catalog)
+}
+
+func TestGetPolicyCatalog(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/.well-known/vss-configuration", httpmock.NewStringResponder(http.StatusOK,
+		`{"accountId": "Fake-aws-account-id", "externalId": "Fake-external-id", "policyCatalog": {"ec2": ["DescribeInstances"]}}`))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint)
+	catalog, err := client.GetPolicyCatalog(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, PolicyCatalog{"ec2": {"DescribeInstances"}}, catalog)
 }
 
 func TestGetRoleCreationInfoFailure(t *testing.T) {
@@ -280,6 +348,75 @@ func TestUpdateCloudAccountSuccess(t *testing.T) {
 	assert.Nil(t, err, "UpdateCloudAccount shouldn't return error.")
 }
 
+func TestUpdateCloudAccountScanSettings(t *testing.T) {
+	account := &CloudAccount{CloudInfo: CloudInfo{Name: "name", ScanEnabled: true, ScanInterval: "Weekly", ScanRegion: "us-east-1"}}
+
+	input := &UpdateCloudAccountInput{}
+	jsonStr, err := input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonStr), `"scanEnabled":true`)
+
+	input.ScanSettings = &ScanSettings{Enabled: false, Region: "us-west-2"}
+	jsonStr, err = input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonStr), `"scanEnabled":false,"scanInterval":"Weekly","scanRegion":"us-west-2"`)
+}
+
+func TestUpdateCloudAccountTagList(t *testing.T) {
+	account := &CloudAccount{CloudInfo: CloudInfo{Name: "name", Tags: Tags{"team:web"}}}
+
+	input := &UpdateCloudAccountInput{}
+	jsonStr, err := input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonStr), `"tags":["team:web"]`)
+
+	input.TagList = Tags{"owner:alice"}
+	jsonStr, err = input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonStr), `"tags":["owner:alice"]`)
+
+	input.TagList = Tags{}
+	jsonStr, err = input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonStr), `"tags":[]`)
+	assert.Contains(t, string(jsonStr), `"name":"name"`)
+}
+
+func TestUpdateCloudAccountFields(t *testing.T) {
+	account := &CloudAccount{CloudInfo: CloudInfo{Name: "name", IsDraft: true, Environment: "Production", ScanEnabled: true, ScanInterval: "Weekly", ScanRegion: "us-east-1", KeyValue: "key-1", Tags: Tags{"team:web"}}}
+
+	input := &UpdateCloudAccountInput{
+		CreateCloudAccountInput: CreateCloudAccountInput{
+			KeyValue:     "key-2",
+			ScanSettings: &ScanSettings{Enabled: false, Interval: "Daily"},
+		},
+		Fields: []string{"key", "scanEnabled", "scanInterval", "tags"},
+	}
+	assert.Equal(t, map[string]interface{}{"key": "key-2", "scanEnabled": false, "scanInterval": "Daily", "tags": Tags{}}, input.Patch())
+	assert.Equal(t, map[string]interface{}{"key": "REDACTED", "scanEnabled": false, "scanInterval": "Daily", "tags": Tags{}}, input.RedactedPatch())
+
+	jsonStr, err := input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	fields := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(jsonStr, &fields))
+	// the fields that aren't updated keep their value, even false or empty
+	assert.Equal(t, "name", fields["name"])
+	assert.Equal(t, true, fields["isDraft"])
+	assert.Equal(t, "Production", fields["environment"])
+	assert.Equal(t, "us-east-1", fields["scanRegion"])
+	assert.Equal(t, "key-2", fields["key"])
+	assert.Equal(t, false, fields["scanEnabled"])
+	assert.Equal(t, "Daily", fields["scanInterval"])
+	assert.Equal(t, []interface{}{}, fields["tags"])
+
+	input = &UpdateCloudAccountInput{Fields: []string{"isDraft", "environment"}}
+	jsonStr, err = input.mergeAndGetJSON(account)
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonStr), `"isDraft":false`)
+	assert.Contains(t, string(jsonStr), `"environment":""`)
+	assert.Contains(t, string(jsonStr), `"tags":["team:web"]`)
+}
+
 func TestReValidateRoleSuccess(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -4,9 +4,6 @@ const (
 	//ErrorMissingAPIOrSecretKey error
 	ErrorMissingAPIOrSecretKey = "Missing API key or/and Secret key. Please run 'coreo configure' to configure them."
 
-	//ErrorNoCloudAccountsFound error
-	ErrorNoCloudAccountsFound = "No cloud accounts found."
-
 	//ErrorNoCloudAccountWithIDFound error
 	ErrorNoCloudAccountWithIDFound = "No cloud account with ID %s found."
 
@@ -19,6 +16,9 @@ const (
 	//ErrorMissingRoleInformation error
 	ErrorMissingRoleInformation = "Adding cloud account falied, you need to provide either rolearn and external id or new role name"
 
+	//ErrorMissingServiceAccountInformation error
+	ErrorMissingServiceAccountInformation = "Adding GCP cloud account failed, you need to provide the project ID, the service account email and either its key or a workload identity provider"
+
 	//ErrorNoTokensFound error
 	ErrorNoTokensFound = "No tokens found. To create a token use `coreo token add [flags]` command."
 
@@ -33,4 +33,7 @@ const (
 
 	//ErrorNoTeamWithIDFound error
 	ErrorNoTeamWithIDFound = "No team with ID %s found."
+
+	//ErrorInsecureCSPEndpoint error
+	ErrorInsecureCSPEndpoint = "The CSP endpoint %s discovered from the API doesn't use https, the API key isn't sent to it. Configure the CSP endpoint with --csp-endpoint or 'vss configure --csp-endpoint'."
 )
@@ -0,0 +1,392 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// redactedHeaders are replaced in traces, compared in canonical form.
+var redactedHeaders = map[string]bool{
+	"Csp-Auth-Token": true,
+	"Authorization":  true,
+	"Cookie":         true,
+	"Set-Cookie":     true,
+}
+
+// redactedFields are replaced in form and JSON bodies of traces, compared in lower case.
+var redactedFields = map[string]bool{
+	"refresh_token": true,
+	"access_token":  true,
+	"id_token":      true,
+	"keyvalue":      true,
+	"externalid":    true,
+	"secret":        true,
+	"clientsecret":  true,
+	"password":      true,
+
+	"serviceaccountkey": true,
+	"privatekeydata":    true,
+	"private_key":       true,
+	"client_secret":     true,
+	"assertion":         true,
+}
+
+// redactedAccountFields are the secrets of cloud accounts whose names are too
+// generic to be redacted everywhere, e.g. "key" is the Azure client secret.
+var redactedAccountFields = map[string]bool{
+	"key": true,
+}
+
+// redactedQueryFields are the secrets of signed URLs, e.g. the SAS signature
+// and function key of Azure webhook URIs, compared in lower case. The query
+// is redacted with redactedFields too.
+var redactedQueryFields = map[string]bool{
+	"sig":                  true,
+	"signature":            true,
+	"code":                 true,
+	"token":                true,
+	"x-amz-signature":      true,
+	"x-amz-security-token": true,
+}
+
+// DebugTransport is an http.RoundTripper tracing every request and response
+// with secrets redacted. It logs to Out and records to HAR when they are set.
+type DebugTransport struct {
+	Next http.RoundTripper
+	Out  io.Writer
+	HAR  *HARRecorder
+}
+
+// RoundTrip sends the request with Next and traces the exchange.
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := t.next().RoundTrip(req)
+	elapsed := time.Since(start)
+
+	var respBody []byte
+	if resp != nil {
+		if respBody, err = readBody(&resp.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	reqText := redactBody(req.Header.Get("Content-Type"), reqBody)
+	respText := ""
+	if resp != nil {
+		respText = redactBody(resp.Header.Get("Content-Type"), respBody)
+	}
+
+	if t.Out != nil {
+		t.log(req, reqText, resp, respText, elapsed, err)
+	}
+	if t.HAR != nil {
+		t.HAR.add(req, reqText, resp, respText, start, elapsed)
+	}
+
+	return resp, err
+}
+
+func (t *DebugTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+func (t *DebugTransport) log(req *http.Request, reqBody string, resp *http.Response, respBody string, elapsed time.Duration, err error) {
+	var b strings.Builder
+	u := redactURL(req.URL)
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, u)
+	writeHeaders(&b, req.Header)
+	if reqBody != "" {
+		fmt.Fprintf(&b, "%s\n", reqBody)
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "<-- error %v (%s)\n", err, elapsed.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(&b, "<-- %s %s (%s)\n", resp.Status, u, elapsed.Round(time.Millisecond))
+		writeHeaders(&b, resp.Header)
+		if respBody != "" {
+			fmt.Fprintf(&b, "%s\n", respBody)
+		}
+	}
+	io.WriteString(t.Out, b.String())
+}
+
+func writeHeaders(w io.Writer, header http.Header) {
+	for name, values := range redactHeaders(header) {
+		fmt.Fprintf(w, "%s: %s\n", name, strings.Join(values, ", "))
+	}
+}
+
+// readBody reads a request or response body and replaces it so it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	b, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+func redactHeaders(header http.Header) http.Header {
+	h := make(http.Header, len(header))
+	for name, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			values = []string{redacted}
+		}
+		h[name] = values
+	}
+	return h
+}
+
+// redactURL returns a copy of u with the secrets of its query and user info replaced.
+func redactURL(u *url.URL) *url.URL {
+	redactedURL := *u
+	if _, ok := u.User.Password(); ok {
+		redactedURL.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if u.RawQuery == "" {
+		return &redactedURL
+	}
+	query := u.Query()
+	for name := range query {
+		if lower := strings.ToLower(name); redactedFields[lower] || redactedQueryFields[lower] {
+			query[name] = []string{redacted}
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	return &redactedURL
+}
+
+// redactBody returns body as text with the values of redactedFields replaced.
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for name := range values {
+				if redactedFields[strings.ToLower(name)] {
+					values.Set(name, redacted)
+				}
+			}
+			return values.Encode()
+		}
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		if b, err := json.Marshal(redactJSON(v)); err == nil {
+			return string(b)
+		}
+	}
+
+	return string(body)
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// a cloud account always has its provider
+		_, account := v["provider"]
+		for name, value := range v {
+			if redactedFields[strings.ToLower(name)] || account && redactedAccountFields[strings.ToLower(name)] {
+				v[name] = redacted
+			} else {
+				v[name] = redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+	return v
+}
+
+// HARRecorder collects traced requests as HTTP Archive entries.
+type HARRecorder struct {
+	mu      sync.Mutex
+	creator harCreator
+	entries []harEntry
+}
+
+// NewHARRecorder returns a recorder naming the given tool as creator of the archive.
+func NewHARRecorder(name, version string) *HARRecorder {
+	return &HARRecorder{creator: harCreator{Name: name, Version: version}}
+}
+
+// WriteTo writes the recorded entries as a HAR 1.2 document.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.entries
+	if entries == nil {
+		entries = []harEntry{}
+	}
+	b, err := json.MarshalIndent(harDocument{Log: harLog{Version: "1.2", Creator: r.creator, Entries: entries}}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (r *HARRecorder) add(req *http.Request, reqBody string, resp *http.Response, respBody string, start time.Time, elapsed time.Duration) {
+	ms := float64(elapsed) / float64(time.Millisecond)
+	u := redactURL(req.URL)
+	entry := harEntry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         u.String(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(u.Query()),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Headers:     []harNameValue{},
+			Content:     harContent{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache:   struct{}{},
+		Timings: harTimings{Send: 0, Wait: ms, Receive: 0},
+	}
+	if reqBody != "" {
+		entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: reqBody}
+	}
+	if resp != nil {
+		entry.Response = harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     harHeaders(resp.Header),
+			Content: harContent{
+				Size:     len(respBody),
+				MimeType: resp.Header.Get("Content-Type"),
+				Text:     respBody,
+			},
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(respBody),
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, values := range redactHeaders(header) {
+		for _, value := range values {
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func harQuery(query url.Values) []harNameValue {
+	params := []harNameValue{}
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, harNameValue{Name: name, Value: value})
+		}
+	}
+	return params
+}
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
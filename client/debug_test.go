@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactBody(t *testing.T) {
+	form := redactBody("application/x-www-form-urlencoded", []byte("refresh_token=secret-token&grant=x"))
+	assert.Equal(t, "grant=x&refresh_token=REDACTED", form)
+
+	body := redactBody("application/json", []byte(`{"name":"azure","provider":"Azure","key":"azure-key","roles":[{"externalId":"id-1"}],"access_token":"t"}`))
+	assert.NotContains(t, body, "azure-key")
+	assert.NotContains(t, body, "id-1")
+	assert.Contains(t, body, `"name":"azure"`)
+	assert.Contains(t, body, `"access_token":"REDACTED"`)
+
+	assert.Equal(t, "plain text", redactBody("text/plain", []byte("plain text")))
+
+	// key is only a secret of cloud accounts
+	body = redactBody("application/json", []byte(`{"rules":[{"key":"s3-public-bucket"}]}`))
+	assert.Equal(t, `{"rules":[{"key":"s3-public-bucket"}]}`, body)
+}
+
+func TestDebugTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"provider":"Azure","key":"azure-key"}`, string(body), "the request body should be sent unchanged")
+		assert.Equal(t, "access-token", r.Header.Get("csp-auth-token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"externalId":"external-id","id":"cloud-id"}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	har := NewHARRecorder("vss-cli", "test")
+	hc := &http.Client{Transport: &DebugTransport{Out: &out, HAR: har}}
+
+	req, _ := http.NewRequest("POST", server.URL+"/cloudaccounts", strings.NewReader(`{"provider":"Azure","key":"azure-key"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("csp-auth-token", "access-token")
+	resp, err := hc.Do(req)
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, `{"externalId":"external-id","id":"cloud-id"}`, string(body), "the response body should be returned unchanged")
+
+	trace := out.String()
+	assert.Contains(t, trace, "--> POST "+server.URL+"/cloudaccounts")
+	assert.Contains(t, trace, "<-- 200 OK")
+	assert.Contains(t, trace, "Csp-Auth-Token: REDACTED")
+	assert.Contains(t, trace, "cloud-id")
+	for _, secret := range []string{"access-token", "azure-key", "external-id"} {
+		assert.NotContains(t, trace, secret)
+	}
+
+	var doc bytes.Buffer
+	har.WriteTo(&doc)
+	var archive harDocument
+	assert.Nil(t, json.Unmarshal(doc.Bytes(), &archive))
+	assert.Equal(t, "1.2", archive.Log.Version)
+	assert.Len(t, archive.Log.Entries, 1)
+	assert.Equal(t, http.StatusOK, archive.Log.Entries[0].Response.Status)
+	for _, secret := range []string{"access-token", "azure-key", "external-id"} {
+		assert.NotContains(t, doc.String(), secret)
+	}
+}
+
+func TestDebugTransportRedactsQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sas-signature", r.URL.Query().Get("sig"), "the request URL should be sent unchanged")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	har := NewHARRecorder("vss-cli", "test")
+	hc := &http.Client{Transport: &DebugTransport{Out: &out, HAR: har}}
+
+	resp, err := hc.Get(server.URL + "/webhook?sv=2019-02-02&sig=sas-signature&code=function-key&Token=api-token")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	trace := out.String()
+	assert.Contains(t, trace, "sv=2019-02-02")
+	assert.Contains(t, trace, "sig=REDACTED")
+	for _, secret := range []string{"sas-signature", "function-key", "api-token"} {
+		assert.NotContains(t, trace, secret)
+	}
+
+	var doc bytes.Buffer
+	har.WriteTo(&doc)
+	var archive harDocument
+	assert.Nil(t, json.Unmarshal(doc.Bytes(), &archive))
+	assert.Contains(t, archive.Log.Entries[0].Request.URL, "code=REDACTED")
+	assert.Contains(t, archive.Log.Entries[0].Request.QueryString, harNameValue{Name: "Token", Value: "REDACTED"})
+	for _, secret := range []string{"sas-signature", "function-key", "api-token"} {
+		assert.NotContains(t, doc.String(), secret)
+	}
+}
@@ -1,5 +1,18 @@
 package client
 
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// requestIDHeaders are the response headers the API and CSP use to correlate a request.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "Csp-Request-Id"}
+
 type errorString struct {
 	Message string `json:"error_message"`
 }
@@ -13,3 +26,110 @@ func (e *errorString) Error() string {
 func NewError(text string) error {
 	return &errorString{text}
 }
+
+// APIError is returned for API responses with a status code of 300 or above.
+type APIError struct {
+	StatusCode int    `json:"status"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message"`
+	RequestID  string `json:"requestId,omitempty"`
+	// Body is the raw response body
+	Body string `json:"-"`
+}
+
+// Error formats the error message of the response
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Body
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (request id: %s)", msg, e.RequestID)
+	}
+	return msg
+}
+
+// apiErrorBody holds the fields the API and CSP use to describe an error.
+type apiErrorBody struct {
+	Code         interface{} `json:"code"`
+	ErrorCode    interface{} `json:"errorCode"`
+	Message      string      `json:"message"`
+	ErrorMessage string      `json:"error_message"`
+	Error        string      `json:"error"`
+	RequestID    string      `json:"requestId"`
+}
+
+// newAPIError reads the response body into an APIError, the body is parsed
+// as JSON when possible and used as the message otherwise.
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := ioutil.ReadAll(resp.Body)
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			apiErr.RequestID = id
+			break
+		}
+	}
+
+	parsed := apiErrorBody{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return apiErr
+	}
+	for _, code := range []interface{}{parsed.Code, parsed.ErrorCode} {
+		if code != nil {
+			apiErr.Code = fmt.Sprint(code)
+			break
+		}
+	}
+	for _, msg := range []string{parsed.Message, parsed.ErrorMessage, parsed.Error} {
+		if msg != "" {
+			apiErr.Message = msg
+			break
+		}
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = parsed.RequestID
+	}
+	return apiErr
+}
+
+// StatusCode returns the HTTP status of an API error, or 0 for any other error.
+func StatusCode(err error) int {
+	if apiErr, ok := errors.Cause(err).(*APIError); ok {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is an API error for a missing resource.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is an API error for missing or invalid credentials.
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err is an API error for a request the credentials don't grant.
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsInvalidRequest reports whether err is an API error for a request the API rejected as invalid.
+func IsInvalidRequest(err error) bool {
+	status := StatusCode(err)
+	return status == http.StatusBadRequest || status == http.StatusConflict || status == http.StatusUnprocessableEntity
+}
+
+// IsRetryable reports whether err is an API error for a request that may succeed when sent again.
+func IsRetryable(err error) bool {
+	status := StatusCode(err)
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
@@ -22,6 +22,7 @@ import (
 type EventStreamConfig struct {
 	AWSEventStreamConfig
 	AzureEventStreamConfig
+	GCPEventStreamConfig
 	Provider string `json:"provider"`
 }
 
@@ -51,10 +52,23 @@ type AzureEventStreamConfig struct {
 	AlertName            string `json:"alertName"`
 }
 
+//GCPEventStreamConfig contains info needed for GCP event stream setup, the log
+//sink publishes the matching audit logs to the Pub/Sub topic, which pushes
+//them to the endpoint
+type GCPEventStreamConfig struct {
+	ProjectID          string `json:"projectId"`
+	PubSubTopic        string `json:"pubsubTopic"`
+	PubSubSubscription string `json:"pubsubSubscription"`
+	PushEndpoint       string `json:"pushEndpoint"`
+	SinkName           string `json:"sinkName"`
+	SinkFilter         string `json:"sinkFilter"`
+}
+
 //EventRemoveConfig for event stream removal
 type EventRemoveConfig struct {
 	AWSEventRemoveConfig
 	AzureEventRemoveConfig
+	GCPEventRemoveConfig
 	Provider string `json:"provider"`
 }
 
@@ -74,6 +88,14 @@ type AzureEventRemoveConfig struct {
 	WebhookServiceURI string `json:"webhookServiceUri"`
 }
 
+//GCPEventRemoveConfig contains info needed for GCP event stream removal
+type GCPEventRemoveConfig struct {
+	ProjectID          string `json:"projectId"`
+	PubSubTopic        string `json:"pubsubTopic"`
+	PubSubSubscription string `json:"pubsubSubscription"`
+	SinkName           string `json:"sinkName"`
+}
+
 //GetSetupConfig get the config for event stream setup from secure state
 func (c *Client) GetSetupConfig(ctx context.Context, cloudID string) (*EventStreamConfig, error) {
 	config := &EventStreamConfig{}
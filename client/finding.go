@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+//Finding is a rule violation found on a cloud resource
+type Finding struct {
+	ID             string `json:"id"`
+	RuleID         string `json:"ruleId"`
+	RuleName       string `json:"ruleName"`
+	Level          string `json:"level"`
+	Status         string `json:"status"`
+	CloudAccountID string `json:"cloudAccountId"`
+	Provider       string `json:"provider"`
+	Region         string `json:"region"`
+	ObjectID       string `json:"objectId"`
+	ObjectType     string `json:"objectType"`
+	FirstSeen      string `json:"firstSeen"`
+	LastSeen       string `json:"lastSeen"`
+}
+
+//FindingFilter selects the findings returned by ListFindings, empty fields match everything
+type FindingFilter struct {
+	CloudAccountIDs []string   `json:"cloudAccountIds,omitempty"`
+	RuleIDs         []string   `json:"ruleIds,omitempty"`
+	Levels          []string   `json:"levels,omitempty"`
+	Providers       []string   `json:"providers,omitempty"`
+	Regions         []string   `json:"regions,omitempty"`
+	ObjectTypes     []string   `json:"objectTypes,omitempty"`
+	LastSeenFrom    *time.Time `json:"lastSeenFrom,omitempty"`
+	LastSeenTo      *time.Time `json:"lastSeenTo,omitempty"`
+}
+
+type findingsQuery struct {
+	Filters        *FindingFilter `json:"filters"`
+	PaginationInfo paginationInfo `json:"paginationInfo"`
+}
+
+type paginationInfo struct {
+	ContinuationToken string `json:"continuationToken,omitempty"`
+	PageSize          int    `json:"pageSize"`
+}
+
+//FindingIterator iterates over findings, fetching pages as needed
+type FindingIterator struct {
+	it iterator
+}
+
+//Next advances to the next finding, it returns false when there are no more or on error
+func (i *FindingIterator) Next(ctx context.Context) bool {
+	return i.it.next(ctx)
+}
+
+//Finding returns the current finding
+func (i *FindingIterator) Finding() *Finding {
+	finding, _ := i.it.cur.(*Finding)
+	return finding
+}
+
+//Err returns the error that stopped the iteration
+func (i *FindingIterator) Err() error {
+	return i.it.err
+}
+
+//Findings returns an iterator over the findings matching the filter, no request is sent before Next is called
+func (c *Client) Findings(filter *FindingFilter, opts ListOptions) *FindingIterator {
+	if filter == nil {
+		filter = &FindingFilter{}
+	}
+
+	fetch := func(ctx context.Context, cursor pageCursor, pageSize int) ([]interface{}, pageCursor, bool, error) {
+		query := findingsQuery{
+			Filters:        filter,
+			PaginationInfo: paginationInfo{ContinuationToken: cursor.ContinuationToken, PageSize: pageSize},
+		}
+		jsonStr, err := json.Marshal(query)
+		if err != nil {
+			return nil, cursor, false, err
+		}
+
+		var raw json.RawMessage
+		if err := c.Do(ctx, "POST", "findings/query", bytes.NewBuffer(jsonStr), &raw); err != nil {
+			return nil, cursor, false, err
+		}
+		findings := make([]*Finding, 0)
+		token, err := decodePage(raw, &findings)
+		if err != nil {
+			return nil, cursor, false, err
+		}
+
+		items := make([]interface{}, len(findings))
+		for i := range findings {
+			items[i] = findings[i]
+		}
+		return items, pageCursor{ContinuationToken: token}, token != "", nil
+	}
+
+	return &FindingIterator{it: newIterator(fetch, opts)}
+}
+
+//ListFindings returns the findings matching the filter up to opts.Limit, fetching all pages
+func (c *Client) ListFindings(ctx context.Context, filter *FindingFilter, opts ListOptions) ([]*Finding, error) {
+	findings := make([]*Finding, 0)
+
+	it := c.Findings(filter, opts)
+	for it.Next(ctx) {
+		findings = append(findings, it.Finding())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
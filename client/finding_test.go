@@ -0,0 +1,53 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestListFindingsPages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	var tokens []string
+	httpmock.RegisterResponder("POST", defaultAPIEndpoint+"/findings/query", func(req *http.Request) (*http.Response, error) {
+		body, _ := ioutil.ReadAll(req.Body)
+		query := findingsQuery{}
+		json.Unmarshal(body, &query)
+		assert.Equal(t, []string{"High"}, query.Filters.Levels)
+		tokens = append(tokens, query.PaginationInfo.ContinuationToken)
+
+		if query.PaginationInfo.ContinuationToken == "" {
+			return httpmock.NewStringResponse(http.StatusOK, `{"results": [{"id": "f1"}, {"id": "f2"}], "continuationToken": "page2"}`), nil
+		}
+		return httpmock.NewStringResponse(http.StatusOK, `{"results": [{"id": "f3"}]}`), nil
+	})
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	findings, err := client.ListFindings(context.Background(), &FindingFilter{Levels: []string{"High"}}, ListOptions{})
+
+	assert.Nil(t, err)
+	assert.Len(t, findings, 3)
+	assert.Equal(t, "f3", findings[2].ID)
+	assert.Equal(t, []string{"", "page2"}, tokens)
+}
+
+func TestListFindingsEmpty(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("POST", defaultAPIEndpoint+"/findings/query", httpmock.NewStringResponder(http.StatusOK, `{"results": []}`))
+
+	client, _ := MakeClient("APIkey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	findings, err := client.ListFindings(context.Background(), nil, ListOptions{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, findings)
+}
@@ -0,0 +1,19 @@
+package client
+
+//OrganizationAccount is a member account of a cloud provider organization
+type OrganizationAccount struct {
+	ID     string
+	Name   string
+	Email  string
+	Status string
+	// Management is true for the account managing the organization
+	Management bool
+	// OrganizationalUnits contain the account, from the root down
+	OrganizationalUnits []OrganizationalUnit
+}
+
+//OrganizationalUnit is a group of accounts of an organization
+type OrganizationalUnit struct {
+	ID   string
+	Name string
+}
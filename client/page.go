@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// DefaultPageSize is the number of results fetched per request when ListOptions.PageSize is 0
+const DefaultPageSize = 100
+
+// ListOptions limits the results of a list endpoint and sets the size of the
+// pages they are fetched in.
+type ListOptions struct {
+	// Limit is the maximum number of results, 0 returns all of them.
+	Limit int
+	// PageSize is the number of results fetched per request.
+	PageSize int
+	// Match selects the results, only those are returned and counted towards
+	// Limit. Pages are still fetched in full so that their offsets are the
+	// server's, nil returns all results.
+	Match func(result interface{}) bool
+}
+
+func (o ListOptions) pageSize() int {
+	if o.PageSize > 0 {
+		return o.PageSize
+	}
+	return DefaultPageSize
+}
+
+// pageCursor points at the next page, list endpoints use either the offset
+// or the continuation token returned with the previous page.
+type pageCursor struct {
+	Offset            int
+	ContinuationToken string
+}
+
+// fetchPage fetches up to pageSize results at cursor and returns the cursor of
+// the following page and whether there is one.
+type fetchPage func(ctx context.Context, cursor pageCursor, pageSize int) (items []interface{}, next pageCursor, more bool, err error)
+
+// iterator lazily fetches the pages of a list endpoint. The typed iterators
+// wrap it so that every list endpoint shares the same paging.
+type iterator struct {
+	fetch  fetchPage
+	opts   ListOptions
+	cursor pageCursor
+	more   bool
+	buf    []interface{}
+	cur    interface{}
+	count  int
+	err    error
+}
+
+func newIterator(fetch fetchPage, opts ListOptions) iterator {
+	return iterator{fetch: fetch, opts: opts, more: true}
+}
+
+// next advances to the next result, fetching the next page when the current
+// one is used up. It returns false after the last result or on error.
+func (it *iterator) next(ctx context.Context) bool {
+	it.cur = nil
+	if it.err != nil || (it.opts.Limit > 0 && it.count >= it.opts.Limit) {
+		return false
+	}
+
+	for len(it.buf) == 0 {
+		if !it.more {
+			return false
+		}
+		pageSize := it.opts.pageSize()
+		if remaining := it.opts.Limit - it.count; it.opts.Limit > 0 && it.opts.Match == nil && remaining < pageSize {
+			pageSize = remaining
+		}
+
+		items, next, more, err := it.fetch(ctx, it.cursor, pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.buf, it.cursor, it.more = items, next, more && len(items) > 0
+		if it.opts.Match != nil {
+			it.buf = it.buf[:0:0]
+			for _, item := range items {
+				if it.opts.Match(item) {
+					it.buf = append(it.buf, item)
+				}
+			}
+		}
+	}
+
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	it.count++
+	return true
+}
+
+// pageEnvelope is the response of list endpoints paging with continuation tokens
+type pageEnvelope struct {
+	Results           json.RawMessage `json:"results"`
+	ContinuationToken string          `json:"continuationToken"`
+}
+
+// decodePage decodes a list response into results, which must point to a
+// slice. Endpoints either return the page as a JSON array or wrapped in a
+// pageEnvelope, the continuation token is empty for arrays.
+func decodePage(raw json.RawMessage, results interface{}) (continuationToken string, err error) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) == nil {
+		if _, ok := fields["results"]; ok {
+			envelope := pageEnvelope{}
+			if err := json.Unmarshal(raw, &envelope); err != nil {
+				return "", err
+			}
+			return envelope.ContinuationToken, json.Unmarshal(envelope.Results, results)
+		}
+	}
+	return "", json.Unmarshal(raw, results)
+}
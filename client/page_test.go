@@ -0,0 +1,152 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+// offsetResponder serves total cloud accounts honoring offset and limit
+func offsetResponder(total int, requests *[]string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		*requests = append(*requests, req.URL.RawQuery)
+		offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		var accounts []string
+		for i := offset; i < total && i < offset+limit; i++ {
+			accounts = append(accounts, fmt.Sprintf(`{"_id": "cloud%d"}`, i))
+		}
+		return httpmock.NewStringResponse(http.StatusOK, "["+strings.Join(accounts, ",")+"]"), nil
+	}
+}
+
+func TestCloudAccountsOffsetPaging(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	var requests []string
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", offsetResponder(5, &requests))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	clouds, err := client.GetCloudAccounts(context.Background(), ListOptions{PageSize: 2})
+
+	assert.Nil(t, err)
+	assert.Len(t, clouds, 5)
+	assert.Equal(t, "cloud4", clouds[4].ID)
+	assert.Equal(t, []string{"limit=2&offset=0", "limit=2&offset=2", "limit=2&offset=4"}, requests)
+}
+
+func TestCloudAccountsLimit(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	var requests []string
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", offsetResponder(10, &requests))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	clouds, err := client.GetCloudAccounts(context.Background(), ListOptions{Limit: 3, PageSize: 2})
+
+	assert.Nil(t, err)
+	assert.Len(t, clouds, 3)
+	assert.Equal(t, []string{"limit=2&offset=0", "limit=1&offset=2"}, requests, "the last page should only fetch the remaining results")
+}
+
+func TestCloudAccountsLimitMatching(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	var requests []string
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", offsetResponder(10, &requests))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	odd := func(result interface{}) bool {
+		id := result.(*CloudAccount).ID
+		return (id[len(id)-1]-'0')%2 == 1
+	}
+	clouds, err := client.GetCloudAccounts(context.Background(), ListOptions{Limit: 3, PageSize: 2, Match: odd})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cloud1", "cloud3", "cloud5"}, []string{clouds[0].ID, clouds[1].ID, clouds[2].ID})
+	assert.Equal(t, []string{"limit=2&offset=0", "limit=2&offset=2", "limit=2&offset=4"}, requests, "the limit should count the matching accounts of full pages")
+}
+
+func TestCloudAccountsWithoutPagingSupport(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", httpmock.NewStringResponder(http.StatusOK, `[{"_id": "cloud0"}, {"_id": "cloud1"}]`))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	clouds, err := client.GetCloudAccounts(context.Background(), ListOptions{PageSize: 2})
+
+	assert.Nil(t, err)
+	assert.Len(t, clouds, 2, "accounts returned again for the next offset should not be repeated")
+	assert.Equal(t, 3, httpmock.GetTotalCallCount())
+}
+
+func TestCloudAccountsOverlappingPages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	// an account added while listing shifts cloud1 onto the second page
+	pages := map[string]string{
+		"0": `[{"_id": "cloud0"}, {"_id": "cloud1"}]`,
+		"2": `[{"_id": "cloud1"}, {"_id": "cloud2"}]`,
+		"4": `[{"_id": "cloud3"}]`,
+	}
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(http.StatusOK, pages[req.URL.Query().Get("offset")]), nil
+	})
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	clouds, err := client.GetCloudAccounts(context.Background(), ListOptions{PageSize: 2})
+
+	assert.Nil(t, err)
+	var ids []string
+	for _, cloud := range clouds {
+		ids = append(ids, cloud.ID)
+	}
+	assert.Equal(t, []string{"cloud0", "cloud1", "cloud2", "cloud3"}, ids, "a repeated account should be skipped, not end the listing")
+}
+
+func TestCloudAccountsContinuationToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("continuationToken") == "next" {
+			return httpmock.NewStringResponse(http.StatusOK, `{"results": [{"_id": "cloud1", "provider": "Azure", "subscriptionId": "subscription"}]}`), nil
+		}
+		return httpmock.NewStringResponse(http.StatusOK, `{"results": [{"_id": "cloud0"}], "continuationToken": "next"}`), nil
+	})
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	it := client.CloudAccounts(ListOptions{})
+	var ids []string
+	for it.Next(context.Background()) {
+		ids = append(ids, it.CloudAccount().ID)
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{"cloud0", "cloud1"}, ids)
+}
+
+func TestIteratorStopsOnError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", httpmock.NewStringResponder(http.StatusForbidden, `{"message": "denied"}`))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithCSPEndpoint(cspURL))
+	it := client.CloudAccounts(ListOptions{})
+
+	assert.False(t, it.Next(context.Background()))
+	assert.True(t, IsForbidden(it.Err()))
+	assert.Nil(t, it.CloudAccount())
+}
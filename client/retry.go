@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how often and how long a Client retries a request
+// that failed with a transport error or a transient status code.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, a value below two
+	// disables retries.
+	MaxAttempts int
+	// Timeout bounds the time spent on a request including all retries,
+	// zero means no limit.
+	Timeout time.Duration
+	// MinBackoff is the delay before the first retry, it is doubled for
+	// every following attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the backoff used when only the attempts and timeout are configured.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 1,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+}
+
+// WithRetryPolicy returns a ClientOption for retrying failed requests
+// of a Client.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(opts *clientOptions) {
+		opts.retry = policy
+	}
+}
+
+// doWithRetry sends the request until it succeeds, fails permanently or the
+// policy is exhausted. The last response is returned unread.
+func (c *Client) doWithRetry(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	policy := c.opts.retry
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.makeRequest(ctx, method, path, payload)
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(method, resp, err) {
+			return resp, err
+		}
+
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				delay = after
+			}
+		}
+		if policy.Timeout > 0 && time.Since(start)+delay > policy.Timeout {
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryable reports whether a request may be sent again. Transport errors and
+// gateway failures are only retried for idempotent methods since the server
+// might have processed the request, while 429 is retried for any method as the
+// request was rejected before processing.
+func (p RetryPolicy) retryable(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// backoff returns the exponential delay before the given retry with jitter,
+// so that concurrent clients don't retry in lockstep.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of 429 and 503 responses, which
+// holds either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+		return date.Sub(now), true
+	}
+	return 0, false
+}
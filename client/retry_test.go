@@ -0,0 +1,114 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func flakyResponder(calls *int, failures int, status int, body string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		*calls++
+		if *calls <= failures {
+			return httpmock.NewStringResponse(status, `bad gateway`), nil
+		}
+		return httpmock.NewStringResponse(http.StatusOK, body), nil
+	}
+}
+
+func TestDoRetriesIdempotentRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", flakyResponder(&calls, 2, http.StatusBadGateway, `[]`))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithRetryPolicy(testRetryPolicy))
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+	assert.Nil(t, err, "Do should succeed after retries.")
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoStopsAfterMaxAttempts(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("GET", defaultAPIEndpoint+"/cloudaccounts", flakyResponder(&calls, 5, http.StatusBadGateway, `[]`))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithRetryPolicy(testRetryPolicy))
+	err := client.Do(context.Background(), "GET", "cloudaccounts", nil, nil)
+	assert.NotNil(t, err, "Do should return error when attempts are exhausted.")
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoDoesNotRetryPostOnBadGateway(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("POST", defaultAPIEndpoint+"/cloudaccounts", flakyResponder(&calls, 1, http.StatusBadGateway, `{}`))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithRetryPolicy(testRetryPolicy))
+	err := client.Do(context.Background(), "POST", "cloudaccounts", nil, nil)
+	assert.NotNil(t, err, "Do shouldn't retry a POST request.")
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoRetriesPostOnTooManyRequests(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("POST", defaultAPIEndpoint+"/cloudaccounts", flakyResponder(&calls, 1, http.StatusTooManyRequests, `{}`))
+	httpmock.RegisterResponder("POST", cspURL+cspResource, httpmock.NewStringResponder(http.StatusOK, refreshTokenJSONPayload))
+
+	client, _ := MakeClient("ApiKey", defaultAPIEndpoint, WithRetryPolicy(testRetryPolicy))
+	err := client.Do(context.Background(), "POST", "cloudaccounts", nil, nil)
+	assert.Nil(t, err, "Do should retry a rate limited request.")
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		status int
+		header string
+		delay  time.Duration
+		ok     bool
+	}{
+		{http.StatusTooManyRequests, "3", 3 * time.Second, true},
+		{http.StatusServiceUnavailable, now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{http.StatusServiceUnavailable, "", 0, false},
+		{http.StatusBadGateway, "3", 0, false},
+		{http.StatusTooManyRequests, "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		delay, ok := retryAfter(resp, now)
+		assert.Equal(t, tt.ok, ok, "Retry-After %q", tt.header)
+		assert.Equal(t, tt.delay, delay, "Retry-After %q", tt.header)
+	}
+}
+
+func TestBackoffIsBounded(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		delay := policy.backoff(attempt)
+		assert.True(t, delay >= time.Second/2, "backoff %v too short", delay)
+		assert.True(t, delay <= 4*time.Second, "backoff %v too long", delay)
+	}
+}
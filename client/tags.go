@@ -0,0 +1,117 @@
+package client
+
+import (
+	"strings"
+)
+
+//Tags are the tags of a cloud account. A tag is stored as key:value, or only
+//as a key when it has no value.
+type Tags []string
+
+//Tag is a key/value tag of a cloud account
+type Tag struct {
+	Key   string
+	Value string
+}
+
+//ParseTag parses a tag given as key=value, key:value or only a key
+func ParseTag(s string) Tag {
+	if i := strings.Index(s, "="); i >= 0 {
+		return Tag{Key: strings.TrimSpace(s[:i]), Value: strings.TrimSpace(s[i+1:])}
+	}
+	return parseStoredTag(strings.TrimSpace(s))
+}
+
+//Valid tells whether the tag can be stored, its key is required and can't
+//contain a colon because the stored key ends at the first one
+func (t Tag) Valid() bool {
+	return t.Key != "" && !strings.Contains(t.Key, ":")
+}
+
+// parseStoredTag parses a tag as it is stored, its key ends at the first colon
+func parseStoredTag(s string) Tag {
+	if i := strings.Index(s, ":"); i >= 0 {
+		return Tag{Key: s[:i], Value: s[i+1:]}
+	}
+	return Tag{Key: s}
+}
+
+// stored returns the tag as it is stored
+func (t Tag) stored() string {
+	if t.Value == "" {
+		return t.Key
+	}
+	return t.Key + ":" + t.Value
+}
+
+//String returns the tag as key=value, or only the key when it has no value
+func (t Tag) String() string {
+	if t.Value == "" {
+		return t.Key
+	}
+	return t.Key + "=" + t.Value
+}
+
+//List returns the parsed tags
+func (t Tags) List() []Tag {
+	tags := make([]Tag, len(t))
+	for i, s := range t {
+		tags[i] = parseStoredTag(s)
+	}
+	return tags
+}
+
+//Get returns the value of the tag with the key and whether there is one
+func (t Tags) Get(key string) (string, bool) {
+	for _, tag := range t.List() {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+//Has tells whether there is a tag matching filter, either a key or key=value
+func (t Tags) Has(filter string) bool {
+	want := ParseTag(filter)
+	value, ok := t.Get(want.Key)
+	return ok && (!strings.ContainsAny(filter, "=:") || value == want.Value)
+}
+
+//Set returns the tags with tag added, replacing the value of a tag with its key
+func (t Tags) Set(tag Tag) Tags {
+	tags := make(Tags, 0, len(t)+1)
+	set := false
+	for _, s := range t {
+		if parseStoredTag(s).Key != tag.Key {
+			tags = append(tags, s)
+		} else if !set {
+			tags = append(tags, tag.stored())
+			set = true
+		}
+	}
+	if !set {
+		tags = append(tags, tag.stored())
+	}
+	return tags
+}
+
+//Remove returns the tags without those matching filter, either a key or key=value
+func (t Tags) Remove(filter string) Tags {
+	tags := make(Tags, 0, len(t))
+	for _, s := range t {
+		if !Tags([]string{s}).Has(filter) {
+			tags = append(tags, s)
+		}
+	}
+	return tags
+}
+
+//String renders the tags as [key=value key2], the same way tags are given to the CLI
+func (t Tags) String() string {
+	rendered := make([]string, len(t))
+	for i, tag := range t.List() {
+		rendered[i] = tag.String()
+	}
+	return "[" + strings.Join(rendered, " ") + "]"
+}
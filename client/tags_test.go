@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	assert.Equal(t, Tag{Key: "owner", Value: "team-x"}, ParseTag("owner=team-x"))
+	assert.Equal(t, Tag{Key: "owner", Value: "team-x"}, ParseTag("owner:team-x"))
+	assert.Equal(t, Tag{Key: "url", Value: "https://example.com"}, ParseTag("url=https://example.com"))
+	assert.Equal(t, Tag{Key: "critical"}, ParseTag("critical"))
+	assert.Equal(t, "owner=team-x", ParseTag("owner:team-x").String())
+
+	assert.True(t, ParseTag("owner:team-x").Valid())
+	assert.False(t, ParseTag("team:owner=x").Valid(), "a colon in the key would be stored as part of the value")
+	assert.False(t, ParseTag("=x").Valid())
+}
+
+func TestTags(t *testing.T) {
+	tags := Tags{"owner:team-x", "critical", "url:https://example.com"}
+
+	value, ok := tags.Get("url")
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com", value)
+	assert.True(t, tags.Has("owner"))
+	assert.True(t, tags.Has("owner=team-x"))
+	assert.False(t, tags.Has("owner=team-y"))
+	assert.True(t, tags.Has("critical"))
+
+	assert.Equal(t, Tags{"owner:team-y", "critical", "url:https://example.com"}, tags.Set(ParseTag("owner=team-y")))
+	assert.Equal(t, Tags{"owner:team-x", "critical", "url:https://example.com", "env:prod"}, tags.Set(ParseTag("env=prod")))
+	assert.Equal(t, Tags{"critical", "url:https://example.com"}, tags.Remove("owner"))
+	assert.Equal(t, tags, tags.Remove("owner=team-y"))
+	assert.Equal(t, Tags{"owner:team-x", "critical", "url:https://example.com"}, tags, "the tags are left unchanged")
+
+	assert.Equal(t, "[owner=team-x critical url=https://example.com]", tags.String())
+	assert.Equal(t, "[]", Tags(nil).String())
+}
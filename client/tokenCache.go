@@ -0,0 +1,113 @@
+// Copyright © 2016 Paul Allen <paul@cloudcoreo.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tokenExpirySkew is how long before its expiry an access token is refreshed,
+// so that a token never expires while a request is in flight.
+const tokenExpirySkew = 60 * time.Second
+
+// cachedToken is an access token together with the time it expires at. The
+// refresh token it was exchanged for is kept as a hash so that a cached token
+// is not reused after the profile's API key changes.
+type cachedToken struct {
+	AccessToken      string    `json:"accessToken"`
+	Expiry           time.Time `json:"expiry"`
+	RefreshTokenHash string    `json:"refreshTokenHash"`
+}
+
+func newCachedToken(token *cspToken, refreshToken string, now time.Time) *cachedToken {
+	return &cachedToken{
+		AccessToken:      token.AccessToken,
+		Expiry:           now.Add(time.Duration(token.ExpiresIn) * time.Second),
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+	}
+}
+
+func (t *cachedToken) valid(refreshToken string, now time.Time) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	if t.RefreshTokenHash != hashRefreshToken(refreshToken) {
+		return false
+	}
+	return now.Add(tokenExpirySkew).Before(t.Expiry)
+}
+
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *Auth) loadCachedToken() *cachedToken {
+	if a.CacheFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(a.CacheFile)
+	if err != nil {
+		return nil
+	}
+	token := new(cachedToken)
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil
+	}
+	return token
+}
+
+func (a *Auth) saveCachedToken() error {
+	if a.CacheFile == "" || a.token == nil {
+		return nil
+	}
+	data, err := json.Marshal(a.token)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(a.CacheFile)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// TempFile creates the file readable by the owner only, renaming it
+	// keeps those permissions and never leaves a half written cache behind.
+	f, err := ioutil.TempFile(dir, filepath.Base(a.CacheFile))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), a.CacheFile)
+}
+
+func (a *Auth) removeCachedToken() {
+	if a.CacheFile != "" {
+		os.Remove(a.CacheFile)
+	}
+}
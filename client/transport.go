@@ -0,0 +1,165 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TransportConfig holds the network settings of the API, CSP and webhook
+// requests. The zero value uses the environment proxy and the system roots.
+type TransportConfig struct {
+	// ProxyURL is the proxy all requests are sent through, HTTPS_PROXY and
+	// HTTP_PROXY are used when it is empty.
+	ProxyURL string
+	// NoProxy is a comma separated list of hosts, domains (".example.com")
+	// or "*" that are connected to directly, bypassing ProxyURL or the
+	// environment proxy.
+	NoProxy string
+	// CABundle is a PEM file of root certificates trusted in addition to the system roots.
+	CABundle string
+	// ClientCert and ClientKey are the PEM files of the certificate presented for mutual TLS.
+	ClientCert string
+	ClientKey  string
+	// TLSMinVersion is the lowest TLS version accepted, one of 1.0, 1.1, 1.2 or 1.3.
+	TLSMinVersion string
+}
+
+// envProxy returns the proxy of the environment, tests replace it since
+// http.ProxyFromEnvironment reads the environment once
+var envProxy = http.ProxyFromEnvironment
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewTransport returns an http.Transport that keeps connections to the API
+// alive, so that it can be shared by clients making many requests.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return envProxy(req)
+		},
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// Transport returns a keep-alive transport with the proxy and TLS settings of the config.
+func (cfg TransportConfig) Transport() (*http.Transport, error) {
+	t := NewTransport()
+
+	proxy := t.Proxy
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	noProxy := splitNoProxy(cfg.NoProxy)
+	t.Proxy = func(req *http.Request) (*url.URL, error) {
+		if useProxy(req.URL.Hostname(), noProxy) {
+			return proxy(req)
+		}
+		return nil, nil
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+
+	return t, nil
+}
+
+// IsZero reports whether the config leaves every setting at its default.
+func (cfg TransportConfig) IsZero() bool {
+	return cfg == TransportConfig{}
+}
+
+func (cfg TransportConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.TLSMinVersion != "" {
+		version, ok := tlsVersions[cfg.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version %q, expected one of 1.0, 1.1, 1.2 or 1.3", cfg.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if cfg.CABundle != "" {
+		pem, err := ioutil.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CA bundle")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func splitNoProxy(noProxy string) []string {
+	var hosts []string
+	for _, host := range strings.Split(noProxy, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// useProxy reports whether host is not matched by the no-proxy list. An entry
+// matches the host itself and, with or without a leading dot, its subdomains.
+func useProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range noProxy {
+		if entry == "*" {
+			return false
+		}
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseProxy(t *testing.T) {
+	noProxy := splitNoProxy(" internal.test, .corp.test ,localhost")
+
+	assert.True(t, useProxy("api.example.com", noProxy))
+	assert.False(t, useProxy("internal.test", noProxy))
+	assert.False(t, useProxy("api.internal.test", noProxy))
+	assert.False(t, useProxy("corp.test", noProxy))
+	assert.False(t, useProxy("CSP.Corp.Test", noProxy))
+	assert.True(t, useProxy("notcorp.test", noProxy))
+	assert.False(t, useProxy("anything", splitNoProxy("*")))
+}
+
+func TestTransportProxy(t *testing.T) {
+	transport, err := TransportConfig{ProxyURL: "http://proxy.test:3128", NoProxy: "internal.test"}.Transport()
+	assert.Nil(t, err)
+
+	req, _ := http.NewRequest("GET", "https://app.securestate.vmware.com/api", nil)
+	proxy, _ := transport.Proxy(req)
+	assert.Equal(t, "http://proxy.test:3128", proxy.String())
+
+	req, _ = http.NewRequest("GET", "https://api.internal.test", nil)
+	proxy, _ = transport.Proxy(req)
+	assert.Nil(t, proxy)
+
+	_, err = TransportConfig{ProxyURL: "proxy.test"}.Transport()
+	assert.NotNil(t, err, "a proxy URL without scheme should be rejected")
+}
+
+func TestTransportEnvironmentProxy(t *testing.T) {
+	defer func(proxy func(*http.Request) (*url.URL, error)) {
+		envProxy = proxy
+	}(envProxy)
+	envProxy = func(req *http.Request) (*url.URL, error) {
+		return url.Parse("http://env-proxy.test:3128")
+	}
+
+	transport, err := TransportConfig{NoProxy: "internal.test"}.Transport()
+	assert.Nil(t, err)
+
+	req, _ := http.NewRequest("GET", "https://app.securestate.vmware.com/api", nil)
+	proxy, _ := transport.Proxy(req)
+	assert.Equal(t, "http://env-proxy.test:3128", proxy.String())
+
+	req, _ = http.NewRequest("GET", "https://api.internal.test", nil)
+	proxy, _ = transport.Proxy(req)
+	assert.Nil(t, proxy, "--no-proxy should bypass the environment proxy")
+}
+
+func TestTransportTLSMinVersion(t *testing.T) {
+	transport, err := TransportConfig{}.Transport()
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+
+	transport, err = TransportConfig{TLSMinVersion: "1.3"}.Transport()
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), transport.TLSClientConfig.MinVersion)
+
+	_, err = TransportConfig{TLSMinVersion: "3"}.Transport()
+	assert.NotNil(t, err)
+}
+
+func TestTransportCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "transport")
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	_, err := (&http.Client{Transport: NewTransport()}).Get(server.URL)
+	assert.NotNil(t, err, "the test server should not be trusted without the CA bundle")
+
+	transport, err := TransportConfig{CABundle: bundle}.Transport()
+	assert.Nil(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	empty := filepath.Join(dir, "empty.pem")
+	ioutil.WriteFile(empty, []byte("no certificates"), 0600)
+	_, err = TransportConfig{CABundle: empty}.Transport()
+	assert.NotNil(t, err)
+}
+
+func TestTransportClientCertRequiresKey(t *testing.T) {
+	_, err := TransportConfig{ClientCert: "client.pem"}.Transport()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/CloudCoreo/cli/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+type applyCmd struct {
+	out                 io.Writer
+	client              command.Interface
+	cloud               command.CloudProvider
+	file                string
+	prune               bool
+	ignoreMissingTrails bool
+}
+
+func newApplyCmd(client command.Interface, provider command.CloudProvider, out io.Writer) *cobra.Command {
+	apply := &applyCmd{
+		out:    out,
+		client: client,
+		cloud:  provider,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdApplyUse,
+		Short:   content.CmdApplyShort,
+		Long:    content.CmdApplyLong,
+		Example: content.CmdApplyExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if apply.file == "" {
+				return fmt.Errorf(content.ErrorFileRequired)
+			}
+			if apply.client == nil {
+				apply.client = newCoreoClient()
+			}
+
+			return apply.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&apply.file, content.CmdFlagFileLong, content.CmdFlagFileShort, "", content.CmdFlagInventoryFileDescription)
+	f.BoolVarP(&apply.prune, content.CmdFlagPrune, "", false, content.CmdFlagPruneDescription)
+	f.BoolVarP(&apply.ignoreMissingTrails, content.CmdFlagIgnoreMissingTrails, "", false, content.CmdFlagIgnoreMissingTrailsDescription)
+
+	return cmd
+}
+
+func (t *applyCmd) run() error {
+	changes, unmanaged, err := planInventory(t.client, t.file, t.prune)
+	if err != nil {
+		return err
+	}
+
+	out := t.out
+	if jsonFormat {
+		out = ioutil.Discard
+	}
+	printPlan(out, changes, unmanaged)
+	if len(changes) == 0 {
+		if jsonFormat {
+			util.PrettyPrintJSON(changes)
+		}
+		return nil
+	}
+	fmt.Fprintln(out)
+
+	importer := &cloudImportCmd{client: t.client, cloud: t.cloud, ignoreMissingTrails: t.ignoreMissingTrails}
+	counts := make(map[string]int)
+	for i := range changes {
+		change := &changes[i]
+		if err := t.apply(importer, change, out); err != nil {
+			if jsonFormat {
+				util.PrettyPrintJSON(changes[:i])
+			}
+			return fmt.Errorf(content.ErrorApplyFailed, change.Action, change.Name, err)
+		}
+		counts[change.Action]++
+	}
+
+	if jsonFormat {
+		util.PrettyPrintJSON(changes)
+		return nil
+	}
+	fmt.Fprintf(out, content.InfoApplySummary, counts[inventory.ActionCreate], counts[inventory.ActionUpdate], counts[inventory.ActionDelete])
+	return nil
+}
+
+// apply makes a change of the plan, setting the cloud ID of added accounts
+func (t *applyCmd) apply(importer *cloudImportCmd, change *inventory.Change, out io.Writer) error {
+	if err := rootCtx.Err(); err != nil {
+		return err
+	}
+
+	switch change.Action {
+	case inventory.ActionCreate:
+		cloud, err := importer.importAccount(*change.Account)
+		if err != nil {
+			return err
+		}
+		change.CloudID = cloud.ID
+		fmt.Fprintf(out, content.InfoApplyCreated, change.Name, change.CloudID)
+
+		if change.Account.EventStream {
+			if err := importer.setupEventStream(*change.Account, change.CloudID); err != nil {
+				return fmt.Errorf(content.ErrorEventStreamSetup, err)
+			}
+			fmt.Fprintf(out, content.InfoApplyEventStream, change.Name, change.CloudID)
+		}
+	case inventory.ActionUpdate:
+		if _, err := t.client.UpdateCloudAccount(rootCtx, inventoryUpdateInput(change.Account, change.Current)); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, content.InfoApplyUpdated, change.Name, change.CloudID)
+	case inventory.ActionDelete:
+		if err := t.client.DeleteCloudAccountByID(rootCtx, change.CloudID); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, content.InfoApplyDeleted, change.Name, change.CloudID)
+	}
+	return nil
+}
+
+// inventoryUpdateInput returns the update of the current account to the fields
+// set in the inventory, the others keep their current value
+func inventoryUpdateInput(account *inventory.Account, current *client.CloudAccount) *client.UpdateCloudAccountInput {
+	input := &client.UpdateCloudAccountInput{
+		CreateCloudAccountInput: client.CreateCloudAccountInput{
+			CloudName:    account.Name,
+			IsDraft:      current.IsDraft,
+			Environment:  current.Environment,
+			ScanSettings: inventoryScanSettings(*account, current),
+		},
+		CloudID: current.ID,
+	}
+	if account.Environment != "" {
+		input.Environment = account.Environment
+	}
+	if len(account.Tags) > 0 {
+		input.Tags = strings.Join(account.Tags, "|")
+	}
+	return input
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+)
+
+func TestPlanAndApplyCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vss-apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	changed := writeFile("changed.yaml", `version: v1
+accounts:
+- name: prod
+  environment: Production
+- name: new
+  roleArn: arn:aws:iam::222222222222:role/vss
+  externalId: ext
+  eventStream: true
+`)
+	unchanged := writeFile("unchanged.yaml", `- name: prod
+  environment: Development
+  tags: [b, a]
+`)
+	eventStream := writeFile("eventStream.yaml", `- name: prod
+  eventStream: true
+`)
+	invalid := writeFile("invalid.yaml", `- name: new
+  roleArn: arn:aws:iam::222222222222:role/vss
+`)
+
+	current := func() []*client.CloudAccount {
+		return []*client.CloudAccount{
+			{ID: "id1", AccountID: "111111111111", CloudInfo: client.CloudInfo{Name: "prod", Provider: "AWS", Environment: "Development", Tags: []string{"a", "b"}}},
+			{ID: "id2", AccountID: "999999999999", CloudInfo: client.CloudInfo{Name: "old", Provider: "AWS"}},
+		}
+	}
+
+	tests := []struct {
+		desc  string
+		apply bool
+		flags []string
+		err   bool
+		xout  string
+	}{
+		{
+			desc:  "plan shows the diff",
+			flags: []string{"-f", changed},
+			xout:  `~ update prod \(id1\)\n    environment: "Development" => "Production"\n\+ add new\n\nPlan: 1 to add, 1 to update, 0 to delete.\n1 cloud accounts aren't listed`,
+		},
+		{
+			desc:  "plan with prune deletes unlisted accounts",
+			flags: []string{"-f", changed, "--prune"},
+			xout:  `- delete old \(id2\)\n\nPlan: 1 to add, 1 to update, 1 to delete.\n$`,
+		},
+		{
+			desc:  "plan of a matching file makes no changes",
+			flags: []string{"-f", unchanged},
+			xout:  `No changes`,
+		},
+		{
+			desc:  "plan validates the accounts to add",
+			flags: []string{"-f", invalid},
+			err:   true,
+		},
+		{
+			desc:  "apply makes the changes",
+			apply: true,
+			flags: []string{"-f", changed, "--prune"},
+			xout:  `Updated prod \(id1\)\nAdded new \(id1\)\nSet up the event stream of new \(id1\)\nDeleted old \(id2\)\nApply complete: 1 added, 1 updated, 1 deleted.\n$`,
+		},
+		{
+			desc:  "apply of a matching file makes no changes",
+			apply: true,
+			flags: []string{"-f", unchanged},
+			xout:  `No changes`,
+		},
+		{
+			desc:  "apply again after the event stream was set up makes no changes",
+			apply: true,
+			flags: []string{"-f", eventStream},
+			xout:  `^No changes`,
+		},
+		{
+			desc:  "apply without a file",
+			apply: true,
+			err:   true,
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: current(), regions: []string{"us-east-1"}}
+
+		cmd := newPlanCmd(frc, &buf)
+		if tt.apply {
+			cmd = newApplyCmd(frc, &fakeCloudProvider{}, &buf)
+		}
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, nil)
+
+		if (err != nil) != tt.err {
+			t.Errorf("%q. expected error %v, got '%v'", tt.desc, tt.err, err)
+		}
+
+		re := regexp.MustCompile(tt.xout)
+		if !re.Match(buf.Bytes()) {
+			t.Fatalf("%q:\nexpected\n\t%q\nactual\n\t%q", tt.desc, tt.xout, buf.String())
+		}
+		buf.Reset()
+	}
+}
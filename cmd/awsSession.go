@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+// awsSession is the role AWS commands assume with the credentials of their
+// profile, it lets them act in a member account from a central identity account
+type awsSession struct {
+	assumeRoleArn        string
+	externalID           string
+	mfaSerial            string
+	webIdentityTokenFile string
+	roleSessionName      string
+	duration             int64
+}
+
+// addFlags adds the session flags to cmd. A command that already has
+// --role-session and --duration for a role of its own, like cloud scan, uses
+// them for the assumed role too.
+func (s *awsSession) addFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&s.assumeRoleArn, content.CmdFlagAssumeRoleArn, "", content.CmdFlagAssumeRoleArnDescription)
+	flags.StringVar(&s.externalID, content.CmdFlagAssumeRoleExternalID, "", content.CmdFlagAssumeRoleExternalIDDescription)
+	flags.StringVar(&s.mfaSerial, content.CmdFlagMFASerial, "", content.CmdFlagMFASerialDescription)
+	flags.StringVar(&s.webIdentityTokenFile, content.CmdFlagWebIdentityTokenFile, "", content.CmdFlagWebIdentityTokenFileDescription)
+	if flags.Lookup(content.CmdFlagRoleSessionName) == nil {
+		flags.StringVar(&s.roleSessionName, content.CmdFlagRoleSessionName, "", content.CmdFlagAssumeRoleSessionNameDescription)
+	}
+	if flags.Lookup(content.CmdFlagDuration) == nil {
+		flags.Int64Var(&s.duration, content.CmdFlagDuration, 0, content.CmdFlagAssumeRoleDurationDescription)
+	}
+}
+
+// serviceInput returns the input of the AWS service acting with the profile
+// and the role of the session
+func (s *awsSession) serviceInput(awsProfile, awsProfilePath string) *aws.NewServiceInput {
+	return &aws.NewServiceInput{
+		AwsProfile:           awsProfile,
+		AwsProfilePath:       awsProfilePath,
+		AssumeRoleArn:        s.assumeRoleArn,
+		ExternalID:           s.externalID,
+		MFASerial:            s.mfaSerial,
+		WebIdentityTokenFile: s.webIdentityTokenFile,
+		RoleSessionName:      s.roleSessionName,
+		Duration:             s.duration,
+		HTTPClient:           httpClient,
+	}
+}
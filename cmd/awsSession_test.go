@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSSessionServiceInput(t *testing.T) {
+	cmd := &cobra.Command{}
+	session := &awsSession{}
+	session.addFlags(cmd)
+	assert.Nil(t, cmd.ParseFlags([]string{
+		"--assume-role-arn", "arn:aws:iam::222222222222:role/admin",
+		"--assume-role-external-id", "external-1",
+		"--mfa-serial", "arn:aws:iam::111111111111:mfa/user",
+		"--role-session", "ci",
+		"--duration", "900",
+	}))
+
+	assert.Equal(t, &aws.NewServiceInput{
+		AwsProfile:      "central",
+		AwsProfilePath:  "/tmp/config",
+		AssumeRoleArn:   "arn:aws:iam::222222222222:role/admin",
+		ExternalID:      "external-1",
+		MFASerial:       "arn:aws:iam::111111111111:mfa/user",
+		RoleSessionName: "ci",
+		Duration:        900,
+	}, session.serviceInput("central", "/tmp/config"))
+}
+
+func TestAWSSessionFlags(t *testing.T) {
+	// --external-id stays the one of the VSS role
+	add := newCloudCreateCmd(&fakeReleaseClient{}, &bytes.Buffer{})
+	for _, flag := range []string{"external-id", "assume-role-arn", "assume-role-external-id", "mfa-serial", "web-identity-token-file", "role-session", "duration"} {
+		assert.NotNil(t, add.Flags().Lookup(flag), flag)
+	}
+
+	// cloud scan keeps its --role-session and --duration of the cross-account role
+	scan := newCloudScanCmd(&fakeReleaseClient{}, nil, &bytes.Buffer{})
+	assert.NotNil(t, scan.Flags().Lookup("assume-role-arn"))
+	assert.Equal(t, aws.DefaultRoleSessionName, scan.Flags().Lookup("role-session").DefValue)
+	assert.Equal(t, "3600", scan.Flags().Lookup("duration").DefValue)
+}
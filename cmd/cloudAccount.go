@@ -17,12 +17,13 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/CloudCoreo/cli/client"
 	"github.com/CloudCoreo/cli/pkg/command"
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/cmd/util"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -40,13 +41,23 @@ func newCloudAccountCmd(out io.Writer) *cobra.Command {
 	cmd.AddCommand(newCloudCreateCmd(nil, out))
 	cmd.AddCommand(newCloudUpdateCmd(nil, out))
 	cmd.AddCommand(newCloudTestCmd(nil, out))
+	cmd.AddCommand(newCloudImportCmd(nil, nil, out))
+	cmd.AddCommand(newCloudExportCmd(nil, out))
+	cmd.AddCommand(newCloudScanCmd(nil, nil, out))
+	cmd.AddCommand(newCloudTagCmd(nil, out))
+	cmd.AddCommand(newCloudRotateCmd(nil, out))
+	cmd.AddCommand(newCloudPolicyCmd(nil, out))
 
 	return cmd
 }
 
 type cloudListCmd struct {
-	out    io.Writer
-	client command.Interface
+	out     io.Writer
+	client  command.Interface
+	opts    client.ListOptions
+	filter  cloudAccountFilter
+	sortBy  string
+	columns string
 }
 
 func newCloudListCmd(client command.Interface, out io.Writer) *cobra.Command {
@@ -56,47 +67,107 @@ func newCloudListCmd(client command.Interface, out io.Writer) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   content.CmdListUse,
-		Short: content.CmdCloudListShort,
-		Long:  content.CmdCloudListLong,
+		Use:     content.CmdListUse,
+		Short:   content.CmdCloudListShort,
+		Long:    content.CmdCloudListLong,
+		Example: content.CmdCloudListExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cloudList.filter.parse(cmd); err != nil {
+				return err
+			}
 
 			if cloudList.client == nil {
-				cloudList.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				cloudList.client = newCoreoClient()
 			}
 
 			return cloudList.run()
 		},
 	}
 
+	addListFlags(cmd, &cloudList.opts)
+	cloudList.filter.addFlags(cmd)
+
+	f := cmd.Flags()
+	f.StringVar(&cloudList.sortBy, content.CmdFlagSortByLong, "", content.CmdFlagSortByDescription)
+	f.StringVar(&cloudList.columns, content.CmdFlagColumnsLong, "", content.CmdFlagColumnsDescription)
+
 	return cmd
 }
 
+// addListFlags adds the --limit and --page-size flags of list commands
+func addListFlags(cmd *cobra.Command, opts *client.ListOptions) {
+	f := cmd.Flags()
+	f.IntVar(&opts.Limit, content.CmdFlagLimitLong, 0, content.CmdFlagLimitDescription)
+	f.IntVar(&opts.PageSize, content.CmdFlagPageSizeLong, client.DefaultPageSize, content.CmdFlagPageSizeDescription)
+}
+
 func (t *cloudListCmd) run() error {
-	clouds, err := t.client.ListCloudAccounts()
+	headers := []string{"ID", "Name", "AccountID", "IsDraft", "Tags", "Provider"}
+	headersMap := map[string]string{
+		"ID":        "ID",
+		"Name":      "Cloud Account Name",
+		"AccountID": "Cloud account ID",
+		"IsDraft":   "IsDraft",
+		"Tags":      "Tags",
+		"Provider":  "Provider",
+	}
+	var fields []cloudAccountField
+	if t.columns != "" {
+		var err error
+		if fields, err = selectColumns(t.columns); err != nil {
+			return err
+		}
+		headers = nil
+		for _, field := range fields {
+			headers = append(headers, field.name)
+			if _, ok := headersMap[field.name]; !ok {
+				headersMap[field.name] = field.name
+			}
+		}
+	}
+	if t.sortBy != "" {
+		if _, err := lookupCloudAccountField(strings.TrimPrefix(t.sortBy, "-")); err != nil {
+			return err
+		}
+	}
+
+	opts := t.opts
+	if t.filter.isSet() {
+		// the limit counts the accounts passing the filters
+		opts.Match = func(result interface{}) bool {
+			cloud, ok := result.(*client.CloudAccount)
+			return ok && t.filter.match(cloud)
+		}
+	}
+	if t.sortBy != "" {
+		// the limit applies to the sorted accounts, so all of them are fetched
+		opts.Limit = 0
+	}
+	clouds, err := t.client.ListCloudAccounts(rootCtx, opts)
 	if err != nil {
 		return err
 	}
+	if t.sortBy != "" {
+		sortCloudAccounts(clouds, t.sortBy)
+	}
+	if t.opts.Limit > 0 && len(clouds) > t.opts.Limit {
+		clouds = clouds[:t.opts.Limit]
+	}
 
 	b := make([]interface{}, len(clouds))
 	for i := range clouds {
-		b[i] = clouds[i]
+		if jsonFormat && fields != nil {
+			b[i] = projectCloudAccount(clouds[i], fields)
+		} else {
+			b[i] = clouds[i]
+		}
 	}
 
 	util.PrintResult(
 		t.out,
 		b,
-		[]string{"ID", "Name", "AccountID", "IsDraft", "Tags", "Provider"},
-		map[string]string{
-			"ID":        "ID",
-			"Name":      "Cloud Account Name",
-			"AccountID": "Cloud account ID",
-			"IsDraft":   "IsDraft",
-			"Tags":      "Tags",
-			"Provider":  "Provider",
-		},
+		headers,
+		headersMap,
 		jsonFormat,
 		verbose)
 
@@ -104,9 +175,12 @@ func (t *cloudListCmd) run() error {
 }
 
 type cloudTestCmd struct {
-	out     io.Writer
-	client  command.Interface
-	cloudID string
+	out         io.Writer
+	client      command.Interface
+	cloudID     string
+	all         bool
+	filter      cloudAccountFilter
+	concurrency int
 }
 
 func newCloudTestCmd(client command.Interface, out io.Writer) *cobra.Command {
@@ -116,19 +190,36 @@ func newCloudTestCmd(client command.Interface, out io.Writer) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   content.CmdTestUse,
-		Short: content.CmdCloudTestShort,
-		Long:  content.CmdCloudTestLong,
+		Use:     content.CmdTestUse,
+		Short:   content.CmdCloudTestShort,
+		Long:    content.CmdCloudTestLong,
+		Example: content.CmdCloudTestExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := util.CheckCloudShowOrDeleteFlag(cloudTest.cloudID, verbose); err != nil {
+			if err := cloudTest.filter.parse(cmd); err != nil {
 				return err
 			}
+			if cloudTest.all {
+				if cloudTest.cloudID != "" {
+					return fmt.Errorf(content.ErrorCloudIDAndAll)
+				}
+				if cloudTest.concurrency < 1 {
+					return fmt.Errorf(content.ErrorConcurrency)
+				}
+			} else {
+				if cloudTest.filter.isSet() {
+					return fmt.Errorf(content.ErrorFilterRequiresAll)
+				}
+				if err := util.CheckCloudShowOrDeleteFlag(cloudTest.cloudID, verbose); err != nil {
+					return err
+				}
+			}
 			if cloudTest.client == nil {
-				cloudTest.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				cloudTest.client = newCoreoClient()
 			}
 
+			if cloudTest.all {
+				return cloudTest.runAll()
+			}
 			return cloudTest.run()
 		},
 	}
@@ -136,12 +227,15 @@ func newCloudTestCmd(client command.Interface, out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 
 	f.StringVarP(&cloudTest.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
+	f.BoolVar(&cloudTest.all, content.CmdFlagAllLong, false, content.CmdFlagTestAllDescription)
+	f.IntVar(&cloudTest.concurrency, content.CmdFlagConcurrencyLong, defaultTestConcurrency, content.CmdFlagConcurrencyDescription)
+	cloudTest.filter.addFlags(cmd)
 
 	return cmd
 }
 
 func (t *cloudTestCmd) run() error {
-	res, err := t.client.ReValidateRole(t.cloudID)
+	res, err := t.client.ReValidateRole(rootCtx, t.cloudID)
 	if err != nil {
 		return err
 	}
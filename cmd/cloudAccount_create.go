@@ -15,18 +15,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"time"
+	"strings"
 
 	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/azure"
+	"github.com/CloudCoreo/cli/pkg/gcp"
 
 	"github.com/CloudCoreo/cli/client"
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/cmd/util"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -40,7 +42,9 @@ type cloudCreateCmd struct {
 	roleArn        string
 	awsProfile     string
 	awsProfilePath string
+	session        awsSession
 	policy         string
+	inlinePolicy   bool
 	isDraft        bool
 	userName       string
 	email          string
@@ -53,6 +57,19 @@ type cloudCreateCmd struct {
 	directoryID    string
 	subscriptionID string
 	tags           string
+	tagList        []string
+	scanSettings   *client.ScanSettings
+
+	projectID                string
+	serviceAccountEmail      string
+	serviceAccountKey        string
+	workloadIdentityProvider string
+	workloadIdentitySubject  string
+	gcpRoles                 string
+	gcpCredentials           string
+
+	azureRoles string
+	authFile   string
 }
 
 func newCloudCreateCmd(client command.Interface, out io.Writer) *cobra.Command {
@@ -74,23 +91,44 @@ func newCloudCreateCmd(client command.Interface, out io.Writer) *cobra.Command {
 				if err := util.CheckCloudAddFlagsForAWS(cloudCreate.externalID, cloudCreate.roleArn, cloudCreate.roleName, cloudCreate.environment); err != nil {
 					return err
 				}
+			} else if cloudCreate.provider == "GCP" {
+				if err := util.CheckCloudAddFlagsForGCP(cloudCreate.projectID, cloudCreate.serviceAccountEmail, cloudCreate.serviceAccountKey, cloudCreate.workloadIdentityProvider, cloudCreate.roleName, cloudCreate.environment); err != nil {
+					return err
+				}
+				cloudCreate.policy = cloudCreate.gcpRoles
 			} else {
-				if err := util.CheckCloudAddFlagsForAzure(cloudCreate.keyValue, cloudCreate.applicationID, cloudCreate.directoryID, cloudCreate.subscriptionID, cloudCreate.environment); err != nil {
+				if err := util.CheckCloudAddFlagsForAzure(cloudCreate.keyValue, cloudCreate.applicationID, cloudCreate.directoryID, cloudCreate.subscriptionID, cloudCreate.roleName, cloudCreate.environment); err != nil {
 					return err
 				}
+				cloudCreate.policy = cloudCreate.azureRoles
+			}
+
+			if cloudCreate.inlinePolicy && cloudCreate.provider != "AWS" {
+				return fmt.Errorf(content.ErrorInlinePolicyNotSupported, cloudCreate.provider)
 			}
 
 			if cloudCreate.client == nil {
-				cloudCreate.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				cloudCreate.client = newCoreoClient()
 			}
 
+			if cloudCreate.cloud == nil && cloudCreate.provider == "GCP" {
+				cloudCreate.cloud = gcp.NewService(&gcp.NewServiceInput{
+					ProjectID:                cloudCreate.projectID,
+					CredentialsFile:          cloudCreate.gcpCredentials,
+					WorkloadIdentityProvider: cloudCreate.workloadIdentityProvider,
+					WorkloadIdentitySubject:  cloudCreate.workloadIdentitySubject,
+					HTTPClient:               httpClient,
+				})
+			}
+			if cloudCreate.cloud == nil && cloudCreate.provider == "Azure" {
+				cloudCreate.cloud = azure.NewService(&azure.NewServiceInput{
+					AuthFile:       cloudCreate.authFile,
+					SubscriptionID: cloudCreate.subscriptionID,
+					HTTPClient:     httpClient,
+				})
+			}
 			if cloudCreate.cloud == nil {
-				newServiceInput := &aws.NewServiceInput{
-					AwsProfile:     cloudCreate.awsProfile,
-					AwsProfilePath: cloudCreate.awsProfilePath,
-				}
+				newServiceInput := cloudCreate.session.serviceInput(cloudCreate.awsProfile, cloudCreate.awsProfilePath)
 				cloudCreate.cloud = aws.NewService(newServiceInput)
 			}
 
@@ -106,7 +144,9 @@ func newCloudCreateCmd(client command.Interface, out io.Writer) *cobra.Command {
 	f.StringVarP(&cloudCreate.externalID, content.CmdFlagRoleExternalID, "", "", content.CmdFlagRoleExternalIDDescription)
 	f.StringVarP(&cloudCreate.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
 	f.StringVarP(&cloudCreate.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	cloudCreate.session.addFlags(cmd)
 	f.StringVarP(&cloudCreate.policy, content.CmdFlagAwsPolicy, "", content.CmdFlagAwsPolicyDefault, content.CmdFlagAwsPolicyDescription)
+	f.BoolVarP(&cloudCreate.inlinePolicy, content.CmdFlagInlinePolicy, "", false, content.CmdFlagInlinePolicyDescription)
 	f.BoolVarP(&cloudCreate.isDraft, content.CmdFlagIsDraft, "", false, content.CmdFlagIsDraftDescription)
 	f.StringVarP(&cloudCreate.email, content.CmdFlagEmail, "", "", content.CmdFlagEmailDescription)
 	f.StringVarP(&cloudCreate.userName, content.CmdFlagUserName, "", "", content.CmdFlagUserNameDescription)
@@ -117,11 +157,45 @@ func newCloudCreateCmd(client command.Interface, out io.Writer) *cobra.Command {
 	f.StringVarP(&cloudCreate.directoryID, content.CmdFlagDirectoryID, "", "", content.CmdFlagDirectoryIDDescription)
 	f.StringVarP(&cloudCreate.subscriptionID, content.CmdFlagSubscriptionID, "", "", content.CmdFlagSubscriptionIDDescription)
 	f.StringVarP(&cloudCreate.tags, content.CmdFlagTags, "", "", content.CmdFlagTagsDescription)
+	f.StringArrayVar(&cloudCreate.tagList, content.CmdFlagTagLong, nil, content.CmdFlagTagDescription)
+	f.StringVarP(&cloudCreate.projectID, content.CmdFlagProjectID, "", "", content.CmdFlagProjectIDDescription)
+	f.StringVarP(&cloudCreate.serviceAccountEmail, content.CmdFlagServiceAccountEmail, "", "", content.CmdFlagServiceAccountEmailDescription)
+	f.StringVarP(&cloudCreate.serviceAccountKey, content.CmdFlagServiceAccountKey, "", "", content.CmdFlagServiceAccountKeyDescription)
+	f.StringVarP(&cloudCreate.workloadIdentityProvider, content.CmdFlagWorkloadIdentityProvider, "", "", content.CmdFlagWorkloadIdentityProviderDescription)
+	f.StringVarP(&cloudCreate.workloadIdentitySubject, content.CmdFlagWorkloadIdentitySubject, "", "", content.CmdFlagWorkloadIdentitySubjectDescription)
+	f.StringVarP(&cloudCreate.gcpRoles, content.CmdFlagGCPRoles, "", gcp.DefaultRoles, content.CmdFlagGCPRolesDescription)
+	f.StringVarP(&cloudCreate.gcpCredentials, content.CmdFlagGCPCredentials, "", "", content.CmdFlagGCPCredentialsDescription)
+	f.StringVarP(&cloudCreate.azureRoles, content.CmdFlagAzureRoles, "", azure.DefaultRoles, content.CmdFlagAzureRolesDescription)
+	f.StringVarP(&cloudCreate.authFile, content.CmdEventAuthFile, "", "", content.CmdFlagAzureAuthFileDescription)
 
 	return cmd
 }
 
 func (t *cloudCreateCmd) run() error {
+	cloud, err := t.create()
+	if err != nil {
+		return err
+	}
+
+	util.PrintResult(
+		t.out,
+		cloud,
+		[]string{"ID", "Name", "Tags"},
+		map[string]string{
+			"ID":   "Cloud Account ID",
+			"Name": "Cloud Account Name",
+			"Tags": "Tags",
+		},
+		jsonFormat,
+		verbose)
+
+	return nil
+}
+
+// create creates the role when a role name is given and adds the cloud account,
+// which is then re-validated until the role has propagated. The account and the
+// role are deleted again when the account can't be added or doesn't validate.
+func (t *cloudCreateCmd) create() (*client.CloudAccount, error) {
 	input := &client.CreateCloudAccountInput{
 		CloudName:      t.resourceName,
 		RoleName:       t.roleName,
@@ -138,42 +212,137 @@ func (t *cloudCreateCmd) run() error {
 		DirectoryID:    t.directoryID,
 		SubscriptionID: t.subscriptionID,
 		Tags:           t.tags,
+		ScanSettings:   t.scanSettings,
+
+		ProjectID:                t.projectID,
+		ServiceAccountEmail:      t.serviceAccountEmail,
+		WorkloadIdentityProvider: t.workloadIdentityProvider,
 	}
-	if t.roleName != "" {
-		info, err := t.client.GetRoleCreationInfo(input)
+	if len(t.tagList) > 0 {
+		var tags client.Tags
+		if t.tags != "" {
+			tags = strings.Split(t.tags, "|")
+		}
+		var err error
+		if input.TagList, err = applyTags(tags, t.tagList); err != nil {
+			return nil, err
+		}
+	}
+	if t.serviceAccountKey != "" {
+		email, key, err := gcp.ReadServiceAccountKey(t.serviceAccountKey)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		input.ServiceAccountKey = key
+		if input.ServiceAccountEmail == "" {
+			input.ServiceAccountEmail = email
 		}
-		arn, externalID, err := t.cloud.CreateNewRole(info)
-		time.Sleep(10 * time.Second)
+	}
+	// the created role and account are removed again when a later step fails
+	tx := &transaction{}
+	if t.roleName != "" {
+		info, err := t.client.GetRoleCreationInfo(rootCtx, input)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		info.InlinePolicy = t.inlinePolicy
+		if err := t.createRole(tx, input, info); err != nil {
+			return nil, tx.rollback(err)
 		}
+	}
 
-		input.RoleArn = arn
-		input.ExternalID = externalID
+	var cloud *client.CloudAccount
+	var err error
+	if t.roleName != "" && !t.isDraft {
+		cloud, err = createCloudAccount(t.client, input)
+	} else {
+		cloud, err = t.client.CreateCloudAccount(rootCtx, input)
 	}
+	if err != nil {
+		return nil, tx.rollback(err)
+	}
+	if t.roleName == "" || t.isDraft {
+		return cloud, nil
+	}
+	tx.record(fmt.Sprintf(content.RollbackResourceAccount, cloud.ID),
+		[]string{fmt.Sprintf(content.RecoveryDeleteAccount, cloud.ID)},
+		func(ctx context.Context) error {
+			return t.client.DeleteCloudAccountByID(ctx, cloud.ID)
+		})
 
-	cloud, err := t.client.CreateCloudAccount(input)
+	// the new role can only be assumed once IAM has propagated it
+	res, err := waitUntilValid(t.client, cloud.ID)
+	if err == nil && !res.IsValid {
+		err = fmt.Errorf(content.ErrorRoleNotValid, propagationBackoff.Timeout, res.Message)
+	}
 	if err != nil {
-		if t.roleName != "" {
-			fmt.Println("Cloud account creation failed! Will delete created role.")
-			t.cloud.DeleteRole(t.roleName)
-		}
-		return err
+		return nil, tx.rollback(err)
 	}
+	return cloud, nil
+}
 
-	util.PrintResult(
-		t.out,
-		cloud,
-		[]string{"ID", "Name", "Tags"},
-		map[string]string{
-			"ID":   "Cloud Account ID",
-			"Name": "Cloud Account Name",
-			"Tags": "Tags",
-		},
-		jsonFormat,
-		verbose)
+// recordRole records the role created for input in tx
+func (t *cloudCreateCmd) recordRole(tx *transaction, input *client.CreateCloudAccountInput) {
+	// application names aren't unique, so Azure deletes by the application ID
+	roleName := t.roleName
+	var resource string
+	var recovery []string
+	switch t.provider {
+	case "Azure":
+		roleName = input.ApplicationID
+		resource = fmt.Sprintf(content.DeleteResourceServicePrincipal, roleName)
+		recovery = []string{fmt.Sprintf(content.RecoveryAzureDeleteApplication, roleName)}
+	case "GCP":
+		resource = fmt.Sprintf(content.DeleteResourceServiceAccount, input.ServiceAccountEmail)
+		recovery = []string{fmt.Sprintf(content.RecoveryGCPDeleteServiceAccount, input.ServiceAccountEmail, t.projectID)}
+	default:
+		account, _, _ := parseRoleArn(input.RoleArn)
+		resource = fmt.Sprintf(content.DeleteResourceRole, roleName, account)
+		recovery = awsRoleRecovery(roleName, t.policy, t.inlinePolicy, t.awsProfile)
+	}
+	tx.record(resource, recovery, func(ctx context.Context) error {
+		return t.cloud.DeleteRole(ctx, roleName)
+	})
+}
 
-	return nil
+// createRole creates the role of info, sets it in input and records it in tx,
+// a role created before a later step failed is recorded too. GCP creates a
+// service account and Azure a service principal in place of the role.
+func (t *cloudCreateCmd) createRole(tx *transaction, input *client.CreateCloudAccountInput, info *client.RoleCreationInfo) error {
+	switch t.provider {
+	case "Azure":
+		creator, ok := t.cloud.(command.ServicePrincipalProvider)
+		if !ok {
+			return fmt.Errorf(content.ErrorServicePrincipalNotSupported)
+		}
+		sp, err := creator.CreateServicePrincipal(rootCtx, info)
+		if sp != nil {
+			input.ApplicationID = sp.ApplicationID
+			input.DirectoryID = sp.DirectoryID
+			input.KeyValue = sp.KeyValue
+			t.recordRole(tx, input)
+		}
+		return err
+	case "GCP":
+		creator, ok := t.cloud.(command.ServiceAccountProvider)
+		if !ok {
+			return fmt.Errorf(content.ErrorServiceAccountNotSupported)
+		}
+		// the new service account and its key take the place of the role
+		account, err := creator.CreateServiceAccount(rootCtx, info)
+		if account != nil {
+			input.ServiceAccountEmail = account.Email
+			input.ServiceAccountKey = account.Key
+			t.recordRole(tx, input)
+		}
+		return err
+	default:
+		arn, externalID, err := t.cloud.CreateNewRole(rootCtx, info)
+		if arn != "" {
+			input.RoleArn = arn
+			input.ExternalID = externalID
+			t.recordRole(tx, input)
+		}
+		return err
+	}
 }
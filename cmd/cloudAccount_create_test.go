@@ -22,6 +22,7 @@ import (
 	"github.com/CloudCoreo/cli/client"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCloudAccountCreateCmd(t *testing.T) {
@@ -60,6 +61,31 @@ func TestCloudAccountCreateCmd(t *testing.T) {
 				`      CloudName1                 ID1                \[\]   \n` +
 				"---------------------  -----------------------  ---------\n\n",
 		},
+		{
+			cmds: "coreo cloud create",
+			desc: "create GCP cloud command with workload identity",
+			flags: []string{
+				"--provider", "GCP",
+				"--name", "CloudName",
+				"--project-id", "project-1",
+				"--service-account-email", "vss@project-1.iam.gserviceaccount.com",
+				"--workload-identity-provider", "projects/1/locations/global/workloadIdentityPools/vss/providers/aws",
+			},
+			resp: []*client.CloudAccount{
+				mockCloudAccount("ID1", "CloudName1"),
+			},
+			xout: `CloudName1 +ID1`,
+		},
+		{
+			cmds: "coreo cloud create",
+			desc: "create GCP cloud command without project",
+			flags: []string{
+				"--provider", "GCP",
+				"--name", "CloudName",
+				"--role", "vss",
+			},
+			err: true,
+		},
 		{
 			cmds:  "coreo cloud create",
 			desc:  "create cloud command with missing flags",
@@ -95,3 +121,74 @@ func TestCloudAccountCreateCmd(t *testing.T) {
 		buf.Reset()
 	}
 }
+
+func TestCloudAccountCreateAzureServicePrincipal(t *testing.T) {
+	propagationBackoff = backoff{}
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+
+	sp := &client.ServicePrincipal{ApplicationID: "app-1", DirectoryID: "tenant-1", KeyValue: "secret-1"}
+	frc := &fakeReleaseClient{validationResult: client.RoleReValidationResult{IsValid: true}}
+	cloud := &fakeServicePrincipalProvider{sp: sp}
+	create := &cloudCreateCmd{
+		client:         frc,
+		cloud:          cloud,
+		resourceName:   "CloudName",
+		roleName:       "vss",
+		provider:       "Azure",
+		subscriptionID: "sub-1",
+	}
+
+	_, err := create.create()
+	assert.Nil(t, err)
+	assert.Equal(t, "app-1", frc.created.ApplicationID)
+	assert.Equal(t, "tenant-1", frc.created.DirectoryID)
+	assert.Equal(t, "secret-1", frc.created.KeyValue)
+	assert.Empty(t, cloud.deleted)
+
+	// the service principal isn't created for providers that can't
+	create.cloud = &fakeCloudProvider{}
+	_, err = create.create()
+	assert.NotNil(t, err)
+}
+
+func TestCloudAccountCreateGCPServiceAccount(t *testing.T) {
+	propagationBackoff = backoff{}
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+
+	account := &client.ServiceAccount{Email: "vss@project-1.iam.gserviceaccount.com", Key: "key-1"}
+	frc := &fakeReleaseClient{validationResult: client.RoleReValidationResult{IsValid: true}}
+	cloud := &fakeServiceAccountProvider{account: account}
+	create := &cloudCreateCmd{client: frc, cloud: cloud, resourceName: "CloudName", roleName: "vss", provider: "GCP", projectID: "project-1"}
+
+	_, err := create.create()
+	assert.Nil(t, err)
+	assert.Equal(t, "vss@project-1.iam.gserviceaccount.com", frc.created.ServiceAccountEmail)
+	assert.Equal(t, "key-1", frc.created.ServiceAccountKey)
+	assert.Empty(t, cloud.deleted)
+
+	// a service account whose key couldn't be created is deleted again
+	cloud.createErr = errors.New("Create key failed")
+	frc.created = nil
+	_, err = create.create()
+	assert.EqualError(t, err, "Create key failed")
+	assert.Nil(t, frc.created)
+	assert.Equal(t, []string{"vss"}, cloud.deleted)
+}
+
+func TestCloudAccountCreateInlinePolicy(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{}
+
+	catalog := client.PolicyCatalog{"ec2": {"DescribeInstances"}}
+	frc := &fakeReleaseClient{info: client.RoleCreationInfo{Catalog: catalog}, validationResult: client.RoleReValidationResult{IsValid: true}}
+	provider := &fakeCloudProvider{arn: "arn:aws:iam::123456789012:role/vss", externalID: "id"}
+	create := &cloudCreateCmd{out: &bytes.Buffer{}, client: frc, cloud: provider, roleName: "vss", inlinePolicy: true, provider: "AWS"}
+	_, err := create.create()
+	assert.Nil(t, err)
+	assert.True(t, provider.roleInfo.InlinePolicy)
+	assert.Equal(t, catalog, provider.roleInfo.Catalog)
+
+	cmd := newCloudCreateCmd(frc, &bytes.Buffer{})
+	assert.Nil(t, cmd.ParseFlags([]string{"--provider", "Azure", "--name", "azure", "--role", "vss", "--subscription-id", "sub-1", "--inline-policy"}))
+	assert.EqualError(t, cmd.RunE(cmd, nil), "Inline policies are only supported for AWS roles, not for Azure cloud accounts")
+}
@@ -15,10 +15,15 @@
 package main
 
 import (
+	"bufio"
 	"io"
+	"os"
 	"strings"
 
+	"github.com/CloudCoreo/cli/client"
 	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/azure"
+	"github.com/CloudCoreo/cli/pkg/gcp"
 
 	"github.com/CloudCoreo/cli/pkg/command"
 
@@ -26,30 +31,38 @@ import (
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/cmd/util"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
 type cloudDeleteCmd struct {
 	out            io.Writer
+	in             io.Reader
 	client         command.Interface
 	cloud          command.CloudProvider
 	cloudID        string
 	deleteRole     bool
+	purge          bool
+	yes            bool
+	dryRun         bool
 	awsProfile     string
 	awsProfilePath string
+	session        awsSession
+	gcpCredentials string
+	authFile       string
 }
 
 func newCloudDeleteCmd(client command.Interface, out io.Writer) *cobra.Command {
 	cloudDelete := &cloudDeleteCmd{
 		out:    out,
+		in:     os.Stdin,
 		client: client,
 	}
 
 	cmd := &cobra.Command{
-		Use:   content.CmdDeleteUse,
-		Short: content.CmdCloudDeleteShort,
-		Long:  content.CmdCloudDeleteLong,
+		Use:     content.CmdDeleteUse,
+		Short:   content.CmdCloudDeleteShort,
+		Long:    content.CmdCloudDeleteLong,
+		Example: content.CmdCloudDeleteExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if err := util.CheckCloudShowOrDeleteFlag(cloudDelete.cloudID, verbose); err != nil {
@@ -57,17 +70,7 @@ func newCloudDeleteCmd(client command.Interface, out io.Writer) *cobra.Command {
 			}
 
 			if cloudDelete.client == nil {
-				cloudDelete.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
-			}
-
-			if cloudDelete.deleteRole && (cloudDelete.cloud == nil) {
-				newServiceInput := &aws.NewServiceInput{
-					AwsProfile:     cloudDelete.awsProfile,
-					AwsProfilePath: cloudDelete.awsProfilePath,
-				}
-				cloudDelete.cloud = aws.NewService(newServiceInput)
+				cloudDelete.client = newCoreoClient()
 			}
 
 			return cloudDelete.run()
@@ -78,32 +81,236 @@ func newCloudDeleteCmd(client command.Interface, out io.Writer) *cobra.Command {
 
 	f.StringVarP(&cloudDelete.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
 	f.BoolVarP(&cloudDelete.deleteRole, content.CmdFlagDeleteRole, "", false, content.CmdFLagDeleteRoleDescription)
+	f.BoolVarP(&cloudDelete.purge, content.CmdFlagPurge, "", false, content.CmdFlagPurgeDescription)
+	f.BoolVarP(&cloudDelete.yes, content.CmdFlagYes, "", false, content.CmdFlagYesDescription)
+	f.BoolVarP(&cloudDelete.dryRun, content.CmdFlagDryRun, "", false, content.CmdFlagDeleteDryRunDescription)
 	f.StringVarP(&cloudDelete.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
 	f.StringVarP(&cloudDelete.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	cloudDelete.session.addFlags(cmd)
+	f.StringVarP(&cloudDelete.gcpCredentials, content.CmdFlagGCPCredentials, "", "", content.CmdFlagGCPCredentialsDescription)
+	f.StringVarP(&cloudDelete.authFile, content.CmdEventAuthFile, "", "", content.CmdFlagAzureAuthFileDescription)
 
 	return cmd
 }
 
+// deleteStep is a step of deleting a cloud account, it removes the listed cloud resources
+type deleteStep struct {
+	Step      string `json:"step"`
+	Resources string `json:"resources"`
+	Status    string `json:"status,omitempty"`
+	Message   string `json:"message,omitempty"`
+
+	run func() error
+}
+
 func (t *cloudDeleteCmd) run() error {
-	var roleName string
-	if t.deleteRole {
-		cloud, err := t.client.ShowCloudAccountByID(t.cloudID)
-		if err != nil {
+	steps, err := t.plan()
+	if err != nil {
+		return err
+	}
+
+	if t.dryRun {
+		t.printSteps(steps)
+		return nil
+	}
+	if t.purge && !t.yes {
+		t.printSteps(steps)
+		if !t.confirm() {
+			return fmt.Errorf(content.ErrorDeleteAborted)
+		}
+	}
+	if len(steps) == 1 {
+		// only the account is deleted, as before --purge
+		if err := steps[0].run(); err != nil {
 			return err
 		}
+		fmt.Fprintln(t.out, content.InfoCloudAccountDeleted)
+		return nil
+	}
+
+	var failed *deleteStep
+	for _, step := range steps {
+		if failed != nil {
+			step.Status = content.DeleteStatusSkipped
+			continue
+		}
+		if err := step.run(); err != nil {
+			step.Status, step.Message = content.DeleteStatusFailed, err.Error()
+			failed = step
+			continue
+		}
+		step.Status = content.DeleteStatusDone
+	}
+	t.printSteps(steps)
 
-		roleNames := strings.Split(cloud.Arn, "/")
-		roleName = roleNames[len(roleNames)-1]
+	if failed != nil {
+		return &util.ReportedError{Message: fmt.Sprintf(content.ErrorDeleteStepFailed, failed.Step)}
+	}
+	return nil
+}
 
-		t.cloud.DeleteRole(roleName)
+// plan returns the steps deleting the cloud account. With --purge its event
+// stream is removed first, then its role, and the account itself last, so
+// that a failed step can be retried.
+func (t *cloudDeleteCmd) plan() ([]*deleteStep, error) {
+	account := &deleteStep{
+		Step:      content.DeleteStepCloudAccount,
+		Resources: t.cloudID,
+		run: func() error {
+			return t.client.DeleteCloudAccountByID(rootCtx, t.cloudID)
+		},
+	}
+	if !t.purge && !t.deleteRole && !t.dryRun {
+		return []*deleteStep{account}, nil
 	}
 
-	err := t.client.DeleteCloudAccountByID(t.cloudID)
+	cloud, err := t.client.ShowCloudAccountByID(rootCtx, t.cloudID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if cloud.Name != "" {
+		account.Resources = fmt.Sprintf("%s (%s)", t.cloudID, cloud.Name)
+	}
+	if t.cloud == nil && (t.purge || t.deleteRole) {
+		t.cloud = t.newProvider(cloud)
+	}
+
+	var steps []*deleteStep
+	if t.purge {
+		config, err := t.client.GetEventRemoveConfig(rootCtx, t.cloudID)
+		if err != nil {
+			return nil, err
+		}
+		if config.Provider == "" {
+			config.Provider = cloud.Provider
+		}
+		if config.Provider == "AWS" && len(config.Regions) == 0 {
+			return nil, fmt.Errorf(content.ErrorNoEventRegions)
+		}
+		steps = append(steps, &deleteStep{
+			Step:      content.DeleteStepEventStream,
+			Resources: strings.Join(eventStreamResources(config), ", "),
+			run: func() error {
+				return t.cloud.RemoveEventStream(rootCtx, config)
+			},
+		})
+	}
+	if t.purge || t.deleteRole {
+		roleName, resource, err := cloudRole(cloud)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, &deleteStep{
+			Step:      content.DeleteStepRole,
+			Resources: resource,
+			run: func() error {
+				return t.cloud.DeleteRole(rootCtx, roleName)
+			},
+		})
 	}
+	return append(steps, account), nil
+}
 
-	fmt.Fprintln(t.out, content.InfoCloudAccountDeleted)
+// printSteps prints the steps with the resources they remove, and their status once run
+func (t *cloudDeleteCmd) printSteps(steps []*deleteStep) {
+	headers := []string{"Step", "Resources"}
+	if steps[0].Status != "" {
+		headers = append(headers, "Status", "Message")
+	}
+	rows := make([]interface{}, len(steps))
+	for i, step := range steps {
+		rows[i] = step
+	}
+	util.PrintResult(
+		t.out,
+		rows,
+		headers,
+		map[string]string{
+			"Step":      "Step",
+			"Resources": "Resources",
+			"Status":    "Status",
+			"Message":   "Message",
+		},
+		jsonFormat,
+		verbose)
+}
 
-	return nil
+// confirm asks whether to delete the listed resources
+func (t *cloudDeleteCmd) confirm() bool {
+	fmt.Fprint(t.out, content.InfoDeleteConfirm)
+	answer, _ := bufio.NewReader(t.in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// cloudRole returns the name DeleteRole takes for the role of the cloud account
+// and a description of the removed resource. GCP deletes the service account
+// and Azure the application of the service principal in place of the role.
+func cloudRole(cloud *client.CloudAccount) (roleName, resource string, err error) {
+	switch cloud.Provider {
+	case "GCP":
+		return cloud.ServiceAccountEmail, fmt.Sprintf(content.DeleteResourceServiceAccount, cloud.ServiceAccountEmail), nil
+	case "Azure":
+		return cloud.ApplicationID, fmt.Sprintf(content.DeleteResourceServicePrincipal, cloud.ApplicationID), nil
+	}
+	account, roleName, err := parseRoleArn(cloud.Arn)
+	if err != nil {
+		return "", "", err
+	}
+	return roleName, fmt.Sprintf(content.DeleteResourceRole, roleName, account), nil
+}
+
+// parseRoleArn returns the AWS account and the name of the role of an IAM role
+// arn, arn:PARTITION:iam::ACCOUNT:role/PATH/NAME
+func parseRoleArn(arn string) (account, roleName string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || !strings.HasPrefix(parts[5], "role/") {
+		return "", "", fmt.Errorf(content.ErrorRoleArn, arn)
+	}
+	path := strings.Split(parts[5], "/")
+	return parts[4], path[len(path)-1], nil
+}
+
+// eventStreamResources describes the resources RemoveEventStream removes
+func eventStreamResources(config *client.EventRemoveConfig) []string {
+	var resources []string
+	switch config.Provider {
+	case "AWS":
+		for _, region := range config.Regions {
+			resources = append(resources, fmt.Sprintf(content.DeleteResourceStack, config.StackName, region))
+		}
+	case "Azure":
+		resources = append(resources, fmt.Sprintf(content.DeleteResourceGroup, config.ResourceGroup, config.SubscriptionID))
+	case "GCP":
+		project := "projects/" + config.ProjectID
+		if config.PubSubSubscription != "" {
+			resources = append(resources, fmt.Sprintf(content.DeleteResourceSubscription, project, config.PubSubSubscription))
+		}
+		if config.SinkName != "" {
+			resources = append(resources, fmt.Sprintf(content.DeleteResourceSink, project, config.SinkName))
+		}
+		if config.PubSubTopic != "" {
+			resources = append(resources, fmt.Sprintf(content.DeleteResourceTopic, project, config.PubSubTopic))
+		}
+	}
+	return resources
+}
+
+// newProvider returns the service removing the event stream and the role of the cloud account
+func (t *cloudDeleteCmd) newProvider(cloud *client.CloudAccount) command.CloudProvider {
+	if cloud.Provider == "GCP" {
+		return gcp.NewService(&gcp.NewServiceInput{
+			ProjectID:       cloud.ProjectID,
+			CredentialsFile: t.gcpCredentials,
+			HTTPClient:      httpClient,
+		})
+	}
+	if cloud.Provider == "Azure" {
+		return azure.NewService(&azure.NewServiceInput{
+			AuthFile:       t.authFile,
+			SubscriptionID: cloud.SubscriptionID,
+			HTTPClient:     httpClient,
+		})
+	}
+	return aws.NewService(t.session.serviceInput(t.awsProfile, t.awsProfilePath))
 }
@@ -17,9 +17,11 @@ package main
 import (
 	"bytes"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/pkg/errors"
 )
@@ -84,3 +86,90 @@ func TestCloudAccountDeleteCmd(t *testing.T) {
 		buf.Reset()
 	}
 }
+
+func purgeTestAccount() *client.CloudAccount {
+	return &client.CloudAccount{
+		ID:        "cloud-1",
+		CloudInfo: client.CloudInfo{Name: "prod", Provider: "AWS", Arn: "arn:aws:iam::123456789012:role/security/vss-role"},
+	}
+}
+
+func TestCloudAccountDeletePurge(t *testing.T) {
+	tests := []struct {
+		desc    string
+		yes     bool
+		dryRun  bool
+		input   string
+		err     bool
+		removed bool
+	}{
+		{"confirmed", false, false, "y\n", false, true},
+		{"aborted", false, false, "n\n", true, false},
+		{"no answer", false, false, "", true, false},
+		{"yes", true, false, "", false, true},
+		{"dry run", false, true, "", false, false},
+	}
+
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: []*client.CloudAccount{purgeTestAccount()}, regions: []string{"us-east-1", "us-west-2"}}
+		provider := &fakeCloudProvider{}
+		var buf bytes.Buffer
+		del := &cloudDeleteCmd{
+			out:     &buf,
+			in:      strings.NewReader(tt.input),
+			client:  frc,
+			cloud:   provider,
+			cloudID: "cloud-1",
+			purge:   true,
+			yes:     tt.yes,
+			dryRun:  tt.dryRun,
+		}
+
+		err := del.run()
+		assert.Equal(t, tt.err, err != nil, tt.desc)
+		assert.Contains(t, buf.String(), "IAM role vss-role in AWS account 123456789012", tt.desc)
+		assert.Contains(t, buf.String(), "in us-west-2", tt.desc)
+		assert.Contains(t, buf.String(), "cloud-1 (prod)", tt.desc)
+		if tt.removed {
+			assert.NotNil(t, provider.removed, tt.desc)
+			assert.Equal(t, []string{"vss-role"}, provider.deleted, tt.desc)
+			assert.Equal(t, []string{"cloud-1"}, frc.deleted, tt.desc)
+		} else {
+			assert.Nil(t, provider.removed, tt.desc)
+			assert.Empty(t, provider.deleted, tt.desc)
+			assert.Empty(t, frc.deleted, tt.desc)
+		}
+	}
+}
+
+func TestCloudAccountDeletePurgeFailure(t *testing.T) {
+	frc := &fakeReleaseClient{cloudAccounts: []*client.CloudAccount{purgeTestAccount()}, regions: []string{"us-east-1"}}
+	var buf bytes.Buffer
+	del := &cloudDeleteCmd{out: &buf, client: frc, cloud: &fakeCloudProvider{err: errors.New("Access denied")}, cloudID: "cloud-1", purge: true, yes: true}
+
+	assert.EqualError(t, del.run(), `Step "Remove event stream" failed, the following steps were skipped`)
+	assert.Contains(t, buf.String(), "Access denied")
+	assert.Contains(t, buf.String(), "Skipped")
+	assert.Empty(t, frc.deleted)
+}
+
+func TestCloudRole(t *testing.T) {
+	tests := []struct {
+		cloud    client.CloudInfo
+		roleName string
+		err      bool
+	}{
+		{client.CloudInfo{Arn: "arn:aws:iam::123456789012:role/vss"}, "vss", false},
+		{client.CloudInfo{Provider: "AWS", Arn: "arn:aws-us-gov:iam::123456789012:role/a/b/vss"}, "vss", false},
+		{client.CloudInfo{Provider: "AWS", Arn: "arn:aws:iam::123456789012:user/vss"}, "", true},
+		{client.CloudInfo{Provider: "AWS"}, "", true},
+		{client.CloudInfo{Provider: "Azure", ApplicationID: "app-1"}, "app-1", false},
+		{client.CloudInfo{Provider: "GCP", ServiceAccountEmail: "vss@p.iam.gserviceaccount.com"}, "vss@p.iam.gserviceaccount.com", false},
+	}
+
+	for _, tt := range tests {
+		roleName, _, err := cloudRole(&client.CloudAccount{CloudInfo: tt.cloud})
+		assert.Equal(t, tt.err, err != nil, tt.cloud.Arn)
+		assert.Equal(t, tt.roleName, roleName, tt.cloud.Arn)
+	}
+}
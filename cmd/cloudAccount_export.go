@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/CloudCoreo/cli/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+type cloudExportCmd struct {
+	out    io.Writer
+	client command.Interface
+	output string
+	format string
+}
+
+func newCloudExportCmd(client command.Interface, out io.Writer) *cobra.Command {
+	cloudExport := &cloudExportCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdExportUse,
+		Short:   content.CmdCloudExportShort,
+		Long:    content.CmdCloudExportLong,
+		Example: content.CmdCloudExportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonFormat && !cmd.Flags().Changed(content.CmdFlagFormat) {
+				cloudExport.format = inventory.FormatJSON
+			}
+			if cloudExport.format != inventory.FormatYAML && cloudExport.format != inventory.FormatJSON {
+				return fmt.Errorf(content.ErrorExportFormat, cloudExport.format)
+			}
+			if cloudExport.client == nil {
+				cloudExport.client = newCoreoClient()
+			}
+
+			return cloudExport.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cloudExport.output, content.CmdFlagOutputLong, content.CmdFlagOutputShort, "", content.CmdFlagOutputDescription)
+	f.StringVarP(&cloudExport.format, content.CmdFlagFormat, "", inventory.FormatYAML, content.CmdFlagFormatDescription)
+
+	return cmd
+}
+
+func (t *cloudExportCmd) run() error {
+	clouds, err := t.client.ListCloudAccounts(rootCtx, client.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	accounts := make([]inventory.Account, len(clouds))
+	for i, cloud := range clouds {
+		accounts[i] = inventory.FromCloudAccount(cloud)
+	}
+
+	b, err := inventory.Marshal(accounts, t.format)
+	if err != nil {
+		return err
+	}
+
+	if t.output == "" {
+		_, err = t.out.Write(b)
+		return err
+	}
+	// role external IDs are kept, so the file is only readable by its owner
+	if err := ioutil.WriteFile(t.output, b, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(t.out, content.InfoExported, len(accounts), t.output)
+	return nil
+}
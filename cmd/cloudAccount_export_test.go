@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/pkg/errors"
+)
+
+func TestCloudAccountExportCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vss-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "inventory.yaml")
+
+	accounts := []*client.CloudAccount{
+		{ID: "id1", AccountID: "111111111111", CloudInfo: client.CloudInfo{Name: "prod", Provider: "AWS", Arn: "arn:aws:iam::111111111111:role/vss", ExternalID: "ext", IsValid: true}},
+		{ID: "id2", AccountID: "sub1", CloudInfo: client.CloudInfo{Name: "azure", Provider: "Azure", KeyValue: "secret-key", SubscriptionID: "sub1"}},
+	}
+
+	tests := []struct {
+		desc  string
+		flags []string
+		err   bool
+		xout  string
+	}{
+		{
+			desc: "export to the standard output",
+			xout: `^# Azure keys[^\n]*\nversion: v1\naccounts:\n- name: prod\n  provider: AWS\n  accountId: "111111111111"\n[\s\S]*- name: azure\n  provider: Azure\n  scanEnabled: false\n  subscriptionId: sub1\n$`,
+		},
+		{
+			desc:  "export json",
+			flags: []string{"--format", "json"},
+			xout:  `^{\n  "version": "v1",\n  "accounts": \[\n    {\n      "name": "prod"`,
+		},
+		{
+			desc:  "export to a file",
+			flags: []string{"-o", output},
+			xout:  "Exported 2 cloud accounts to " + regexp.QuoteMeta(output),
+		},
+		{
+			desc:  "export with an unsupported format",
+			flags: []string{"--format", "csv"},
+			err:   true,
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: accounts}
+
+		cmd := newCloudExportCmd(frc, &buf)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, nil)
+
+		if (err != nil) != tt.err {
+			t.Errorf("%q. expected error %v, got '%v'", tt.desc, tt.err, err)
+		}
+		if regexp.MustCompile("secret-key|isValid|_id").Match(buf.Bytes()) {
+			t.Errorf("%q. server fields or secrets exported:\n%s", tt.desc, buf.String())
+		}
+
+		re := regexp.MustCompile(tt.xout)
+		if !re.Match(buf.Bytes()) {
+			t.Fatalf("%q:\nexpected\n\t%q\nactual\n\t%q", tt.desc, tt.xout, buf.String())
+		}
+		buf.Reset()
+	}
+
+	// the exported file plans no changes against the accounts it was exported from
+	cmd := newPlanCmd(&fakeReleaseClient{cloudAccounts: accounts}, &buf)
+	cmd.ParseFlags([]string{"-f", output, "--prune"})
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile("No changes").Match(buf.Bytes()) {
+		t.Fatalf("expected no changes, got %q", buf.String())
+	}
+
+	frc := &fakeReleaseClient{err: errors.New("Error")}
+	cmd = newCloudExportCmd(frc, &buf)
+	if err := cmd.RunE(cmd, nil); err == nil {
+		t.Error("expected the list error")
+	}
+}
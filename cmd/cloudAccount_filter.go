@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/spf13/cobra"
+)
+
+// cloudAccountFilter selects cloud accounts by the filter flags of list commands
+type cloudAccountFilter struct {
+	providers    []string
+	environments []string
+	tags         []string
+	valid        bool
+	invalid      bool
+	draft        bool
+	name         string
+	nameRegex    string
+
+	// draftSet tells whether --draft was given, --draft=false selects the accounts that aren't drafts
+	draftSet  bool
+	nameMatch *regexp.Regexp
+}
+
+// addFlags adds the filter flags to cmd
+func (f *cloudAccountFilter) addFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVar(&f.providers, content.CmdFlagProvider, nil, content.CmdFlagListProviderDescription)
+	flags.StringSliceVar(&f.environments, content.CmdFlagEnvironmentLong, nil, content.CmdFlagListEnvironmentDescription)
+	flags.StringArrayVar(&f.tags, content.CmdFlagTagLong, nil, content.CmdFlagListTagDescription)
+	flags.BoolVar(&f.valid, content.CmdFlagValidLong, false, content.CmdFlagValidDescription)
+	flags.BoolVar(&f.invalid, content.CmdFlagInvalidLong, false, content.CmdFlagInvalidDescription)
+	flags.BoolVar(&f.draft, content.CmdFlagIsDraft, false, content.CmdFlagListDraftDescription)
+	flags.StringVar(&f.name, content.CmdFlagNameLong, "", content.CmdFlagListNameDescription)
+	flags.StringVar(&f.nameRegex, content.CmdFlagNameRegexLong, "", content.CmdFlagNameRegexDescription)
+}
+
+// parse validates the flags of cmd, it is called before the filter is used
+func (f *cloudAccountFilter) parse(cmd *cobra.Command) error {
+	if f.valid && f.invalid {
+		return fmt.Errorf(content.ErrorValidAndInvalid)
+	}
+	f.draftSet = cmd.Flags().Changed(content.CmdFlagIsDraft)
+	if f.nameRegex != "" {
+		re, err := regexp.Compile(f.nameRegex)
+		if err != nil {
+			return fmt.Errorf(content.ErrorNameRegex, err)
+		}
+		f.nameMatch = re
+	}
+	return nil
+}
+
+// isSet tells whether any filter is given
+func (f *cloudAccountFilter) isSet() bool {
+	return len(f.providers) > 0 || len(f.environments) > 0 || len(f.tags) > 0 || f.valid || f.invalid ||
+		f.draftSet || f.name != "" || f.nameMatch != nil
+}
+
+// match tells whether the cloud account passes all filters
+func (f *cloudAccountFilter) match(cloud *client.CloudAccount) bool {
+	if len(f.providers) > 0 && !containsFold(f.providers, cloud.Provider) {
+		return false
+	}
+	if len(f.environments) > 0 && !containsFold(f.environments, cloud.Environment) {
+		return false
+	}
+	for _, tag := range f.tags {
+		if !cloud.Tags.Has(tag) {
+			return false
+		}
+	}
+	if (f.valid && !cloud.IsValid) || (f.invalid && cloud.IsValid) {
+		return false
+	}
+	if f.draftSet && cloud.IsDraft != f.draft {
+		return false
+	}
+	if f.name != "" && !strings.Contains(strings.ToLower(cloud.Name), strings.ToLower(f.name)) {
+		return false
+	}
+	if f.nameMatch != nil && !f.nameMatch.MatchString(cloud.Name) {
+		return false
+	}
+	return true
+}
+
+// apply returns the cloud accounts passing all filters
+func (f *cloudAccountFilter) apply(clouds []*client.CloudAccount) []*client.CloudAccount {
+	var matched []*client.CloudAccount
+	for _, cloud := range clouds {
+		if f.match(cloud) {
+			matched = append(matched, cloud)
+		}
+	}
+	return matched
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// cloudAccountField is a field of client.CloudAccount, including those of the embedded CloudInfo
+type cloudAccountField struct {
+	name     string
+	jsonName string
+	index    []int
+}
+
+// secretCloudAccountFields can't be selected, sorted by or printed as columns
+var secretCloudAccountFields = map[string]bool{
+	"KeyValue":          true,
+	"ServiceAccountKey": true,
+}
+
+// cloudAccountFields lists the fields of client.CloudAccount in declaration order, without secrets
+func cloudAccountFields() []cloudAccountField {
+	var fields []cloudAccountField
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldIndex := append(append([]int{}, index...), i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				walk(field.Type, fieldIndex)
+				continue
+			}
+			if secretCloudAccountFields[field.Name] {
+				continue
+			}
+			jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+			if jsonName == "" {
+				jsonName = field.Name
+			}
+			fields = append(fields, cloudAccountField{name: field.Name, jsonName: jsonName, index: fieldIndex})
+		}
+	}
+	walk(reflect.TypeOf(client.CloudAccount{}), nil)
+	return fields
+}
+
+// lookupCloudAccountField returns the field named by its Go or JSON name, case is ignored
+func lookupCloudAccountField(name string) (cloudAccountField, error) {
+	var names []string
+	for _, field := range cloudAccountFields() {
+		if strings.EqualFold(field.name, name) || strings.EqualFold(field.jsonName, name) {
+			return field, nil
+		}
+		names = append(names, field.name)
+	}
+	return cloudAccountField{}, fmt.Errorf(content.ErrorUnknownField, name, strings.Join(names, ", "))
+}
+
+func (f cloudAccountField) value(cloud *client.CloudAccount) reflect.Value {
+	return reflect.ValueOf(cloud).Elem().FieldByIndex(f.index)
+}
+
+// sortCloudAccounts sorts the cloud accounts by the field, a leading - sorts in descending order
+func sortCloudAccounts(clouds []*client.CloudAccount, by string) error {
+	descending := strings.HasPrefix(by, "-")
+	field, err := lookupCloudAccountField(strings.TrimPrefix(by, "-"))
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(clouds, func(i, j int) bool {
+		a, b := field.value(clouds[i]), field.value(clouds[j])
+		if descending {
+			a, b = b, a
+		}
+		return lessValue(a, b)
+	})
+	return nil
+}
+
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.String:
+		return strings.ToLower(a.String()) < strings.ToLower(b.String())
+	default:
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+}
+
+// selectColumns returns the fields named by columns, comma separated Go or JSON names
+func selectColumns(columns string) ([]cloudAccountField, error) {
+	var fields []cloudAccountField
+	for _, name := range strings.Split(columns, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		field, err := lookupCloudAccountField(name)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// projectCloudAccount returns the fields of the cloud account keyed by their JSON name
+func projectCloudAccount(cloud *client.CloudAccount, fields []cloudAccountField) map[string]interface{} {
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		projected[field.jsonName] = field.value(cloud).Interface()
+	}
+	return projected
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func filterTestAccounts() []*client.CloudAccount {
+	return []*client.CloudAccount{
+		{ID: "1", CloudInfo: client.CloudInfo{Name: "prod-web", Provider: "AWS", Environment: "Production", IsValid: true, Tags: []string{"team:web", "owner:alice"}, LastValidationCheck: "2020-01-03"}},
+		{ID: "2", CloudInfo: client.CloudInfo{Name: "prod-data", Provider: "Azure", Environment: "Production", IsValid: false, Tags: []string{"team:data"}, LastValidationCheck: "2020-01-01"}},
+		{ID: "3", CloudInfo: client.CloudInfo{Name: "Staging", Provider: "GCP", Environment: "Staging", IsDraft: true, ScanInterval: "Daily", LastValidationCheck: "2020-01-02"}},
+	}
+}
+
+func TestCloudAccountFilter(t *testing.T) {
+	tests := []struct {
+		desc  string
+		flags []string
+		ids   []string
+	}{
+		{"no filter", nil, []string{"1", "2", "3"}},
+		{"provider", []string{"--provider", "aws,GCP"}, []string{"1", "3"}},
+		{"environment", []string{"--environment", "Production"}, []string{"1", "2"}},
+		{"tag key", []string{"--tag", "team"}, []string{"1", "2"}},
+		{"tag key and value", []string{"--tag", "team=web", "--tag", "owner"}, []string{"1"}},
+		{"valid", []string{"--valid"}, []string{"1"}},
+		{"invalid", []string{"--invalid"}, []string{"2", "3"}},
+		{"draft", []string{"--draft"}, []string{"3"}},
+		{"not draft", []string{"--draft=false"}, []string{"1", "2"}},
+		{"name", []string{"--name", "PROD"}, []string{"1", "2"}},
+		{"name regex", []string{"--name-regex", "^prod-d"}, []string{"2"}},
+	}
+
+	for _, tt := range tests {
+		cmd := &cobra.Command{}
+		filter := &cloudAccountFilter{}
+		filter.addFlags(cmd)
+		assert.Nil(t, cmd.ParseFlags(tt.flags), tt.desc)
+		assert.Nil(t, filter.parse(cmd), tt.desc)
+
+		var ids []string
+		for _, cloud := range filter.apply(filterTestAccounts()) {
+			ids = append(ids, cloud.ID)
+		}
+		assert.Equal(t, tt.ids, ids, tt.desc)
+	}
+}
+
+func TestCloudAccountFilterErrors(t *testing.T) {
+	for _, flags := range [][]string{{"--valid", "--invalid"}, {"--name-regex", "("}} {
+		cmd := newCloudListCmd(&fakeReleaseClient{}, &bytes.Buffer{})
+		assert.Nil(t, cmd.ParseFlags(flags))
+		assert.NotNil(t, cmd.RunE(cmd, nil), flags)
+	}
+}
+
+func TestSortCloudAccounts(t *testing.T) {
+	clouds := filterTestAccounts()
+	assert.Nil(t, sortCloudAccounts(clouds, "lastValidationCheck"))
+	assert.Equal(t, []string{"2", "3", "1"}, []string{clouds[0].ID, clouds[1].ID, clouds[2].ID})
+
+	assert.Nil(t, sortCloudAccounts(clouds, "-Name"))
+	assert.Equal(t, []string{"3", "1", "2"}, []string{clouds[0].ID, clouds[1].ID, clouds[2].ID})
+
+	assert.Nil(t, sortCloudAccounts(clouds, "IsValid"))
+	assert.Equal(t, "1", clouds[2].ID)
+
+	assert.NotNil(t, sortCloudAccounts(clouds, "Unknown"))
+}
+
+func TestSelectColumns(t *testing.T) {
+	fields, err := selectColumns("Name, scanInterval,_id")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":         "Staging",
+		"scanInterval": "Daily",
+		"_id":          "3",
+	}, projectCloudAccount(filterTestAccounts()[2], fields))
+
+	_, err = selectColumns("Name,Color")
+	assert.NotNil(t, err)
+
+	for _, secret := range []string{"key", "KeyValue", "serviceAccountKey"} {
+		_, err = selectColumns("Name," + secret)
+		assert.NotNil(t, err, secret)
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/azure"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/CloudCoreo/cli/pkg/gcp"
+	"github.com/CloudCoreo/cli/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+const defaultAzureRegion = "eastus"
+
+type cloudImportCmd struct {
+	out                 io.Writer
+	client              command.Interface
+	cloud               command.CloudProvider
+	file                string
+	setupEvents         bool
+	ignoreMissingTrails bool
+}
+
+// importResult is the outcome of importing one account of the file
+type importResult struct {
+	Row     int    `json:"row"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	CloudID string `json:"cloudId,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func newCloudImportCmd(client command.Interface, provider command.CloudProvider, out io.Writer) *cobra.Command {
+	cloudImport := &cloudImportCmd{
+		out:    out,
+		client: client,
+		cloud:  provider,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdImportUse,
+		Short:   content.CmdCloudImportShort,
+		Long:    content.CmdCloudImportLong,
+		Example: content.CmdCloudImportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cloudImport.file == "" {
+				return fmt.Errorf(content.ErrorFileRequired)
+			}
+			if cloudImport.client == nil {
+				cloudImport.client = newCoreoClient()
+			}
+
+			return cloudImport.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cloudImport.file, content.CmdFlagFileLong, content.CmdFlagFileShort, "", content.CmdFlagImportFileDescription)
+	f.BoolVarP(&cloudImport.setupEvents, content.CmdFlagSetupEvents, "", false, content.CmdFlagSetupEventsDescription)
+	f.BoolVarP(&cloudImport.ignoreMissingTrails, content.CmdFlagIgnoreMissingTrails, "", false, content.CmdFlagIgnoreMissingTrailsDescription)
+
+	return cmd
+}
+
+func (t *cloudImportCmd) run() error {
+	accounts, err := inventory.Load(t.file)
+	if err != nil {
+		return err
+	}
+
+	existing, err := t.client.ListCloudAccounts(rootCtx, client.ListOptions{})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string)
+	accountIDs := make(map[string]string)
+	for _, cloud := range existing {
+		names[cloud.Name] = cloud.ID
+		if cloud.AccountID != "" {
+			accountIDs[cloud.AccountID] = cloud.ID
+		}
+		if cloud.SubscriptionID != "" {
+			accountIDs[cloud.SubscriptionID] = cloud.ID
+		}
+	}
+
+	results := make([]interface{}, len(accounts))
+	failed := 0
+	for i, account := range accounts {
+		result := &importResult{Row: i + 1, Name: account.Name}
+		results[i] = result
+
+		accountID := account.ProviderAccountID()
+		cloudID, ok := names[account.Name]
+		if !ok || account.Name == "" {
+			cloudID, ok = accountIDs[accountID]
+			ok = ok && accountID != ""
+		}
+		if ok {
+			result.Status, result.CloudID, result.Message = content.ImportStatusSkipped, cloudID, content.InfoAccountExists
+			// a previous import may have added the account but failed to set up its event stream
+			if t.setupEvents || account.EventStream {
+				if err := t.setupEventStream(account, cloudID); err != nil {
+					failed++
+					result.Status, result.Message = content.ImportStatusFailed, fmt.Sprintf(content.ErrorExistingEventStreamSetup, err)
+					continue
+				}
+				result.Message = content.InfoAccountExistsEventStreamSetUp
+			}
+			continue
+		}
+
+		cloud, err := t.importAccount(account)
+		if err != nil {
+			failed++
+			result.Status, result.Message = content.ImportStatusFailed, err.Error()
+			continue
+		}
+		result.Status, result.CloudID = content.ImportStatusCreated, cloud.ID
+		names[account.Name] = cloud.ID
+		if accountID != "" {
+			accountIDs[accountID] = cloud.ID
+		}
+
+		if t.setupEvents || account.EventStream {
+			if err := t.setupEventStream(account, cloud.ID); err != nil {
+				failed++
+				result.Status, result.Message = content.ImportStatusEventStreamFailed, fmt.Sprintf(content.ErrorEventStreamSetup, err)
+				continue
+			}
+			result.Message = content.InfoEventStreamSetUp
+		}
+	}
+
+	util.PrintResult(
+		t.out,
+		results,
+		[]string{"Row", "Name", "Status", "CloudID", "Message"},
+		map[string]string{
+			"Row":     "Row",
+			"Name":    "Cloud Account Name",
+			"Status":  "Status",
+			"CloudID": "Cloud Account ID",
+			"Message": "Message",
+		},
+		jsonFormat,
+		verbose)
+
+	if failed > 0 {
+		return &util.ReportedError{Message: fmt.Sprintf(content.ErrorImportFailed, failed, len(accounts))}
+	}
+	return nil
+}
+
+// importAccount adds an account of the file the same way as 'vss cloud add'
+func (t *cloudImportCmd) importAccount(account inventory.Account) (*client.CloudAccount, error) {
+	if err := rootCtx.Err(); err != nil {
+		return nil, err
+	}
+
+	create, err := t.createCmd(account)
+	if err != nil {
+		return nil, err
+	}
+	return create.create()
+}
+
+// createCmd validates an account of the file and returns the command adding it
+func (t *cloudImportCmd) createCmd(account inventory.Account) (*cloudCreateCmd, error) {
+	if account.Name == "" {
+		return nil, fmt.Errorf(content.ErrorNameRequired)
+	}
+
+	create := &cloudCreateCmd{
+		out:            ioutil.Discard,
+		client:         t.client,
+		cloud:          t.cloud,
+		resourceName:   account.Name,
+		roleName:       account.RoleName,
+		externalID:     account.ExternalID,
+		roleArn:        account.RoleArn,
+		awsProfile:     account.AwsProfile,
+		awsProfilePath: account.AwsProfilePath,
+		policy:         account.Policy,
+		isDraft:        account.Draft,
+		userName:       account.UserName,
+		email:          account.Email,
+		environment:    account.Environment,
+		provider:       account.Provider,
+		keyValue:       account.KeyValue,
+		applicationID:  account.ApplicationID,
+		directoryID:    account.DirectoryID,
+		subscriptionID: account.SubscriptionID,
+		tags:           strings.Join(account.Tags, "|"),
+		scanSettings:   inventoryScanSettings(account, nil),
+
+		projectID:                account.ProjectID,
+		serviceAccountEmail:      account.ServiceAccountEmail,
+		serviceAccountKey:        account.ServiceAccountKey,
+		workloadIdentityProvider: account.WorkloadIdentityProvider,
+		workloadIdentitySubject:  account.WorkloadIdentitySubject,
+		gcpCredentials:           account.GCPCredentials,
+	}
+	if create.provider == "" {
+		create.provider = "AWS"
+	}
+	if create.policy == "" && create.provider == "AWS" {
+		create.policy = content.CmdFlagAwsPolicyDefault
+	}
+
+	if err := util.CheckProviderFlag(create.provider); err != nil {
+		return nil, err
+	}
+	if create.provider == "AWS" {
+		if err := util.CheckCloudAddFlagsForAWS(create.externalID, create.roleArn, create.roleName, create.environment); err != nil {
+			return nil, err
+		}
+	} else if create.provider == "GCP" {
+		if err := util.CheckCloudAddFlagsForGCP(create.projectID, create.serviceAccountEmail, create.serviceAccountKey, create.workloadIdentityProvider, create.roleName, create.environment); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := util.CheckCloudAddFlagsForAzure(create.keyValue, create.applicationID, create.directoryID, create.subscriptionID, create.roleName, create.environment); err != nil {
+			return nil, err
+		}
+	}
+
+	if create.cloud == nil {
+		if create.provider == "AWS" {
+			create.cloud = aws.NewService(&aws.NewServiceInput{
+				AwsProfile:     account.AwsProfile,
+				AwsProfilePath: account.AwsProfilePath,
+				HTTPClient:     httpClient,
+			})
+		} else if create.provider == "GCP" {
+			create.cloud = gcp.NewService(&gcp.NewServiceInput{
+				ProjectID:                account.ProjectID,
+				CredentialsFile:          account.GCPCredentials,
+				WorkloadIdentityProvider: account.WorkloadIdentityProvider,
+				WorkloadIdentitySubject:  account.WorkloadIdentitySubject,
+				HTTPClient:               httpClient,
+			})
+		} else {
+			create.cloud = azure.NewService(&azure.NewServiceInput{
+				AuthFile:       account.AuthFile,
+				Region:         account.Region,
+				SubscriptionID: account.SubscriptionID,
+				HTTPClient:     httpClient,
+			})
+		}
+	}
+
+	return create, nil
+}
+
+// setupEventStream sets up the event stream of an added account the same way as 'vss event setup'
+func (t *cloudImportCmd) setupEventStream(account inventory.Account, cloudID string) error {
+	setup := &eventSetupCmd{
+		client:              t.client,
+		cloud:               t.cloud,
+		out:                 ioutil.Discard,
+		awsProfile:          account.AwsProfile,
+		awsProfilePath:      account.AwsProfilePath,
+		cloudID:             cloudID,
+		ignoreMissingTrails: t.ignoreMissingTrails,
+		authFile:            account.AuthFile,
+		region:              account.Region,
+		gcpCredentials:      account.GCPCredentials,
+	}
+	if setup.region == "" {
+		setup.region = defaultAzureRegion
+	}
+	return setup.run()
+}
+
+// inventoryScanSettings returns the scan settings of an account of the file, the
+// settings it leaves out are kept from current when it is set. It returns nil
+// when neither sets any.
+func inventoryScanSettings(account inventory.Account, current *client.CloudAccount) *client.ScanSettings {
+	if current == nil && account.ScanEnabled == nil && account.ScanInterval == "" && account.ScanRegion == "" {
+		return nil
+	}
+
+	settings := &client.ScanSettings{Enabled: true, Interval: account.ScanInterval, Region: account.ScanRegion}
+	if current != nil {
+		settings.Enabled = current.ScanEnabled
+		if settings.Interval == "" {
+			settings.Interval = current.ScanInterval
+		}
+		if settings.Region == "" {
+			settings.Region = current.ScanRegion
+		}
+	}
+	if account.ScanEnabled != nil {
+		settings.Enabled = *account.ScanEnabled
+	}
+	return settings
+}
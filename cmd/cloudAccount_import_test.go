@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+)
+
+func TestCloudAccountImportCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vss-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	existing := []*client.CloudAccount{
+		{ID: "id1", AccountID: "111111111111", CloudInfo: client.CloudInfo{Name: "existing"}},
+	}
+
+	tests := []struct {
+		desc     string
+		flags    []string
+		accounts []*client.CloudAccount
+		cloud    *fakeCloudProvider
+		err      bool
+		xout     string
+		xsetups  int
+	}{
+		{
+			desc: "import skips existing accounts and reports invalid rows",
+			flags: []string{"--file", writeFile("accounts.csv", `name,roleArn,externalId
+existing,arn:aws:iam::999999999999:role/vss,ext
+same-account,arn:aws:iam::111111111111:role/vss,ext
+new,arn:aws:iam::222222222222:role/vss,ext
+missing-external-id,arn:aws:iam::333333333333:role/vss,
+`)},
+			accounts: existing,
+			err:      true,
+			xout:     `existing[\s\S]*Skipped[\s\S]*same-account[\s\S]*Skipped[\s\S]*new[\s\S]*Created[\s\S]*missing-external-id[\s\S]*Failed`,
+		},
+		{
+			desc: "import a yaml document with event stream setup",
+			flags: []string{"--file", writeFile("accounts.yaml", `version: v1
+accounts:
+- name: new
+  roleArn: arn:aws:iam::222222222222:role/vss
+  externalId: ext
+  eventStream: true
+`)},
+			accounts: existing,
+			xout:     `new[\s\S]*Created[\s\S]*Event stream set up`,
+			xsetups:  1,
+		},
+		{
+			desc: "import reports accounts added without their event stream",
+			flags: []string{"--file", writeFile("eventStreamFailed.yaml", `- name: new
+  roleArn: arn:aws:iam::222222222222:role/vss
+  externalId: ext
+  eventStream: true
+`)},
+			accounts: existing,
+			cloud:    &fakeCloudProvider{setupErr: errors.New("stack failed")},
+			err:      true,
+			xout:     `new\s+Added, event stream failed\s+id1\s+Cloud account added but event stream setup failed: stack failed`,
+			xsetups:  1,
+		},
+		{
+			desc: "import sets up the event stream of existing accounts",
+			flags: []string{"--file", writeFile("existingEventStream.yaml", `- name: existing
+  roleArn: arn:aws:iam::111111111111:role/vss
+  externalId: ext
+  eventStream: true
+- name: same-account
+  roleArn: arn:aws:iam::111111111111:role/vss
+  externalId: ext
+`)},
+			accounts: existing,
+			xout:     `existing\s+Skipped\s+id1\s+Cloud account already exists, event stream set up[\s\S]*same-account\s+Skipped\s+id1\s+Cloud account already exists\s`,
+			xsetups:  1,
+		},
+		{
+			desc:  "import an unsupported file",
+			flags: []string{"--file", writeFile("accounts.txt", "")},
+			err:   true,
+		},
+		{
+			desc: "import without a file",
+			err:  true,
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: tt.accounts, regions: []string{"us-east-1"}}
+
+		cloud := tt.cloud
+		if cloud == nil {
+			cloud = &fakeCloudProvider{}
+		}
+
+		cmd := newCloudImportCmd(frc, cloud, &buf)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, nil)
+
+		if (err != nil) != tt.err {
+			t.Errorf("%q. expected error %v, got '%v'", tt.desc, tt.err, err)
+		}
+
+		re := regexp.MustCompile(tt.xout)
+		if !re.Match(buf.Bytes()) {
+			t.Fatalf("%q:\nexpected\n\t%q\nactual\n\t%q", tt.desc, tt.xout, buf.String())
+		}
+		if cloud.setups != tt.xsetups {
+			t.Errorf("%q. expected %d event stream setups, got %d", tt.desc, tt.xsetups, cloud.setups)
+		}
+		buf.Reset()
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/spf13/cobra"
+)
+
+func newCloudPolicyCmd(client command.Interface, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     content.CmdPolicyUse,
+		Short:   content.CmdCloudPolicyShort,
+		Long:    content.CmdCloudPolicyLong,
+		Example: content.CmdCloudPolicyExample,
+	}
+
+	cmd.AddCommand(newCloudPolicyShowCmd(client, out))
+	cmd.AddCommand(newCloudPolicyUpdateCmd(client, out))
+
+	return cmd
+}
+
+type cloudPolicyCmd struct {
+	out            io.Writer
+	client         command.Interface
+	cloud          command.CloudProvider
+	cloudID        string
+	awsProfile     string
+	awsProfilePath string
+	session        awsSession
+	dryRun         bool
+}
+
+// policyResult is the result of updating the inline policy of a role
+type policyResult struct {
+	CloudID  string `json:"cloudId"`
+	Name     string `json:"name"`
+	RoleName string `json:"roleName"`
+	Policy   string `json:"policy"`
+	Status   string `json:"status"`
+}
+
+func newCloudPolicyShowCmd(client command.Interface, out io.Writer) *cobra.Command {
+	cloudPolicy := &cloudPolicyCmd{
+		out:    out,
+		client: client,
+	}
+
+	return &cobra.Command{
+		Use:   content.CmdShowUse,
+		Short: content.CmdCloudPolicyShowShort,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cloudPolicy.client == nil {
+				cloudPolicy.client = newCoreoClient()
+			}
+
+			return cloudPolicy.show()
+		},
+	}
+}
+
+func newCloudPolicyUpdateCmd(client command.Interface, out io.Writer) *cobra.Command {
+	cloudPolicy := &cloudPolicyCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:   content.CmdUpdateUse,
+		Short: content.CmdCloudPolicyUpdateShort,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := util.CheckCloudShowOrDeleteFlag(cloudPolicy.cloudID, verbose); err != nil {
+				return err
+			}
+			if cloudPolicy.client == nil {
+				cloudPolicy.client = newCoreoClient()
+			}
+			if cloudPolicy.cloud == nil {
+				cloudPolicy.cloud = aws.NewService(cloudPolicy.session.serviceInput(cloudPolicy.awsProfile, cloudPolicy.awsProfilePath))
+			}
+
+			return cloudPolicy.update()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cloudPolicy.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
+	f.StringVarP(&cloudPolicy.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
+	f.StringVarP(&cloudPolicy.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	cloudPolicy.session.addFlags(cmd)
+	f.BoolVarP(&cloudPolicy.dryRun, content.CmdFlagDryRun, "", false, content.CmdFlagPolicyDryRunDescription)
+
+	return cmd
+}
+
+// catalog returns the policy catalog of the VSS configuration, the default
+// catalog of the CLI is used when it has none
+func (t *cloudPolicyCmd) catalog() (client.PolicyCatalog, error) {
+	return t.client.GetPolicyCatalog(rootCtx)
+}
+
+// show prints the inline policy roles are created with
+func (t *cloudPolicyCmd) show() error {
+	catalog, err := t.catalog()
+	if err != nil {
+		return err
+	}
+	document, err := aws.PolicyDocument(catalog)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(t.out, util.PrettyJSON(json.RawMessage(document)))
+	return nil
+}
+
+// update puts the inline policy of the current catalog on the role of the
+// cloud account, the role keeps its other policies
+func (t *cloudPolicyCmd) update() error {
+	cloud, err := t.client.ShowCloudAccountByID(rootCtx, t.cloudID)
+	if err != nil {
+		return err
+	}
+	if cloud.Provider != "" && cloud.Provider != "AWS" {
+		return fmt.Errorf(content.ErrorInlinePolicyNotSupported, cloud.Provider)
+	}
+	_, roleName, err := parseRoleArn(cloud.Arn)
+	if err != nil {
+		return err
+	}
+	catalog, err := t.catalog()
+	if err != nil {
+		return err
+	}
+
+	if t.dryRun {
+		document, err := aws.PolicyDocument(catalog)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(t.out, util.PrettyJSON(json.RawMessage(document)))
+		return nil
+	}
+
+	updater, ok := t.cloud.(command.InlinePolicyUpdater)
+	if !ok {
+		return fmt.Errorf(content.ErrorInlinePolicyNotSupported, cloud.Provider)
+	}
+	changed, err := updater.UpdateInlinePolicy(rootCtx, roleName, catalog)
+	if err != nil {
+		return err
+	}
+
+	status := content.PolicyStatusUnchanged
+	if changed {
+		status = content.PolicyStatusUpdated
+	}
+	util.PrintResult(
+		t.out,
+		&policyResult{CloudID: t.cloudID, Name: cloud.Name, RoleName: roleName, Policy: aws.InlinePolicyName, Status: status},
+		[]string{"CloudID", "Name", "RoleName", "Policy", "Status"},
+		map[string]string{
+			"CloudID":  "Cloud Account ID",
+			"Name":     "Cloud Account Name",
+			"RoleName": "Role Name",
+			"Policy":   "Inline Policy",
+			"Status":   "Status",
+		},
+		jsonFormat,
+		verbose)
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePolicyUpdater is a fakeCloudProvider putting inline policies
+type fakePolicyUpdater struct {
+	fakeCloudProvider
+	changed  bool
+	roleName string
+	catalog  client.PolicyCatalog
+}
+
+func (c *fakePolicyUpdater) UpdateInlinePolicy(ctx context.Context, roleName string, catalog client.PolicyCatalog) (bool, error) {
+	c.roleName, c.catalog = roleName, catalog
+	return c.changed, c.err
+}
+
+func policyTestClient(provider string) *fakeReleaseClient {
+	return &fakeReleaseClient{
+		cloudAccounts: []*client.CloudAccount{{ID: "cloud-1", CloudInfo: client.CloudInfo{Name: "prod", Provider: provider, Arn: "arn:aws:iam::123456789012:role/path/vss"}}},
+		info:          client.RoleCreationInfo{Catalog: client.PolicyCatalog{"s3": {"ListAllMyBuckets"}}},
+	}
+}
+
+func TestCloudPolicyShow(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := newCloudPolicyShowCmd(policyTestClient("AWS"), &buf)
+	assert.Nil(t, cmd.RunE(cmd, nil))
+
+	policy := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &policy))
+	assert.Equal(t, []interface{}{"s3:ListAllMyBuckets"}, policy["Statement"].([]interface{})[0].(map[string]interface{})["Action"])
+	assert.Contains(t, buf.String(), "\n\t\"Statement\"")
+}
+
+func TestCloudPolicyUpdate(t *testing.T) {
+	for _, changed := range []bool{true, false} {
+		var buf bytes.Buffer
+		updater := &fakePolicyUpdater{changed: changed}
+		update := &cloudPolicyCmd{out: &buf, client: policyTestClient("AWS"), cloud: updater, cloudID: "cloud-1"}
+		assert.Nil(t, update.update())
+		assert.Equal(t, "vss", updater.roleName)
+		assert.Equal(t, client.PolicyCatalog{"s3": {"ListAllMyBuckets"}}, updater.catalog)
+		if changed {
+			assert.Contains(t, buf.String(), "Updated")
+		} else {
+			assert.Contains(t, buf.String(), "Unchanged")
+		}
+	}
+}
+
+func TestCloudPolicyUpdateDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	updater := &fakePolicyUpdater{}
+	update := &cloudPolicyCmd{out: &buf, client: policyTestClient("AWS"), cloud: updater, cloudID: "cloud-1", dryRun: true}
+	assert.Nil(t, update.update())
+	assert.Equal(t, "", updater.roleName)
+	assert.Contains(t, buf.String(), `"s3:ListAllMyBuckets"`)
+}
+
+func TestCloudPolicyUpdateNotAWS(t *testing.T) {
+	update := &cloudPolicyCmd{out: &bytes.Buffer{}, client: policyTestClient("GCP"), cloud: &fakePolicyUpdater{}, cloudID: "cloud-1"}
+	assert.EqualError(t, update.update(), "Inline policies are only supported for AWS roles, not for GCP cloud accounts")
+
+	update = &cloudPolicyCmd{out: &bytes.Buffer{}, client: policyTestClient("AWS"), cloud: &fakeCloudProvider{}, cloudID: "cloud-1"}
+	assert.NotNil(t, update.update())
+}
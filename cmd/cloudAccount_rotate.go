@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/azure"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type cloudRotateCmd struct {
+	out            io.Writer
+	client         command.Interface
+	cloud          command.CloudProvider
+	cloudID        string
+	awsProfile     string
+	awsProfilePath string
+	session        awsSession
+	authFile       string
+}
+
+// rotateResult is the result of rotating the credentials of a cloud account
+type rotateResult struct {
+	CloudID  string `json:"cloudId"`
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Message  string `json:"message"`
+}
+
+func newCloudRotateCmd(client command.Interface, out io.Writer) *cobra.Command {
+	cloudRotate := &cloudRotateCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdRotateUse,
+		Short:   content.CmdCloudRotateShort,
+		Long:    content.CmdCloudRotateLong,
+		Example: content.CmdCloudRotateExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := util.CheckCloudShowOrDeleteFlag(cloudRotate.cloudID, verbose); err != nil {
+				return err
+			}
+			if cloudRotate.client == nil {
+				cloudRotate.client = newCoreoClient()
+			}
+
+			return cloudRotate.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cloudRotate.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
+	f.StringVarP(&cloudRotate.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
+	f.StringVarP(&cloudRotate.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	cloudRotate.session.addFlags(cmd)
+	f.StringVarP(&cloudRotate.authFile, content.CmdEventAuthFile, "", "", content.CmdFlagAzureAuthFileDescription)
+
+	return cmd
+}
+
+func (t *cloudRotateCmd) run() error {
+	cloud, err := t.client.ShowCloudAccountByID(rootCtx, t.cloudID)
+	if err != nil {
+		return err
+	}
+	if cloud.Provider == "GCP" {
+		return fmt.Errorf(content.ErrorRotateNotSupported, cloud.Provider)
+	}
+	if t.cloud == nil {
+		t.cloud = t.newProvider(cloud)
+	}
+
+	var res *client.RoleReValidationResult
+	if cloud.Provider == "Azure" {
+		res, err = t.rotateSecret(cloud)
+	} else {
+		res, err = t.rotateExternalID(cloud)
+	}
+	if err != nil {
+		return err
+	}
+
+	message := res.Message
+	if message == "" {
+		message = content.InfoCredentialsRotated
+	}
+	util.PrintResult(
+		t.out,
+		&rotateResult{CloudID: t.cloudID, Name: cloud.Name, Provider: cloud.Provider, Message: message},
+		[]string{"CloudID", "Name", "Provider", "Message"},
+		map[string]string{
+			"CloudID":  "Cloud Account ID",
+			"Name":     "Cloud Account Name",
+			"Provider": "Provider",
+			"Message":  "Message",
+		},
+		jsonFormat,
+		verbose)
+	return nil
+}
+
+// rotateExternalID makes the role trust a new external ID along with the current
+// one, switches the account to it and stops trusting the current one once the
+// account is valid
+func (t *cloudRotateCmd) rotateExternalID(cloud *client.CloudAccount) (*client.RoleReValidationResult, error) {
+	updater, ok := t.cloud.(command.ExternalIDUpdater)
+	if !ok {
+		return nil, fmt.Errorf(content.ErrorRotateNotSupported, cloud.Provider)
+	}
+	_, roleName, err := parseRoleArn(cloud.Arn)
+	if err != nil {
+		return nil, err
+	}
+	current := cloud.ExternalID
+	if current == "" {
+		return nil, errors.New(content.ErrorExternalIDUnknown)
+	}
+	info, err := t.client.GetRoleCreationInfo(rootCtx, &client.CreateCloudAccountInput{RoleName: roleName})
+	if err != nil {
+		return nil, err
+	}
+	externalID := info.ExternalID
+
+	// the role and the account get their old external ID back when the account isn't valid with the new one
+	tx := &transaction{}
+	if err := updater.UpdateExternalID(rootCtx, roleName, current, []string{current, externalID}); err != nil {
+		return nil, err
+	}
+	tx.record(fmt.Sprintf(content.RollbackResourceTrustedExternalID, externalID, roleName),
+		[]string{fmt.Sprintf(content.RecoveryAWSTrustedExternalID, roleName, externalID)},
+		func(ctx context.Context) error {
+			return updater.UpdateExternalID(ctx, roleName, current, []string{current})
+		})
+	res, err := t.updateAndValidate(tx,
+		&client.UpdateCloudAccountInput{
+			CreateCloudAccountInput: client.CreateCloudAccountInput{ExternalID: externalID},
+			CloudID:                 t.cloudID,
+			Fields:                  []string{"externalId"},
+		},
+		&client.UpdateCloudAccountInput{
+			CreateCloudAccountInput: client.CreateCloudAccountInput{ExternalID: current},
+			CloudID:                 t.cloudID,
+			Fields:                  []string{"externalId"},
+		},
+		fmt.Sprintf(content.RecoveryRevertAccountExternalID, t.cloudID, current))
+	if err != nil {
+		return nil, tx.rollback(err)
+	}
+
+	if err := updater.UpdateExternalID(rootCtx, roleName, externalID, []string{externalID}); err != nil {
+		return nil, fmt.Errorf(content.ErrorOldExternalIDTrusted, err)
+	}
+	return res, nil
+}
+
+// rotateSecret creates a new client secret of the application, switches the
+// account to it and removes the old client secret once the account is valid
+func (t *cloudRotateCmd) rotateSecret(cloud *client.CloudAccount) (*client.RoleReValidationResult, error) {
+	rotator, ok := t.cloud.(command.SecretRotator)
+	if !ok {
+		return nil, fmt.Errorf(content.ErrorRotateNotSupported, cloud.Provider)
+	}
+	appID := cloud.ApplicationID
+	if appID == "" {
+		return nil, errors.New(content.ErrorApplicationIDUnknown)
+	}
+	if cloud.KeyValue == "" {
+		// the account couldn't get its old client secret back when the new one isn't valid
+		return nil, errors.New(content.ErrorClientSecretUnknown)
+	}
+	before, err := rotator.ListSecrets(rootCtx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the new client secret is removed and the account gets the old one back when it isn't valid with the new one
+	tx := &transaction{}
+	secret, err := rotator.AddSecret(rootCtx, appID)
+	if err != nil {
+		return nil, err
+	}
+	tx.record(fmt.Sprintf(content.RollbackResourceClientSecret, secret.KeyID, appID),
+		[]string{fmt.Sprintf(content.RecoveryAzureDeleteSecret, appID, secret.KeyID)},
+		func(ctx context.Context) error {
+			return rotator.RemoveSecret(ctx, appID, secret.KeyID)
+		})
+	res, err := t.updateAndValidate(tx,
+		&client.UpdateCloudAccountInput{
+			CreateCloudAccountInput: client.CreateCloudAccountInput{KeyValue: secret.Value},
+			CloudID:                 t.cloudID,
+			Fields:                  []string{"key"},
+		},
+		&client.UpdateCloudAccountInput{
+			CreateCloudAccountInput: client.CreateCloudAccountInput{KeyValue: cloud.KeyValue},
+			CloudID:                 t.cloudID,
+			Fields:                  []string{"key"},
+		},
+		fmt.Sprintf(content.RecoveryRevertAccountSecret, t.cloudID))
+	if err != nil {
+		return nil, tx.rollback(err)
+	}
+
+	for _, s := range oldSecrets(before, cloud.KeyValue) {
+		if err := rotator.RemoveSecret(rootCtx, appID, s.KeyID); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// oldSecrets returns the client secrets the account used before the rotation.
+// Azure only returns the first characters of a secret as its hint.
+func oldSecrets(secrets []*client.Secret, key string) []*client.Secret {
+	var old []*client.Secret
+	for _, s := range secrets {
+		if s.Hint != "" && strings.HasPrefix(key, s.Hint) {
+			old = append(old, s)
+		}
+	}
+	return old
+}
+
+// updateAndValidate updates the account with the new credentials and re-validates
+// it until they have propagated. Once the account is updated, updating it with
+// revert is recorded in tx.
+func (t *cloudRotateCmd) updateAndValidate(tx *transaction, input, revert *client.UpdateCloudAccountInput, recovery string) (*client.RoleReValidationResult, error) {
+	if _, err := t.client.UpdateCloudAccount(rootCtx, input); err != nil {
+		return nil, err
+	}
+	tx.record(fmt.Sprintf(content.RollbackResourceAccountCredentials, t.cloudID), []string{recovery}, func(ctx context.Context) error {
+		_, err := t.client.UpdateCloudAccount(ctx, revert)
+		return err
+	})
+	res, err := waitUntilValid(t.client, t.cloudID)
+	if err != nil {
+		return nil, err
+	}
+	if !res.IsValid {
+		return nil, fmt.Errorf(content.ErrorRotationInvalid, res.Message)
+	}
+	return res, nil
+}
+
+// newProvider returns the service rotating the credentials of the cloud account
+func (t *cloudRotateCmd) newProvider(cloud *client.CloudAccount) command.CloudProvider {
+	if cloud.Provider == "Azure" {
+		return azure.NewService(&azure.NewServiceInput{
+			AuthFile:       t.authFile,
+			SubscriptionID: cloud.SubscriptionID,
+			HTTPClient:     httpClient,
+		})
+	}
+	return aws.NewService(t.session.serviceInput(t.awsProfile, t.awsProfilePath))
+}
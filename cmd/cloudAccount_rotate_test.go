@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRotator is a fakeCloudProvider updating external IDs and client secrets
+type fakeRotator struct {
+	fakeCloudProvider
+	// trusted are the external IDs the role trusts
+	trusted []string
+	secrets []*client.Secret
+	added   *client.Secret
+	removed []string
+	// removeErr is returned by RemoveSecret
+	removeErr error
+}
+
+func (c *fakeRotator) UpdateExternalID(ctx context.Context, roleName, current string, externalIDs []string) error {
+	c.trusted = externalIDs
+	return nil
+}
+
+func (c *fakeRotator) ListSecrets(ctx context.Context, applicationID string) ([]*client.Secret, error) {
+	return c.secrets, nil
+}
+
+func (c *fakeRotator) AddSecret(ctx context.Context, applicationID string) (*client.Secret, error) {
+	c.added = &client.Secret{KeyID: "key-new", Hint: "new", Value: "new-secret"}
+	return c.added, nil
+}
+
+func (c *fakeRotator) RemoveSecret(ctx context.Context, applicationID, keyID string) error {
+	c.removed = append(c.removed, keyID)
+	return c.removeErr
+}
+
+func rotateTestCmd(cloud client.CloudInfo, valid bool) (*cloudRotateCmd, *fakeReleaseClient, *fakeRotator) {
+	frc := &fakeReleaseClient{
+		cloudAccounts:    []*client.CloudAccount{{ID: "cloud-1", CloudInfo: cloud}},
+		info:             client.RoleCreationInfo{ExternalID: "new-id"},
+		validationResult: client.RoleReValidationResult{IsValid: valid, Message: "validation message"},
+	}
+	rotator := &fakeRotator{}
+	return &cloudRotateCmd{out: &bytes.Buffer{}, client: frc, cloud: rotator, cloudID: "cloud-1"}, frc, rotator
+}
+
+func TestCloudAccountRotateExternalID(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{}
+	aws := client.CloudInfo{Provider: "AWS", Arn: "arn:aws:iam::123456789012:role/vss", ExternalID: "old-id"}
+
+	rotate, frc, rotator := rotateTestCmd(aws, true)
+	assert.Nil(t, rotate.run())
+	assert.Equal(t, "new-id", frc.updated.ExternalID)
+	assert.Equal(t, []string{"externalId"}, frc.updated.Fields)
+	assert.Equal(t, []string{"new-id"}, rotator.trusted)
+
+	rotate, frc, rotator = rotateTestCmd(aws, false)
+	assert.EqualError(t, rotate.run(), "The cloud account isn't valid with the new credentials, validation message. The old credentials are kept")
+	assert.Equal(t, "old-id", frc.updated.ExternalID)
+	assert.Equal(t, []string{"old-id"}, rotator.trusted)
+
+	rotate, _, _ = rotateTestCmd(client.CloudInfo{Provider: "AWS", Arn: aws.Arn}, true)
+	assert.NotNil(t, rotate.run())
+}
+
+func TestCloudAccountRotateSecret(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{}
+	azure := client.CloudInfo{Provider: "Azure", ApplicationID: "app-1", KeyValue: "abc-old-secret"}
+	secrets := []*client.Secret{{KeyID: "key-1", Hint: "abc"}, {KeyID: "key-2", Hint: "xyz"}}
+
+	rotate, frc, rotator := rotateTestCmd(azure, true)
+	rotator.secrets = secrets
+	assert.Nil(t, rotate.run())
+	assert.Equal(t, "new-secret", frc.updated.KeyValue)
+	assert.Equal(t, []string{"key"}, frc.updated.Fields)
+	assert.Equal(t, []string{"key-1"}, rotator.removed)
+
+	rotate, frc, rotator = rotateTestCmd(azure, false)
+	rotator.secrets = secrets
+	assert.NotNil(t, rotate.run())
+	assert.Equal(t, "abc-old-secret", frc.updated.KeyValue)
+	assert.Equal(t, []string{"key-new"}, rotator.removed)
+
+	// a new secret that couldn't be removed again is part of the error
+	rotate, _, rotator = rotateTestCmd(azure, false)
+	rotator.removeErr = assert.AnError
+	err := rotate.run()
+	assert.IsType(t, &rollbackError{}, err)
+	assert.Contains(t, err.Error(), "az ad app credential delete --id app-1 --key-id key-new")
+
+	// without its key, the account couldn't get it back, so it isn't updated
+	rotate, frc, rotator = rotateTestCmd(client.CloudInfo{Provider: "Azure", ApplicationID: "app-1"}, true)
+	assert.NotNil(t, rotate.run())
+	assert.Nil(t, frc.updated)
+	assert.Nil(t, rotator.added)
+}
+
+func TestOldSecrets(t *testing.T) {
+	secrets := []*client.Secret{{KeyID: "key-1", Hint: "abc"}, {KeyID: "key-2", Hint: "xyz"}}
+
+	assert.Equal(t, secrets[1:], oldSecrets(secrets, "xyz-secret"))
+	assert.Empty(t, oldSecrets(secrets, "other-secret"))
+}
+
+func TestCloudAccountRotateGCP(t *testing.T) {
+	rotate, _, _ := rotateTestCmd(client.CloudInfo{Provider: "GCP"}, true)
+	assert.EqualError(t, rotate.run(), "Rotating the credentials of GCP cloud accounts isn't supported")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/spf13/cobra"
+)
+
+type cloudScanCmd struct {
+	out              io.Writer
+	client           command.Interface
+	org              command.Organization
+	roleName         string
+	crossAccountRole string
+	roleSessionName  string
+	duration         int64
+	awsProfile       string
+	awsProfilePath   string
+	session          awsSession
+	policy           string
+	inlinePolicy     bool
+	environment      string
+	includeOUs       []string
+	excludeOUs       []string
+	includeAccounts  []string
+	excludeAccounts  []string
+	concurrency      int
+}
+
+// defaultScanConcurrency is how many accounts 'vss cloud scan' adds at the same
+// time, each waits up to the propagation timeout for its new role
+const defaultScanConcurrency = 5
+
+// scanResult is the outcome of adding one account of the organization
+type scanResult struct {
+	AccountID string `json:"accountId"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	CloudID   string `json:"cloudId,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+func newCloudScanCmd(client command.Interface, org command.Organization, out io.Writer) *cobra.Command {
+	cloudScan := &cloudScanCmd{
+		out:    out,
+		client: client,
+		org:    org,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdScanUse,
+		Short:   content.CmdCloudScanShort,
+		Long:    content.CmdCloudScanLong,
+		Example: content.CmdCloudScanExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cloudScan.roleName == "" {
+				return fmt.Errorf(content.ErrorRoleNameRequired)
+			}
+			if err := util.CheckCloudAddFlagsForAWS("", "", cloudScan.roleName, cloudScan.environment); err != nil {
+				return err
+			}
+			if cloudScan.concurrency < 1 {
+				return fmt.Errorf(content.ErrorConcurrency)
+			}
+			if cloudScan.client == nil {
+				cloudScan.client = newCoreoClient()
+			}
+			if cloudScan.org == nil {
+				newServiceInput := cloudScan.session.serviceInput(cloudScan.awsProfile, cloudScan.awsProfilePath)
+				newServiceInput.RoleSessionName = cloudScan.roleSessionName
+				newServiceInput.Duration = cloudScan.duration
+				newServiceInput.CrossAccountRole = cloudScan.crossAccountRole
+				cloudScan.org = aws.NewOrganizationService(newServiceInput)
+			}
+
+			return cloudScan.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cloudScan.roleName, content.CmdFlagRoleName, "", "", content.CmdFlagRoleNameDescription)
+	f.StringVarP(&cloudScan.crossAccountRole, content.CmdFlagCrossAccountRole, "", aws.DefaultCrossAccountRole, content.CmdFlagCrossAccountRoleDescription)
+	f.StringVarP(&cloudScan.roleSessionName, content.CmdFlagRoleSessionName, "", aws.DefaultRoleSessionName, content.CmdFlagRoleSessionNameDescription)
+	f.Int64VarP(&cloudScan.duration, content.CmdFlagDuration, "", 3600, content.CmdFlagDurationDescription)
+	f.StringVarP(&cloudScan.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
+	f.StringVarP(&cloudScan.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	cloudScan.session.addFlags(cmd)
+	f.StringVarP(&cloudScan.policy, content.CmdFlagAwsPolicy, "", content.CmdFlagAwsPolicyDefault, content.CmdFlagAwsPolicyDescription)
+	f.BoolVarP(&cloudScan.inlinePolicy, content.CmdFlagInlinePolicy, "", false, content.CmdFlagInlinePolicyDescription)
+	f.StringVarP(&cloudScan.environment, content.CmdFlagEnvironmentLong, content.CmdFlagEnvironmentShort, "", content.CmdFlagEnvironmentDescription)
+	f.StringSliceVar(&cloudScan.includeOUs, content.CmdFlagIncludeOU, nil, content.CmdFlagIncludeOUDescription)
+	f.StringSliceVar(&cloudScan.excludeOUs, content.CmdFlagExcludeOU, nil, content.CmdFlagExcludeOUDescription)
+	f.StringSliceVar(&cloudScan.includeAccounts, content.CmdFlagIncludeAccount, nil, content.CmdFlagIncludeAccountDescription)
+	f.StringSliceVar(&cloudScan.excludeAccounts, content.CmdFlagExcludeAccount, nil, content.CmdFlagExcludeAccountDescription)
+	f.IntVar(&cloudScan.concurrency, content.CmdFlagConcurrencyLong, defaultScanConcurrency, content.CmdFlagScanConcurrencyDescription)
+
+	return cmd
+}
+
+func (t *cloudScanCmd) run() error {
+	accounts, err := t.org.ListAccounts(rootCtx)
+	if err != nil {
+		return err
+	}
+
+	existing, err := t.client.ListCloudAccounts(rootCtx, client.ListOptions{})
+	if err != nil {
+		return err
+	}
+	cloudIDs := make(map[string]string)
+	for _, cloud := range existing {
+		if cloud.AccountID != "" {
+			cloudIDs[cloud.AccountID] = cloud.ID
+		}
+	}
+
+	var selected []*client.OrganizationAccount
+	for _, account := range accounts {
+		if t.selected(account) {
+			selected = append(selected, account)
+		}
+	}
+
+	// the accounts are added at most concurrency at the same time, the results
+	// keep the order of the organization
+	results := make([]*scanResult, len(selected))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < t.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = t.scan(selected[i], cloudIDs)
+			}
+		}()
+	}
+	for i := range selected {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	rows := make([]interface{}, len(results))
+	counts := make(map[string]int)
+	for i, result := range results {
+		rows[i] = result
+		counts[result.Status]++
+	}
+
+	util.PrintResult(
+		t.out,
+		rows,
+		[]string{"AccountID", "Name", "Status", "CloudID", "Message"},
+		map[string]string{
+			"AccountID": "AWS Account ID",
+			"Name":      "Cloud Account Name",
+			"Status":    "Status",
+			"CloudID":   "Cloud Account ID",
+			"Message":   "Message",
+		},
+		jsonFormat,
+		verbose)
+	if !jsonFormat {
+		fmt.Fprintf(t.out, content.InfoScanSummary, counts[content.ImportStatusCreated], counts[content.ImportStatusSkipped], counts[content.ImportStatusFailed])
+	}
+
+	// an interrupted scan exits as interrupted, the accounts it didn't add failed
+	if err := rootCtx.Err(); err != nil {
+		return err
+	}
+	if failed := counts[content.ImportStatusFailed]; failed > 0 {
+		return &util.ReportedError{Message: fmt.Sprintf(content.ErrorImportFailed, failed, len(results))}
+	}
+	return nil
+}
+
+// scan adds the account unless it is one of the existing cloud accounts
+func (t *cloudScanCmd) scan(account *client.OrganizationAccount, cloudIDs map[string]string) *scanResult {
+	result := &scanResult{AccountID: account.ID, Name: account.Name}
+	if cloudID, ok := cloudIDs[account.ID]; ok {
+		result.Status, result.CloudID, result.Message = content.ImportStatusSkipped, cloudID, content.InfoAccountExists
+	} else if cloud, err := t.add(account); err != nil {
+		result.Status, result.Message = content.ImportStatusFailed, err.Error()
+	} else {
+		result.Status, result.CloudID = content.ImportStatusCreated, cloud.ID
+	}
+	return result
+}
+
+// add creates the role in the account and adds it the same way as 'vss cloud add'
+func (t *cloudScanCmd) add(account *client.OrganizationAccount) (*client.CloudAccount, error) {
+	if err := rootCtx.Err(); err != nil {
+		return nil, err
+	}
+
+	provider, err := t.org.AccountProvider(rootCtx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	create := &cloudCreateCmd{
+		out:          ioutil.Discard,
+		client:       t.client,
+		cloud:        provider,
+		resourceName: account.Name,
+		roleName:     t.roleName,
+		policy:       t.policy,
+		inlinePolicy: t.inlinePolicy,
+		environment:  t.environment,
+		provider:     "AWS",
+	}
+	return create.create()
+}
+
+// selected reports whether the account passes the include and exclude filters
+func (t *cloudScanCmd) selected(account *client.OrganizationAccount) bool {
+	inUnits := func(units []string) bool {
+		for _, unit := range account.OrganizationalUnits {
+			if contains(units, unit.ID) || contains(units, unit.Name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if contains(t.excludeAccounts, account.ID) || inUnits(t.excludeOUs) {
+		return false
+	}
+	if len(t.includeAccounts) == 0 && len(t.includeOUs) == 0 {
+		return true
+	}
+	return contains(t.includeAccounts, account.ID) || inUnits(t.includeOUs)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/pkg/errors"
+)
+
+func TestCloudAccountScanCmd(t *testing.T) {
+	propagationBackoff = backoff{}
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+
+	production := []client.OrganizationalUnit{{ID: "ou-prod", Name: "Production"}}
+	sandbox := []client.OrganizationalUnit{{ID: "ou-sandbox", Name: "Sandbox"}}
+	org := func() *fakeOrganization {
+		return &fakeOrganization{
+			accounts: []*client.OrganizationAccount{
+				{ID: "111111111111", Name: "management", Management: true},
+				{ID: "222222222222", Name: "prod", OrganizationalUnits: production},
+				{ID: "333333333333", Name: "prod-denied", OrganizationalUnits: production},
+				{ID: "444444444444", Name: "sandbox", OrganizationalUnits: sandbox},
+			},
+			providers: map[string]command.CloudProvider{
+				"111111111111": &fakeCloudProvider{},
+				"222222222222": &fakeCloudProvider{},
+				"444444444444": &fakeCloudProvider{},
+			},
+		}
+	}
+	existing := []*client.CloudAccount{
+		{ID: "cloud1", AccountID: "111111111111", CloudInfo: client.CloudInfo{Name: "management"}},
+	}
+
+	tests := []struct {
+		desc  string
+		flags []string
+		org   *fakeOrganization
+		err   bool
+		xout  string
+		nout  string
+	}{
+		{
+			desc:  "scan adds the organization accounts",
+			flags: []string{"--role", "vss-role"},
+			org:   org(),
+			err:   true,
+			xout:  `management[\s\S]*Skipped[\s\S]*prod[\s\S]*Created[\s\S]*prod-denied[\s\S]*Failed[\s\S]*AccessDenied[\s\S]*sandbox[\s\S]*Created[\s\S]*2 cloud accounts added, 1 skipped, 1 failed`,
+		},
+		{
+			desc:  "scan filters by organizational unit and account",
+			flags: []string{"--role", "vss-role", "--include-ou", "Production,ou-sandbox", "--exclude-account", "333333333333"},
+			org:   org(),
+			xout:  `prod[\s\S]*Created[\s\S]*sandbox[\s\S]*Created[\s\S]*2 cloud accounts added, 0 skipped, 0 failed`,
+			nout:  `management|prod-denied`,
+		},
+		{
+			desc:  "scan includes accounts by ID",
+			flags: []string{"--role", "vss-role", "--include-account", "444444444444"},
+			org:   org(),
+			xout:  `sandbox[\s\S]*1 cloud accounts added, 0 skipped, 0 failed`,
+			nout:  `prod`,
+		},
+		{
+			desc: "scan requires a role name",
+			org:  org(),
+			err:  true,
+		},
+		{
+			desc:  "scan fails without an organization",
+			flags: []string{"--role", "vss-role"},
+			org:   &fakeOrganization{err: errors.New("AWSOrganizationsNotInUseException")},
+			err:   true,
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: existing, validationResult: client.RoleReValidationResult{IsValid: true}}
+
+		cmd := newCloudScanCmd(frc, tt.org, &buf)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, nil)
+
+		if (err != nil) != tt.err {
+			t.Errorf("%q. expected error %v, got '%v'", tt.desc, tt.err, err)
+		}
+
+		re := regexp.MustCompile(tt.xout)
+		if !re.Match(buf.Bytes()) {
+			t.Fatalf("%q:\nexpected\n\t%q\nactual\n\t%q", tt.desc, tt.xout, buf.String())
+		}
+		if tt.nout != "" && regexp.MustCompile(tt.nout).Match(buf.Bytes()) {
+			t.Fatalf("%q:\nunexpected\n\t%q\nactual\n\t%q", tt.desc, tt.nout, buf.String())
+		}
+		buf.Reset()
+	}
+}
+
+func TestCloudAccountScanInterrupted(t *testing.T) {
+	defer func(ctx context.Context) { rootCtx = ctx }(rootCtx)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rootCtx = ctx
+
+	org := &fakeOrganization{
+		accounts:  []*client.OrganizationAccount{{ID: "222222222222", Name: "prod"}},
+		providers: map[string]command.CloudProvider{"222222222222": &fakeCloudProvider{}},
+	}
+	var buf bytes.Buffer
+	cmd := newCloudScanCmd(&fakeReleaseClient{}, org, &buf)
+	cmd.ParseFlags([]string{"--role", "vss-role"})
+	err := cmd.RunE(cmd, nil)
+
+	if code := exitCode(err); code != exitCodeInterrupted {
+		t.Errorf("expected exit code %d, got %d (%v)", exitCodeInterrupted, code, err)
+	}
+	if !regexp.MustCompile(`prod[\s\S]*Failed`).Match(buf.Bytes()) {
+		t.Errorf("expected the results of the interrupted scan, got %q", buf.String())
+	}
+}
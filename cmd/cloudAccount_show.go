@@ -21,7 +21,6 @@ import (
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/cmd/util"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -48,9 +47,7 @@ func newCloudShowCmd(client command.Interface, out io.Writer) *cobra.Command {
 			}
 
 			if cloudShow.client == nil {
-				cloudShow.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				cloudShow.client = newCoreoClient()
 			}
 
 			return cloudShow.run()
@@ -65,7 +62,7 @@ func newCloudShowCmd(client command.Interface, out io.Writer) *cobra.Command {
 }
 
 func (t *cloudShowCmd) run() error {
-	cloud, err := t.client.ShowCloudAccountByID(t.cloudID)
+	cloud, err := t.client.ShowCloudAccountByID(rootCtx, t.cloudID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/spf13/cobra"
+)
+
+func newCloudTagCmd(client command.Interface, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     content.CmdTagUse,
+		Short:   content.CmdCloudTagShort,
+		Long:    content.CmdCloudTagLong,
+		Example: content.CmdCloudTagExample,
+	}
+
+	cmd.AddCommand(newCloudTagUpdateCmd(client, out, content.CmdTagAddUse, content.CmdCloudTagAddShort, addTags))
+	cmd.AddCommand(newCloudTagUpdateCmd(client, out, content.CmdTagRemoveUse, content.CmdCloudTagRemoveShort, removeTags))
+	cmd.AddCommand(newCloudTagUpdateCmd(client, out, content.CmdTagSetUse, content.CmdCloudTagSetShort, setTags))
+
+	return cmd
+}
+
+// tagUpdate returns the new tags of a cloud account from its current ones and the --tag flags
+type tagUpdate func(current client.Tags, tags []string) (client.Tags, error)
+
+type cloudTagUpdateCmd struct {
+	out     io.Writer
+	client  command.Interface
+	cloudID string
+	tags    []string
+	update  tagUpdate
+}
+
+func newCloudTagUpdateCmd(client command.Interface, out io.Writer, use, short string, update tagUpdate) *cobra.Command {
+	cloudTag := &cloudTagUpdateCmd{
+		out:    out,
+		client: client,
+		update: update,
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := util.CheckCloudShowOrDeleteFlag(cloudTag.cloudID, verbose); err != nil {
+				return err
+			}
+
+			if cloudTag.client == nil {
+				cloudTag.client = newCoreoClient()
+			}
+
+			return cloudTag.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cloudTag.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
+	f.StringArrayVar(&cloudTag.tags, content.CmdFlagTagLong, nil, content.CmdFlagTagDescription)
+
+	return cmd
+}
+
+// run reads the tags of the cloud account and writes them back updated, only
+// the tags are updated
+func (t *cloudTagUpdateCmd) run() error {
+	cloud, err := t.client.ShowCloudAccountByID(rootCtx, t.cloudID)
+	if err != nil {
+		return err
+	}
+	tags, err := t.update(cloud.Tags, t.tags)
+	if err != nil {
+		return err
+	}
+
+	input := &client.UpdateCloudAccountInput{
+		CreateCloudAccountInput: client.CreateCloudAccountInput{
+			TagList: tags,
+		},
+		CloudID: t.cloudID,
+		Fields:  []string{"tags"},
+	}
+	updated, err := t.client.UpdateCloudAccount(rootCtx, input)
+	if err != nil {
+		return err
+	}
+
+	util.PrintResult(
+		t.out,
+		updated,
+		[]string{"ID", "Name", "Tags"},
+		map[string]string{
+			"ID":   "Cloud Account ID",
+			"Name": "Cloud Account Name",
+			"Tags": "Tags",
+		},
+		jsonFormat,
+		verbose)
+	return nil
+}
+
+// applyTags returns the tags with the key=value tags of the flags set
+func applyTags(current client.Tags, tags []string) (client.Tags, error) {
+	result := append(client.Tags{}, current...)
+	for _, s := range tags {
+		tag := client.ParseTag(s)
+		if !tag.Valid() {
+			return nil, fmt.Errorf(content.ErrorInvalidTag, s)
+		}
+		result = result.Set(tag)
+	}
+	return result, nil
+}
+
+func addTags(current client.Tags, tags []string) (client.Tags, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf(content.ErrorTagRequired)
+	}
+	return applyTags(current, tags)
+}
+
+func removeTags(current client.Tags, tags []string) (client.Tags, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf(content.ErrorTagRequired)
+	}
+	result := append(client.Tags{}, current...)
+	for _, tag := range tags {
+		result = result.Remove(tag)
+	}
+	return result, nil
+}
+
+func setTags(current client.Tags, tags []string) (client.Tags, error) {
+	return applyTags(nil, tags)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudAccountTagCmd(t *testing.T) {
+	tests := []struct {
+		desc  string
+		cmd   string
+		flags []string
+		tags  client.Tags
+		err   bool
+	}{
+		{"add a tag and change a value", "add", []string{"--tag", "owner=team-y", "--tag", "env=prod"}, client.Tags{"owner:team-y", "critical", "env:prod"}, false},
+		{"add without tags", "add", nil, nil, true},
+		{"add an empty key", "add", []string{"--tag", "=x"}, nil, true},
+		{"add a key with a colon", "add", []string{"--tag", "team:owner=x"}, nil, true},
+		{"remove by key", "remove", []string{"--tag", "owner"}, client.Tags{"critical"}, false},
+		{"remove by key and other value", "remove", []string{"--tag", "owner=team-y"}, client.Tags{"owner:team-x", "critical"}, false},
+		{"set", "set", []string{"--tag", "owner=team-z"}, client.Tags{"owner:team-z"}, false},
+		{"set nothing removes all tags", "set", nil, client.Tags{}, false},
+	}
+
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: []*client.CloudAccount{{
+			ID:        "cloud-1",
+			CloudInfo: client.CloudInfo{Name: "web", Tags: client.Tags{"owner:team-x", "critical"}},
+		}}}
+		var buf bytes.Buffer
+		root := newCloudTagCmd(frc, &buf)
+		cmd, _, err := root.Find([]string{tt.cmd})
+		assert.Nil(t, err, tt.desc)
+		assert.Nil(t, cmd.ParseFlags(append([]string{"--cloud-id", "cloud-1"}, tt.flags...)), tt.desc)
+
+		err = cmd.RunE(cmd, nil)
+		if tt.err {
+			assert.NotNil(t, err, tt.desc)
+			assert.Nil(t, frc.updated, tt.desc)
+			continue
+		}
+		assert.Nil(t, err, tt.desc)
+		assert.Equal(t, tt.tags, frc.updated.TagList, tt.desc)
+		assert.Equal(t, "cloud-1", frc.updated.CloudID, tt.desc)
+		// the other settings are kept
+		assert.Equal(t, []string{"tags"}, frc.updated.Fields, tt.desc)
+		assert.Contains(t, buf.String(), "web", tt.desc)
+	}
+}
+
+func TestCloudAccountCreateTags(t *testing.T) {
+	frc := &fakeReleaseClient{}
+	create := &cloudCreateCmd{
+		client:       frc,
+		resourceName: "CloudName",
+		roleArn:      "arn",
+		externalID:   "id",
+		provider:     "AWS",
+		tags:         "team:web|critical",
+		tagList:      []string{"owner=team-x", "team=data"},
+	}
+
+	_, err := create.create()
+	assert.Nil(t, err)
+	assert.Equal(t, client.Tags{"team:data", "critical", "owner:team-x"}, frc.created.TagList)
+}
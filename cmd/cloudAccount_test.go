@@ -58,6 +58,34 @@ func TestCloudAccountListCmd(t *testing.T) {
 				`   CloudName2              ID2                  AccountID2           false         \[\]                  \n` +
 				"---------------  -----------------------  ---------------------  ------------  ---------  -------------\n\n",
 		},
+		{
+			cmds:  "coreo cloud list, filtered",
+			desc:  "get filtered and sorted list of cloud accounts with selected columns",
+			flags: []string{"--name", "cloud", "--sort-by", "-name", "--columns", "Name,accountId"},
+			resp: []*client.CloudAccount{
+				mockCloudAccount("CloudName1", "Team1", "ID1", "AccountID1"),
+				mockCloudAccount("Other", "Team2", "ID2", "AccountID2"),
+				mockCloudAccount("CloudName3", "Team3", "ID3", "AccountID3"),
+			},
+			xout: `(?s)Cloud Account Name +Cloud account ID.*CloudName3 +AccountID3.*CloudName1 +AccountID1`,
+		},
+		{
+			cmds:  "coreo cloud list, filtered and limited",
+			desc:  "get the first filtered cloud accounts",
+			flags: []string{"--name", "cloud", "--limit", "1", "--columns", "Name"},
+			resp: []*client.CloudAccount{
+				mockCloudAccount("Other", "Team1", "ID1", "AccountID1"),
+				mockCloudAccount("CloudName2", "Team2", "ID2", "AccountID2"),
+				mockCloudAccount("CloudName3", "Team3", "ID3", "AccountID3"),
+			},
+			xout: `(?s)CloudName2[^C]*$`,
+		},
+		{
+			cmds:  "coreo cloud list, unknown column",
+			desc:  "get list of cloud accounts with an unknown column",
+			flags: []string{"--columns", "Name,Color"},
+			err:   true,
+		},
 		{
 			cmds: "coreo cloud list, failure",
 			desc: "get list of cloud accounts",
@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"time"
+	"strings"
 
 	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/gcp"
 
 	"github.com/CloudCoreo/cli/client"
 	"github.com/CloudCoreo/cli/cmd/util"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 
 	"github.com/CloudCoreo/cli/cmd/content"
 
@@ -33,8 +34,67 @@ type cloudUpdateCmd struct {
 	environment    string
 	awsProfile     string
 	awsProfilePath string
+	session        awsSession
 	policy         string
+	inlinePolicy   bool
 	tags           string
+	tagList        []string
+	keyValue       string
+	applicationID  string
+	directoryID    string
+	subscriptionID string
+	scanEnabled    bool
+	scanInterval   string
+	scanRegion     string
+	dryRun         bool
+
+	projectID                string
+	serviceAccountEmail      string
+	serviceAccountKey        string
+	workloadIdentityProvider string
+
+	// fields are the JSON names of the settings whose flags were given
+	fields []string
+}
+
+// updateFlagFields maps the flags of the update command to the JSON names of the settings they update
+var updateFlagFields = []struct {
+	flag  string
+	field string
+}{
+	{content.CmdFlagNameLong, "name"},
+	{content.CmdFlagRoleArn, "arn"},
+	{content.CmdFlagRoleExternalID, "externalId"},
+	{content.CmdFlagIsDraft, "isDraft"},
+	{content.CmdFlagEmail, "email"},
+	{content.CmdFlagUserName, "username"},
+	{content.CmdFlagEnvironmentLong, "environment"},
+	{content.CmdFlagKeyValue, "key"},
+	{content.CmdFlagApplicationID, "appId"},
+	{content.CmdFlagDirectoryID, "directoryId"},
+	{content.CmdFlagSubscriptionID, "subscriptionId"},
+	{content.CmdFlagProjectID, "projectId"},
+	{content.CmdFlagServiceAccountEmail, "serviceAccountEmail"},
+	{content.CmdFlagServiceAccountKey, "serviceAccountKey"},
+	{content.CmdFlagWorkloadIdentityProvider, "workloadIdentityProvider"},
+	{content.CmdFlagTags, "tags"},
+	{content.CmdFlagTagLong, "tags"},
+	{content.CmdFlagScanEnabled, "scanEnabled"},
+	{content.CmdFlagScanInterval, "scanInterval"},
+	{content.CmdFlagScanRegion, "scanRegion"},
+}
+
+// changedFields returns the JSON names of the settings whose flags were given
+func changedFields(cmd *cobra.Command) []string {
+	fields := []string{}
+	seen := map[string]bool{}
+	for _, f := range updateFlagFields {
+		if cmd.Flags().Changed(f.flag) && !seen[f.field] {
+			fields = append(fields, f.field)
+			seen[f.field] = true
+		}
+	}
+	return fields
 }
 
 func newCloudUpdateCmd(client command.Interface, out io.Writer) *cobra.Command {
@@ -44,25 +104,26 @@ func newCloudUpdateCmd(client command.Interface, out io.Writer) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   content.CmdUpdateUse,
-		Short: content.CmdCloudUpdateShort,
-		Long:  content.CmdCloudUpdateLong,
+		Use:     content.CmdUpdateUse,
+		Short:   content.CmdCloudUpdateShort,
+		Long:    content.CmdCloudUpdateLong,
+		Example: content.CmdCloudUpdateExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := util.CheckCloudShowOrDeleteFlag(cloudUpdate.cloudID, verbose); err != nil {
 				return err
 			}
 
+			cloudUpdate.fields = changedFields(cmd)
+			if len(cloudUpdate.fields) == 0 && cloudUpdate.roleName == "" {
+				return fmt.Errorf(content.ErrorNothingToUpdate)
+			}
+
 			if cloudUpdate.client == nil {
-				cloudUpdate.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				cloudUpdate.client = newCoreoClient()
 			}
 
 			if cloudUpdate.cloud == nil {
-				newServiceInput := &aws.NewServiceInput{
-					AwsProfile:     cloudUpdate.awsProfile,
-					AwsProfilePath: cloudUpdate.awsProfilePath,
-				}
+				newServiceInput := cloudUpdate.session.serviceInput(cloudUpdate.awsProfile, cloudUpdate.awsProfilePath)
 				cloudUpdate.cloud = aws.NewService(newServiceInput)
 			}
 
@@ -81,9 +142,24 @@ func newCloudUpdateCmd(client command.Interface, out io.Writer) *cobra.Command {
 	f.StringVarP(&cloudUpdate.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
 	f.StringVarP(&cloudUpdate.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
 	f.StringVarP(&cloudUpdate.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	cloudUpdate.session.addFlags(cmd)
 	f.StringVarP(&cloudUpdate.policy, content.CmdFlagAwsPolicy, "", content.CmdFlagAwsPolicyDefault, content.CmdFlagAwsPolicyDescription)
+	f.BoolVarP(&cloudUpdate.inlinePolicy, content.CmdFlagInlinePolicy, "", false, content.CmdFlagInlinePolicyDescription)
 	f.StringVarP(&cloudUpdate.roleName, content.CmdFlagRoleName, "", "", content.CmdFlagRoleNameDescription)
 	f.StringVarP(&cloudUpdate.tags, content.CmdFlagTags, "", "", content.CmdFlagTagsDescription)
+	f.StringArrayVar(&cloudUpdate.tagList, content.CmdFlagTagLong, nil, content.CmdFlagTagDescription)
+	f.StringVarP(&cloudUpdate.keyValue, content.CmdFlagKeyValue, "", "", content.CmdFlagKeyValueDescription)
+	f.StringVarP(&cloudUpdate.applicationID, content.CmdFlagApplicationID, "", "", content.CmdFlagApplicationIDDescription)
+	f.StringVarP(&cloudUpdate.directoryID, content.CmdFlagDirectoryID, "", "", content.CmdFlagDirectoryIDDescription)
+	f.StringVarP(&cloudUpdate.subscriptionID, content.CmdFlagSubscriptionID, "", "", content.CmdFlagSubscriptionIDDescription)
+	f.StringVarP(&cloudUpdate.projectID, content.CmdFlagProjectID, "", "", content.CmdFlagProjectIDDescription)
+	f.StringVarP(&cloudUpdate.serviceAccountEmail, content.CmdFlagServiceAccountEmail, "", "", content.CmdFlagServiceAccountEmailDescription)
+	f.StringVarP(&cloudUpdate.serviceAccountKey, content.CmdFlagServiceAccountKey, "", "", content.CmdFlagServiceAccountKeyDescription)
+	f.StringVarP(&cloudUpdate.workloadIdentityProvider, content.CmdFlagWorkloadIdentityProvider, "", "", content.CmdFlagWorkloadIdentityProviderDescription)
+	f.BoolVarP(&cloudUpdate.scanEnabled, content.CmdFlagScanEnabled, "", false, content.CmdFlagScanEnabledDescription)
+	f.StringVarP(&cloudUpdate.scanInterval, content.CmdFlagScanInterval, "", "", content.CmdFlagScanIntervalDescription)
+	f.StringVarP(&cloudUpdate.scanRegion, content.CmdFlagScanRegion, "", "", content.CmdFlagScanRegionDescription)
+	f.BoolVarP(&cloudUpdate.dryRun, content.CmdFlagDryRun, "", false, content.CmdFlagUpdateDryRunDescription)
 	return cmd
 
 }
@@ -101,32 +177,118 @@ func (t *cloudUpdateCmd) run() error {
 			Environment: t.environment,
 			Policy:      t.policy,
 			Tags:        t.tags,
+
+			KeyValue:       t.keyValue,
+			ApplicationID:  t.applicationID,
+			DirectoryID:    t.directoryID,
+			SubscriptionID: t.subscriptionID,
+			ScanSettings: &client.ScanSettings{
+				Enabled:  t.scanEnabled,
+				Interval: t.scanInterval,
+				Region:   t.scanRegion,
+			},
+
+			ProjectID:                t.projectID,
+			ServiceAccountEmail:      t.serviceAccountEmail,
+			WorkloadIdentityProvider: t.workloadIdentityProvider,
 		},
 		CloudID: t.cloudID,
+		Fields:  t.fields,
 	}
 
-	if t.roleName != "" {
-		info, err := t.client.GetRoleCreationInfo(&input.CreateCloudAccountInput)
+	if t.serviceAccountKey != "" {
+		email, key, err := gcp.ReadServiceAccountKey(t.serviceAccountKey)
 		if err != nil {
 			return err
 		}
-		arn, externalID, err := t.cloud.CreateNewRole(info)
-		time.Sleep(10 * time.Second)
+		input.ServiceAccountKey = key
+		if input.ServiceAccountEmail == "" {
+			input.ServiceAccountEmail = email
+			input.Fields = append(input.Fields, "serviceAccountEmail")
+		}
+	}
+
+	if len(t.tagList) > 0 {
+		// the tags are set on the current ones unless --tags replaces them
+		var tags client.Tags
+		if t.tags != "" {
+			tags = strings.Split(t.tags, "|")
+		} else {
+			cloud, err := t.client.ShowCloudAccountByID(rootCtx, t.cloudID)
+			if err != nil {
+				return err
+			}
+			tags = cloud.Tags
+		}
+		var err error
+		if input.TagList, err = applyTags(tags, t.tagList); err != nil {
+			return err
+		}
+	}
+
+	if t.dryRun {
+		// the role isn't created, its arn and external id are only known after creation
+		fmt.Fprint(t.out, util.PrettyJSON(input.RedactedPatch()))
+		return nil
+	}
+
+	// the created role and the new role of the account are undone when a later step fails
+	tx := &transaction{}
+	var current *client.CloudAccount
+	if t.roleName != "" {
+		var err error
+		if current, err = t.client.ShowCloudAccountByID(rootCtx, t.cloudID); err != nil {
+			return err
+		}
+		info, err := t.client.GetRoleCreationInfo(rootCtx, &input.CreateCloudAccountInput)
 		if err != nil {
 			return err
 		}
+		info.InlinePolicy = t.inlinePolicy
+		arn, externalID, err := t.cloud.CreateNewRole(rootCtx, info)
+		if arn != "" {
+			// a role created before a later step failed is deleted too
+			account, _, _ := parseRoleArn(arn)
+			tx.record(fmt.Sprintf(content.DeleteResourceRole, t.roleName, account),
+				awsRoleRecovery(t.roleName, t.policy, t.inlinePolicy, t.awsProfile),
+				func(ctx context.Context) error {
+					return t.cloud.DeleteRole(ctx, t.roleName)
+				})
+		}
+		if err != nil {
+			return tx.rollback(err)
+		}
 
 		input.RoleArn = arn
 		input.ExternalID = externalID
+		input.Fields = append(input.Fields, "arn", "externalId")
 	}
 
-	cloud, err := t.client.UpdateCloudAccount(input)
+	cloud, err := t.client.UpdateCloudAccount(rootCtx, input)
 	if err != nil {
-		if t.roleName != "" {
-			fmt.Println("Cloud account update failed! Will delete created role.")
-			t.cloud.DeleteRole(t.roleName)
+		return tx.rollback(err)
+	}
+	if t.roleName != "" && !t.isDraft {
+		revert := &client.UpdateCloudAccountInput{
+			CreateCloudAccountInput: client.CreateCloudAccountInput{RoleArn: current.Arn, ExternalID: current.ExternalID},
+			CloudID:                 t.cloudID,
+			Fields:                  []string{"arn", "externalId"},
+		}
+		tx.record(fmt.Sprintf(content.RollbackResourceAccountRole, t.cloudID),
+			[]string{fmt.Sprintf(content.RecoveryRevertAccountRole, t.cloudID, current.Arn, current.ExternalID)},
+			func(ctx context.Context) error {
+				_, err := t.client.UpdateCloudAccount(ctx, revert)
+				return err
+			})
+
+		// the new role can only be assumed once IAM has propagated it
+		res, err := waitUntilValid(t.client, t.cloudID)
+		if err == nil && !res.IsValid {
+			err = fmt.Errorf(content.ErrorRoleNotValid, propagationBackoff.Timeout, res.Message)
+		}
+		if err != nil {
+			return tx.rollback(err)
 		}
-		return err
 	}
 	util.PrintResult(
 		t.out,
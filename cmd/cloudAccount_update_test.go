@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudAccountUpdateCmd(t *testing.T) {
+	tests := []struct {
+		desc   string
+		flags  []string
+		fields []string
+		err    bool
+	}{
+		{"name only", []string{"--name", "web"}, []string{"name"}, false},
+		{"azure key rotation", []string{"--key-value", "key-2", "--application-id", "app-2"}, []string{"key", "appId"}, false},
+		{"scan settings", []string{"--scan-enabled=false", "--scan-interval", "Daily", "--scan-region", "us-west-2"}, []string{"scanEnabled", "scanInterval", "scanRegion"}, false},
+		{"draft and tags", []string{"--draft=false", "--tags", "", "--tag", "owner=team-x"}, []string{"isDraft", "tags"}, false},
+		{"nothing to update", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{}
+		cmd := newCloudUpdateCmd(frc, &bytes.Buffer{})
+		assert.Nil(t, cmd.ParseFlags(append([]string{"--cloud-id", "cloud-1"}, tt.flags...)), tt.desc)
+
+		err := cmd.RunE(cmd, nil)
+		if tt.err {
+			assert.NotNil(t, err, tt.desc)
+			assert.Nil(t, frc.updated, tt.desc)
+			continue
+		}
+		assert.Nil(t, err, tt.desc)
+		assert.Equal(t, tt.fields, frc.updated.Fields, tt.desc)
+		assert.Equal(t, "cloud-1", frc.updated.CloudID, tt.desc)
+	}
+}
+
+func TestCloudAccountUpdateDryRun(t *testing.T) {
+	frc := &fakeReleaseClient{}
+	var buf bytes.Buffer
+	cmd := newCloudUpdateCmd(frc, &buf)
+	assert.Nil(t, cmd.ParseFlags([]string{"--cloud-id", "cloud-1", "--name", "web", "--scan-enabled", "--dry-run"}))
+
+	assert.Nil(t, cmd.RunE(cmd, nil))
+	assert.Nil(t, frc.updated)
+	patch := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &patch))
+	assert.Equal(t, map[string]interface{}{"name": "web", "scanEnabled": true}, patch)
+}
+
+func TestCloudAccountUpdateDryRunRedactsKey(t *testing.T) {
+	frc := &fakeReleaseClient{}
+	var buf bytes.Buffer
+	cmd := newCloudUpdateCmd(frc, &buf)
+	assert.Nil(t, cmd.ParseFlags([]string{"--cloud-id", "cloud-1", "--key-value", "secret-1", "--dry-run"}))
+
+	assert.Nil(t, cmd.RunE(cmd, nil))
+	assert.NotContains(t, buf.String(), "secret-1")
+	assert.Contains(t, buf.String(), `"key": "REDACTED"`)
+}
+
+func TestCloudAccountUpdateTagsKeepCurrent(t *testing.T) {
+	frc := &fakeReleaseClient{cloudAccounts: []*client.CloudAccount{{CloudInfo: client.CloudInfo{Tags: client.Tags{"team:web"}}}}}
+	cmd := newCloudUpdateCmd(frc, &bytes.Buffer{})
+	assert.Nil(t, cmd.ParseFlags([]string{"--cloud-id", "cloud-1", "--tag", "owner=team-x"}))
+
+	assert.Nil(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, client.Tags{"team:web", "owner:team-x"}, frc.updated.TagList)
+}
+
+func TestCloudAccountUpdateRole(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{}
+	frc := &fakeReleaseClient{validationResult: client.RoleReValidationResult{IsValid: true}}
+	update := &cloudUpdateCmd{out: &bytes.Buffer{}, client: frc, cloud: &fakeCloudProvider{arn: "arn-1"}, cloudID: "cloud-1", roleName: "vss", fields: []string{}}
+
+	assert.Nil(t, update.run())
+	assert.Equal(t, []string{"arn", "externalId"}, frc.updated.Fields)
+	assert.Equal(t, "arn-1", frc.updated.Patch()["arn"])
+}
+
+func TestCloudAccountUpdateRoleRollback(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{}
+	frc := &fakeReleaseClient{
+		cloudAccounts:    []*client.CloudAccount{{ID: "cloud-1", CloudInfo: client.CloudInfo{Arn: "arn-old", ExternalID: "id-old"}}},
+		validationResult: client.RoleReValidationResult{Message: "can't assume role"},
+	}
+	provider := &fakeCloudProvider{arn: "arn-1"}
+	update := &cloudUpdateCmd{out: &bytes.Buffer{}, client: frc, cloud: provider, cloudID: "cloud-1", roleName: "vss", fields: []string{}}
+
+	assert.NotNil(t, update.run())
+	assert.Equal(t, map[string]interface{}{"arn": "arn-old", "externalId": "id-old"}, frc.updated.Patch())
+	assert.Equal(t, []string{"vss"}, provider.deleted)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/pkg/errors"
+)
+
+// defaultTestConcurrency is how many cloud accounts 'vss cloud test --all' tests at the same time
+const defaultTestConcurrency = 5
+
+// validationResult is the result of re-validating the role of a cloud account
+type validationResult struct {
+	CloudID             string `json:"cloudId"`
+	Name                string `json:"name"`
+	Provider            string `json:"provider"`
+	Status              string `json:"status"`
+	IsValid             bool   `json:"isValid"`
+	Message             string `json:"message"`
+	LastValidationCheck string `json:"lastValidationCheck"`
+
+	// err is why the role couldn't be re-validated
+	err error
+}
+
+// runAll re-validates the roles of all cloud accounts matching the filter, at
+// most concurrency at the same time. It fails with the first error of an
+// account that couldn't be tested, or when any of them is invalid.
+func (t *cloudTestCmd) runAll() error {
+	clouds, err := t.client.ListCloudAccounts(rootCtx, client.ListOptions{})
+	if err != nil {
+		return err
+	}
+	clouds = t.filter.apply(clouds)
+
+	results := make([]*validationResult, len(clouds))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < t.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = t.validate(clouds[i])
+			}
+		}()
+	}
+feed:
+	for i := range clouds {
+		select {
+		case indexes <- i:
+		case <-rootCtx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	invalid, failed := 0, 0
+	var firstErr error
+	rows := make([]interface{}, len(results))
+	for i, result := range results {
+		if result == nil {
+			// not started before the interrupt
+			result = newValidationResult(clouds[i])
+			result.fail(rootCtx.Err())
+			results[i] = result
+		}
+		switch {
+		case result.err != nil:
+			failed++
+			if firstErr == nil {
+				firstErr = result.err
+			}
+		case !result.IsValid:
+			invalid++
+		}
+		rows[i] = result
+	}
+
+	util.PrintResult(
+		t.out,
+		rows,
+		[]string{"CloudID", "Name", "Provider", "Status", "Message", "LastValidationCheck"},
+		map[string]string{
+			"CloudID":             "Cloud Account ID",
+			"Name":                "Cloud Account Name",
+			"Provider":            "Provider",
+			"Status":              "Status",
+			"Message":             "Message",
+			"LastValidationCheck": "Last Check",
+		},
+		jsonFormat,
+		verbose)
+
+	// an interrupted test exits as interrupted
+	if err := rootCtx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return errors.Wrapf(firstErr, content.ErrorCloudAccountsNotTested, failed, len(results))
+	}
+	if invalid > 0 {
+		return &util.ReportedError{Message: fmt.Sprintf(content.ErrorCloudAccountsInvalid, invalid, len(results))}
+	}
+	return nil
+}
+
+func newValidationResult(cloud *client.CloudAccount) *validationResult {
+	return &validationResult{
+		CloudID:             cloud.ID,
+		Name:                cloud.Name,
+		Provider:            cloud.Provider,
+		LastValidationCheck: cloud.LastValidationCheck,
+	}
+}
+
+// fail records that the role couldn't be re-validated, which doesn't tell whether it is valid
+func (r *validationResult) fail(err error) {
+	r.Status, r.Message, r.err = content.TestStatusError, err.Error(), err
+}
+
+// validate re-validates the role of the cloud account, a failed request is an error and not an invalid role
+func (t *cloudTestCmd) validate(cloud *client.CloudAccount) *validationResult {
+	result := newValidationResult(cloud)
+	if err := rootCtx.Err(); err != nil {
+		result.fail(err)
+		return result
+	}
+
+	res, err := t.client.ReValidateRole(rootCtx, cloud.ID)
+	if err != nil {
+		result.fail(err)
+		return result
+	}
+	result.IsValid, result.Message = res.IsValid, res.Message
+	result.Status = content.TestStatusInvalid
+	if res.IsValid {
+		result.Status = content.TestStatusValid
+	}
+	// the time of the check is only known from the account
+	if updated, err := t.client.ShowCloudAccountByID(rootCtx, cloud.ID); err == nil && updated.LastValidationCheck != "" {
+		result.LastValidationCheck = updated.LastValidationCheck
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func validationTestClient() *fakeReleaseClient {
+	return &fakeReleaseClient{
+		cloudAccounts: []*client.CloudAccount{
+			{ID: "1", CloudInfo: client.CloudInfo{Name: "prod-web", Provider: "AWS", Environment: "Production", LastValidationCheck: "2020-01-01"}},
+			{ID: "2", CloudInfo: client.CloudInfo{Name: "prod-data", Provider: "Azure", Environment: "Production"}},
+			{ID: "3", CloudInfo: client.CloudInfo{Name: "staging", Provider: "AWS", Environment: "Staging"}},
+		},
+		validationResult: client.RoleReValidationResult{IsValid: true, Message: "Role is valid"},
+		validationResults: map[string]client.RoleReValidationResult{
+			"2": {IsValid: false, Message: "Invalid credentials"},
+		},
+	}
+}
+
+func TestCloudAccountTestAll(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := newCloudTestCmd(validationTestClient(), &buf)
+	assert.Nil(t, cmd.ParseFlags([]string{"--all", "--concurrency", "2"}))
+
+	err := cmd.RunE(cmd, nil)
+	assert.IsType(t, &util.ReportedError{}, err)
+	assert.EqualError(t, err, "1 of 3 cloud accounts are invalid")
+	assert.Contains(t, buf.String(), "prod-web")
+	assert.Contains(t, buf.String(), "Invalid credentials")
+	assert.Contains(t, buf.String(), "2020-01-01")
+}
+
+func TestCloudAccountTestAllFilter(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := newCloudTestCmd(validationTestClient(), &buf)
+	assert.Nil(t, cmd.ParseFlags([]string{"--all", "--provider", "AWS"}))
+
+	assert.Nil(t, cmd.RunE(cmd, nil))
+	assert.Contains(t, buf.String(), "staging")
+	assert.NotContains(t, buf.String(), "prod-data")
+}
+
+func TestCloudAccountTestFlags(t *testing.T) {
+	for _, flags := range [][]string{
+		{"--all", "--cloud-id", "1"},
+		{"--all", "--concurrency", "0"},
+		{"--cloud-id", "1", "--provider", "AWS"},
+		{"--all", "--valid", "--invalid"},
+	} {
+		cmd := newCloudTestCmd(validationTestClient(), &bytes.Buffer{})
+		assert.Nil(t, cmd.ParseFlags(flags))
+		assert.NotNil(t, cmd.RunE(cmd, nil), flags)
+	}
+}
+
+func TestValidateRequestError(t *testing.T) {
+	frc := validationTestClient()
+	frc.err = assert.AnError
+	test := &cloudTestCmd{client: frc}
+
+	result := test.validate(frc.cloudAccounts[0])
+	assert.False(t, result.IsValid)
+	assert.Equal(t, "Error", result.Status)
+	assert.Equal(t, assert.AnError.Error(), result.Message)
+	assert.Equal(t, "2020-01-01", result.LastValidationCheck)
+}
+
+func TestCloudAccountTestAllRequestError(t *testing.T) {
+	frc := validationTestClient()
+	frc.cloudAccounts = frc.cloudAccounts[:1]
+	test := &cloudTestCmd{client: &failingValidationClient{frc}, out: &bytes.Buffer{}, concurrency: 1}
+
+	err := test.runAll()
+	assert.EqualError(t, err, "1 of 1 cloud accounts couldn't be tested: Unauthorized")
+	assert.Equal(t, exitCodeUnauthorized, exitCode(err))
+}
+
+func TestCloudAccountTestAllInterrupted(t *testing.T) {
+	defer func(ctx context.Context) { rootCtx = ctx }(rootCtx)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rootCtx = ctx
+
+	var buf bytes.Buffer
+	cmd := newCloudTestCmd(validationTestClient(), &buf)
+	assert.Nil(t, cmd.ParseFlags([]string{"--all"}))
+
+	err := cmd.RunE(cmd, nil)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, exitCodeInterrupted, exitCode(err))
+	assert.NotContains(t, buf.String(), "Invalid")
+	assert.Contains(t, buf.String(), "Error")
+}
+
+// failingValidationClient fails to re-validate roles as unauthorized
+type failingValidationClient struct {
+	*fakeReleaseClient
+}
+
+func (c *failingValidationClient) ReValidateRole(ctx context.Context, cloudID string) (*client.RoleReValidationResult, error) {
+	return nil, &client.APIError{StatusCode: http.StatusUnauthorized, Message: "Unauthorized"}
+}
@@ -30,6 +30,9 @@ type configureCmd struct {
 	out         io.Writer
 	client      command.Interface
 	compositeID string
+	cspEndpoint string
+	// settings holds the changed transport flags by profile key
+	settings map[string]string
 }
 
 func newConfigureCmd(out io.Writer) *cobra.Command {
@@ -44,6 +47,10 @@ func newConfigureCmd(out io.Writer) *cobra.Command {
 		Long:    content.CmdConfigureLong,
 		Example: content.CmdConfigureExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed(content.CmdFlagCSPEndpointLong) {
+				configure.cspEndpoint = cspEndpoint
+			}
+			configure.settings = changedTransportSettings(cmd)
 			return configure.run()
 		},
 	}
@@ -68,6 +75,10 @@ func (t *configureCmd) run() error {
 
 	// replace values in config
 	util.UpdateConfig(apiKey, userAPIkey)
+	util.UpdateConfig(fmt.Sprintf("%s.%s", userProfile, content.CSPEndpoint), t.cspEndpoint)
+	for profileKey, value := range t.settings {
+		util.UpdateConfig(fmt.Sprintf("%s.%s", userProfile, profileKey), value)
+	}
 
 	// save config
 	if err := util.SaveViperConfig(); err != nil {
@@ -76,6 +87,25 @@ func (t *configureCmd) run() error {
 	return nil
 }
 
+// changedTransportSettings returns the transport flags set on the command line by profile key.
+func changedTransportSettings(cmd *cobra.Command) map[string]string {
+	flags := map[string]string{
+		content.Proxy:         content.CmdFlagProxyLong,
+		content.NoProxy:       content.CmdFlagNoProxyLong,
+		content.CABundle:      content.CmdFlagCABundleLong,
+		content.ClientCert:    content.CmdFlagClientCertLong,
+		content.ClientKey:     content.CmdFlagClientKeyLong,
+		content.TLSMinVersion: content.CmdFlagTLSMinVersionLong,
+	}
+	settings := make(map[string]string)
+	for profileKey, flag := range flags {
+		if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+			settings[profileKey] = f.Value.String()
+		}
+	}
+	return settings
+}
+
 func getValueFromUser(userKey *string, prompt string) {
 	fmt.Print(prompt)
 	fmt.Scanln(userKey)
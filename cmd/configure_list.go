@@ -31,6 +31,7 @@ type Profile struct {
 	APIKey      string
 	SecretKey   string
 	TeamID      string
+	CSPEndpoint string
 }
 
 type configureListCmd struct {
@@ -73,6 +74,7 @@ func (t *configureListCmd) run() error {
 		profile := &Profile{
 			ProfileName: k,
 			APIKey:      util.GetValueFromConfig(apiKey, true),
+			CSPEndpoint: util.GetValueFromConfig(fmt.Sprintf("%s.%s", k, content.CSPEndpoint), false),
 		}
 
 		profiles = append(profiles, profile)
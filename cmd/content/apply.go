@@ -0,0 +1,70 @@
+package content
+
+//CmdPlanUse is the command for plan
+const CmdPlanUse = "plan"
+
+//CmdPlanShort is the short version description for vss plan command
+const CmdPlanShort = "Show the changes needed for the cloud accounts to match an inventory file"
+
+//CmdPlanLong is the long version description for vss plan command
+const CmdPlanLong = `Compare the cloud accounts listed in a YAML, JSON or CSV inventory file to the existing ones
+and show the accounts that 'vss apply' would add, update and, with --prune, delete.
+
+Accounts are matched by account ID, subscription ID or role ARN and then by name. The name, environment,
+tags and scan settings of matched accounts are compared, fields left out of the file are not managed.
+The provider of an account can't be changed. Event streams are set up for added accounts with eventStream set,
+the event stream of existing accounts isn't compared.`
+
+//CmdPlanExample is the use case for command plan
+const CmdPlanExample = `  vss plan -f inventory.yaml
+  vss plan -f inventory.yaml --prune`
+
+//CmdApplyUse is the command for apply
+const CmdApplyUse = "apply"
+
+//CmdApplyShort is the short version description for vss apply command
+const CmdApplyShort = "Add, update and delete cloud accounts to match an inventory file"
+
+//CmdApplyLong is the long version description for vss apply command
+const CmdApplyLong = `Add, update and, with --prune, delete cloud accounts to match a YAML, JSON or CSV inventory file.
+The changes are the ones shown by 'vss plan', applying the same file again makes no changes.`
+
+//CmdApplyExample is the use case for command apply
+const CmdApplyExample = `  vss apply -f inventory.yaml
+  vss apply -f inventory.yaml --prune`
+
+//CmdFlagInventoryFileDescription flag description
+const CmdFlagInventoryFileDescription = "YAML, JSON or CSV file listing the cloud accounts"
+
+//CmdFlagPrune flag
+const CmdFlagPrune = "prune"
+
+//CmdFlagPruneDescription flag description
+const CmdFlagPruneDescription = "Delete the cloud accounts that aren't listed in the file"
+
+//InfoPlanNoChanges info
+const InfoPlanNoChanges = "No changes, the cloud accounts match the inventory."
+
+//InfoPlanSummary info
+const InfoPlanSummary = "Plan: %d to add, %d to update, %d to delete.\n"
+
+//InfoPlanUnmanaged info
+const InfoPlanUnmanaged = "%d cloud accounts aren't listed in the inventory, use --prune to delete them.\n"
+
+//InfoApplyCreated info
+const InfoApplyCreated = "Added %s (%s)\n"
+
+//InfoApplyUpdated info
+const InfoApplyUpdated = "Updated %s (%s)\n"
+
+//InfoApplyDeleted info
+const InfoApplyDeleted = "Deleted %s (%s)\n"
+
+//InfoApplyEventStream info
+const InfoApplyEventStream = "Set up the event stream of %s (%s)\n"
+
+//InfoApplySummary info
+const InfoApplySummary = "Apply complete: %d added, %d updated, %d deleted.\n"
+
+//ErrorApplyFailed error
+const ErrorApplyFailed = "%s %s failed: %v"
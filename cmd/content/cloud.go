@@ -25,16 +25,38 @@ const (
 	CmdCloudLong = `Connect to your cloud accounts.`
 
 	//CmdCloudListShort short description
-	CmdCloudListShort = "Show list of tokens"
+	CmdCloudListShort = "Show list of cloud accounts"
 
 	//CmdCloudListLong long description
-	CmdCloudListLong = `Show list of tokens.`
+	CmdCloudListLong = `Show list of cloud accounts.
+
+Filters are combined, an account is listed when it passes all of them. --provider and --environment take
+comma separated values and --tag can be repeated. --sort-by and --columns take the field names of a cloud
+account as shown by 'vss cloud show --json' or as Go field names, such as ScanInterval or lastValidationCheck.
+The secrets key and serviceAccountKey can't be selected. --limit counts the accounts passing the filters.
+With --json, --columns selects the fields of the printed accounts.`
+
+	//CmdCloudListExample example
+	CmdCloudListExample = `  vss cloud list --provider Azure --environment Production
+  vss cloud list --tag team=security --tag owner --invalid
+  vss cloud list --name-regex '^prod-' --draft=false --sort-by -lastValidationCheck
+  vss cloud list --columns Name,Provider,ScanInterval,LastValidationCheck --json`
 
 	//CmdCloudTestShort short description
 	CmdCloudTestShort = "test role"
 
 	//CmdCloudTestLong long description
-	CmdCloudTestLong = "test whether role is valid"
+	CmdCloudTestLong = `test whether role is valid.
+
+With --all, the roles of all cloud accounts matching the filter flags are re-validated concurrently
+and a table of the results is printed. Accounts whose role couldn't be re-validated, e.g. because a
+request failed, have the status Error. The command exits with the first of those errors, otherwise with
+an error when any account is invalid.`
+
+	//CmdCloudTestExample example
+	CmdCloudTestExample = `  vss cloud test --cloud-id YOUR_CLOUD_ID
+  vss cloud test --all
+  vss cloud test --all --provider AWS --environment Production --concurrency 10`
 
 	//CmdCloudAddShort short description
 	CmdCloudAddShort = "Add a cloud account"
@@ -52,17 +74,82 @@ Add a cloud account. The result would be shown as the following if successful.
 	CmdCloudUpdateShort = "Update cloud account info"
 
 	//CmdCloudUpdateLong long description
-	CmdCloudUpdateLong = "Update cloud account info"
+	CmdCloudUpdateLong = `Update cloud account info.
+
+Only the settings whose flags are given are updated, all other settings of the account are kept.
+Flags can also clear a setting, e.g. --draft=false or --tags "". Use --dry-run to print the
+fields that would be sent without updating the account.`
+
+	//CmdCloudUpdateExample is the example of the update command
+	CmdCloudUpdateExample = `  vss cloud update --cloud-id YOUR_CLOUD_ID --name new-name
+  vss cloud update --cloud-id YOUR_CLOUD_ID --key-value NEW_AZURE_KEY
+  vss cloud update --cloud-id YOUR_CLOUD_ID --scan-enabled --scan-interval Daily --scan-region us-west-2 --dry-run`
+
+	//CmdCloudRotateShort short description
+	CmdCloudRotateShort = "Rotate the external ID or client secret of a cloud account"
+
+	//CmdCloudRotateLong long description
+	CmdCloudRotateLong = `Rotate the credentials of a cloud account without deleting and adding it again.
+
+For AWS, a new external ID is generated and trusted by the role of the account along with the current
+one. The account is updated with the new external ID and validated, then the role stops trusting the
+old one. For Azure, a new client secret of the application is created, the account is updated with it
+and validated, then the old client secret is removed. When the validation fails, the account is
+updated with its old credentials again and the new ones are removed. An Azure account is only rotated
+when its current client secret is known.`
+
+	//CmdCloudRotateExample example
+	CmdCloudRotateExample = `  vss cloud rotate --cloud-id YOUR_CLOUD_ID --aws-profile security
+  vss cloud rotate --cloud-id YOUR_AZURE_CLOUD_ID --auth-file azure.auth`
+
+	//CmdCloudPolicyShort short description
+	CmdCloudPolicyShort = "Show or update the least-privilege inline policy of AWS roles"
+
+	//CmdCloudPolicyLong long description
+	CmdCloudPolicyLong = `Show or update the least-privilege inline policy of the roles of AWS cloud accounts.
+
+The policy allows only the actions VSS calls, read from the catalog of the VSS configuration or from
+the catalog shipped with the CLI when the configuration has none. Roles get the policy, named
+VSSSecureStateReadOnly, when they are created with 'vss cloud add --inline-policy'. Run update when
+the catalog changes to put the current policy on an existing role, its other policies are kept.`
+
+	//CmdCloudPolicyExample example
+	CmdCloudPolicyExample = `  vss cloud policy show > vss-policy.json
+  vss cloud policy update --cloud-id YOUR_CLOUD_ID --dry-run
+  vss cloud policy update --cloud-id YOUR_CLOUD_ID --aws-profile security`
+
+	//CmdCloudPolicyShowShort short description
+	CmdCloudPolicyShowShort = "Print the inline policy document for review"
+
+	//CmdCloudPolicyUpdateShort short description
+	CmdCloudPolicyUpdateShort = "Put the current inline policy on the role of a cloud account"
 
 	//CmdCloudScanShort short description
 	CmdCloudScanShort = "Scan your root account and create skeletons"
 
 	//CmdCloudScanLong long description
-	CmdCloudScanLong = "Scan your root account, get organization and create skeletons for each account"
+	CmdCloudScanLong = `Scan your root account, get organization and create skeletons for each account.
+
+The active accounts of the AWS organization are listed with the credentials of its management account.
+For every member account, the cross-account role is assumed to create the role given with --role,
+which is then added as a cloud account named after the AWS account. The management account itself
+uses the given credentials directly. Accounts that are already added are skipped. Up to --concurrency
+accounts are added at the same time, each waiting until its new role can be assumed.
+
+Filter flags can be repeated or take comma separated values. Organizational units are matched by ID or
+name and include all accounts below them. Excluded accounts are skipped even when they are included.`
+
+	//CmdCloudScanExample example
+	CmdCloudScanExample = `  vss cloud scan --role securestate_role --aws-profile management
+  vss cloud scan --role securestate_role --include-ou Production --exclude-account 123456789012
+  vss cloud scan --role securestate_role --cross-account-role AdminRole --duration 900`
 
 	//CmdCloudAddExample ...
 	CmdCloudAddExample = `  vss cloud add --name YOUR_NEW_ACCOUNT_NAME --role NAME_FOR_NEW_ROLE
-  vss cloud add --name YOUR_NEW_ACCOUNT_NAME --arn YOUR_ROLE_ARN --external-id EXTERNAL_ID_OF_YOUR_ROLE`
+  vss cloud add --name YOUR_NEW_ACCOUNT_NAME --arn YOUR_ROLE_ARN --external-id EXTERNAL_ID_OF_YOUR_ROLE
+  vss cloud add --provider Azure --name YOUR_NEW_ACCOUNT_NAME --subscription-id YOUR_SUBSCRIPTION_ID --role NAME_FOR_NEW_APPLICATION
+  vss cloud add --provider GCP --name YOUR_NEW_ACCOUNT_NAME --project-id YOUR_PROJECT_ID --role NAME_FOR_NEW_SERVICE_ACCOUNT
+  vss cloud add --provider GCP --name YOUR_NEW_ACCOUNT_NAME --project-id YOUR_PROJECT_ID --service-account-key KEY_FILE`
 
 	//CmdCloudShowShort short description
 	CmdCloudShowShort = "Show a cloud account"
@@ -74,7 +161,17 @@ Add a cloud account. The result would be shown as the following if successful.
 	CmdCloudDeleteShort = "Delete a cloud account"
 
 	//CmdCloudDeleteLong long desription
-	CmdCloudDeleteLong = `Delete a cloud account.`
+	CmdCloudDeleteLong = `Delete a cloud account.
+
+With --purge, the event stream of the account is removed first, then its role (the service principal
+for Azure and the service account for GCP), and the account itself last. Each step is reported and
+the steps after a failed one are skipped, so that the command can be run again. The resources to
+remove are listed and confirmed interactively unless --yes is given. --dry-run only lists them.`
+
+	//CmdCloudDeleteExample example
+	CmdCloudDeleteExample = `  vss cloud delete --cloud-id YOUR_CLOUD_ID
+  vss cloud delete --cloud-id YOUR_CLOUD_ID --purge --dry-run
+  vss cloud delete --cloud-id YOUR_CLOUD_ID --purge --yes --aws-profile security`
 
 	//CmdFlagCloudIDLong flag
 	CmdFlagCloudIDLong = "cloud-id"
@@ -100,6 +197,36 @@ Add a cloud account. The result would be shown as the following if successful.
 	//CmdFlagRoleSessionNameDescription is the description of flag roleSessionName
 	CmdFlagRoleSessionNameDescription = "The session name to assume the role"
 
+	//CmdFlagCrossAccountRole is the role assumed in member accounts for cloud scan command
+	CmdFlagCrossAccountRole = "cross-account-role"
+
+	//CmdFlagCrossAccountRoleDescription is the description of flag cross-account-role
+	CmdFlagCrossAccountRoleDescription = "The role assumed in the member accounts of the organization"
+
+	//CmdFlagIncludeOU flag
+	CmdFlagIncludeOU = "include-ou"
+
+	//CmdFlagIncludeOUDescription flag description
+	CmdFlagIncludeOUDescription = "Only add the accounts in these organizational units"
+
+	//CmdFlagExcludeOU flag
+	CmdFlagExcludeOU = "exclude-ou"
+
+	//CmdFlagExcludeOUDescription flag description
+	CmdFlagExcludeOUDescription = "Skip the accounts in these organizational units"
+
+	//CmdFlagIncludeAccount flag
+	CmdFlagIncludeAccount = "include-account"
+
+	//CmdFlagIncludeAccountDescription flag description
+	CmdFlagIncludeAccountDescription = "Only add the accounts with these AWS account IDs"
+
+	//CmdFlagExcludeAccount flag
+	CmdFlagExcludeAccount = "exclude-account"
+
+	//CmdFlagExcludeAccountDescription flag description
+	CmdFlagExcludeAccountDescription = "Skip the accounts with these AWS account IDs"
+
 	//CmdFlagIgnoreMissingTrails will make CLI skip on current region of which cloudTrail is not enabled and go on.
 	CmdFlagIgnoreMissingTrails = "ignore-missing-trails"
 
@@ -107,7 +234,7 @@ Add a cloud account. The result would be shown as the following if successful.
 	CmdFlagIgnoreMissingTrailsDescription = "CLI will continue on event steam setup even if CloudTrail is not enabled in all regions"
 
 	//CmdFlagDuration is the duration of session keys for cloud scan command
-	CmdFlagDuration = "duartion"
+	CmdFlagDuration = "duration"
 
 	//CmdFlagDurationDescription describes the flag duration
 	CmdFlagDurationDescription = "The duration for session in seconds"
@@ -117,7 +244,8 @@ Add a cloud account. The result would be shown as the following if successful.
 
 	//CmdFlagAwsProfileDescription ...
 	CmdFlagAwsProfileDescription = "Aws shared credential file. If empty default provider chain will be used to look for credentials with the following order.\n" +
-		"  1. Environment variables.\n" + "  2. Shared credentials file.\n" + "  3. If your application is running on an Amazon EC2 instance, IAM role for Amazon EC2."
+		"  1. Environment variables.\n" + "  2. Shared credentials file.\n" + "  3. If your application is running on an Amazon EC2 instance, IAM role for Amazon EC2.\n" +
+		"  The profile may be an AWS SSO profile, run a credential_process or assume a role with an MFA token or a web identity token."
 
 	//CmdFlagAwsProfilePath  ...
 	CmdFlagAwsProfilePath = "aws-profile-path"
@@ -126,6 +254,36 @@ Add a cloud account. The result would be shown as the following if successful.
 	CmdFlagAwsProfilePathDescription = "The file path of aws profile. If empty will look for AWS_SHARED_CREDENTIALS_FILE env variable. " +
 		"If the env value is empty will default to current user's home directory.\n" + "  Linux/OSX: \"$HOME/.aws/credentials\"\n" + "  Windows:   \"%USERPROFILE%\\.aws\\credentials\""
 
+	//CmdFlagAssumeRoleArn is the flag for the role assumed with the AWS credentials
+	CmdFlagAssumeRoleArn = "assume-role-arn"
+
+	//CmdFlagAssumeRoleArnDescription describes flag assume-role-arn
+	CmdFlagAssumeRoleArnDescription = "The arn of a role assumed with the AWS credentials, e.g. to act in a member account from a central identity account"
+
+	//CmdFlagAssumeRoleExternalID is the flag for the external ID of the role of --assume-role-arn, --external-id is the one of the VSS role
+	CmdFlagAssumeRoleExternalID = "assume-role-external-id"
+
+	//CmdFlagAssumeRoleExternalIDDescription describes flag assume-role-external-id
+	CmdFlagAssumeRoleExternalIDDescription = "The external-id used to assume the role of --assume-role-arn"
+
+	//CmdFlagAssumeRoleSessionNameDescription describes flag role-session of the role of --assume-role-arn
+	CmdFlagAssumeRoleSessionNameDescription = "The session name to assume the role of --assume-role-arn"
+
+	//CmdFlagAssumeRoleDurationDescription describes flag duration of the role of --assume-role-arn
+	CmdFlagAssumeRoleDurationDescription = "The duration for the session of the role of --assume-role-arn in seconds, 15 minutes by default"
+
+	//CmdFlagMFASerial is the flag for the MFA device of the role of --assume-role-arn
+	CmdFlagMFASerial = "mfa-serial"
+
+	//CmdFlagMFASerialDescription describes flag mfa-serial
+	CmdFlagMFASerialDescription = "The arn of the MFA device whose token is asked for to assume the role of --assume-role-arn"
+
+	//CmdFlagWebIdentityTokenFile is the flag for the web identity token the role of --assume-role-arn is assumed with
+	CmdFlagWebIdentityTokenFile = "web-identity-token-file"
+
+	//CmdFlagWebIdentityTokenFileDescription describes flag web-identity-token-file
+	CmdFlagWebIdentityTokenFileDescription = "The file of an OIDC token, e.g. of a CI job, the role of --assume-role-arn is assumed with instead of the AWS profile"
+
 	//CmdFlagAwsPolicy is the flag for policy
 	CmdFlagAwsPolicy = "policy-arn"
 
@@ -160,7 +318,7 @@ Add a cloud account. The result would be shown as the following if successful.
 	CmdFlagProvider = "provider"
 
 	//CmdFlagProviderDescription describes the usage of provider flag
-	CmdFlagProviderDescription = "Your cloud account provider, AWS or Azure"
+	CmdFlagProviderDescription = "Your cloud account provider, AWS, Azure or GCP"
 
 	//CmdFlagEmail is the owner email when adding cloud accounts, optional
 	CmdFlagEmail = "email"
@@ -195,6 +353,21 @@ Add a cloud account. The result would be shown as the following if successful.
 	//CmdFlagDeleteRoleDescription describes flag --role
 	CmdFLagDeleteRoleDescription = "Use this flag to delete the role while deleting a cloud account"
 
+	//CmdFlagPurge is the flag for removing all cloud resources of a deleted cloud account
+	CmdFlagPurge = "purge"
+
+	//CmdFlagPurgeDescription describes flag --purge
+	CmdFlagPurgeDescription = "Remove the event stream and the role of the cloud account before deleting it"
+
+	//CmdFlagYes is the flag for skipping the confirmation
+	CmdFlagYes = "yes"
+
+	//CmdFlagYesDescription describes flag --yes
+	CmdFlagYesDescription = "Don't ask for confirmation"
+
+	//CmdFlagDeleteDryRunDescription is the description for flag --dry-run of the delete command
+	CmdFlagDeleteDryRunDescription = "List the resources that would be removed without removing them"
+
 	//CmdFlagKeyValue is the flag for key value
 	CmdFlagKeyValue = "key-value"
 
@@ -219,7 +392,479 @@ Add a cloud account. The result would be shown as the following if successful.
 	//CmdFlagSubscriptionID is the description for flag --subscription-id
 	CmdFlagSubscriptionIDDescription = "Subscription ID required for adding Azure cloud accounts"
 
+	//CmdFlagProjectID is the flag for project ID
+	CmdFlagProjectID = "project-id"
+
+	//CmdFlagProjectIDDescription is the description for flag --project-id
+	CmdFlagProjectIDDescription = "Project ID required for adding GCP cloud accounts"
+
+	//CmdFlagServiceAccountEmail is the flag for service account email
+	CmdFlagServiceAccountEmail = "service-account-email"
+
+	//CmdFlagServiceAccountEmailDescription is the description for flag --service-account-email
+	CmdFlagServiceAccountEmailDescription = "Email of the existing service account VSS reads the GCP project with, taken from its key by default"
+
+	//CmdFlagServiceAccountKey is the flag for service account key
+	CmdFlagServiceAccountKey = "service-account-key"
+
+	//CmdFlagServiceAccountKeyDescription is the description for flag --service-account-key
+	CmdFlagServiceAccountKeyDescription = "JSON key file of the existing service account VSS reads the GCP project with"
+
+	//CmdFlagWorkloadIdentityProvider is the flag for workload identity provider
+	CmdFlagWorkloadIdentityProvider = "workload-identity-provider"
+
+	//CmdFlagWorkloadIdentityProviderDescription is the description for flag --workload-identity-provider
+	CmdFlagWorkloadIdentityProviderDescription = "Resource name of the workload identity pool provider VSS impersonates the service account with instead of a key, " +
+		"projects/NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER"
+
+	//CmdFlagWorkloadIdentitySubject is the flag for the workload identity allowed to impersonate a new service account
+	CmdFlagWorkloadIdentitySubject = "workload-identity-subject"
+
+	//CmdFlagWorkloadIdentitySubjectDescription is the description for flag --workload-identity-subject
+	CmdFlagWorkloadIdentitySubjectDescription = "Subject of the identity of the workload identity pool allowed to impersonate the service account created with --role, " +
+		"or attribute.NAME/VALUE to allow the identities with that attribute"
+
+	//CmdFlagGCPRoles is the flag for the roles granted to new GCP service accounts
+	CmdFlagGCPRoles = "gcp-roles"
+
+	//CmdFlagGCPRolesDescription is the description for flag --gcp-roles
+	CmdFlagGCPRolesDescription = "Comma separated roles granted on the project to the new GCP service account"
+
+	//CmdFlagGCPCredentials is the flag for GCP credentials
+	CmdFlagGCPCredentials = "gcp-credentials"
+
+	//CmdFlagGCPCredentialsDescription is the description for flag --gcp-credentials
+	CmdFlagGCPCredentialsDescription = "Service account key or authorized user file to call GCP with. If empty GOOGLE_APPLICATION_CREDENTIALS " +
+		"and then the gcloud application default credentials are used"
+
+	//CmdFlagListProviderDescription is the description for flag --provider of list commands
+	CmdFlagListProviderDescription = "List the cloud accounts of these providers: AWS, Azure or GCP"
+
+	//CmdFlagListEnvironmentDescription is the description for flag --environment of list commands
+	CmdFlagListEnvironmentDescription = "List the cloud accounts of these environments: Production, Staging, Development or Test"
+
+	//CmdFlagTagLong is the flag for a key=value tag
+	CmdFlagTagLong = "tag"
+
+	//CmdFlagListTagDescription is the description for flag --tag of list commands
+	CmdFlagListTagDescription = "List the cloud accounts with the tag key or key=value, can be repeated"
+
+	//CmdFlagValidLong is the flag for valid cloud accounts
+	CmdFlagValidLong = "valid"
+
+	//CmdFlagValidDescription is the description for flag --valid
+	CmdFlagValidDescription = "List the cloud accounts whose role is valid"
+
+	//CmdFlagInvalidLong is the flag for invalid cloud accounts
+	CmdFlagInvalidLong = "invalid"
+
+	//CmdFlagInvalidDescription is the description for flag --invalid
+	CmdFlagInvalidDescription = "List the cloud accounts whose role is invalid"
+
+	//CmdFlagListDraftDescription is the description for flag --draft of list commands
+	CmdFlagListDraftDescription = "List the draft cloud accounts, --draft=false lists the others"
+
+	//CmdFlagListNameDescription is the description for flag --name of list commands
+	CmdFlagListNameDescription = "List the cloud accounts whose name contains this text, case is ignored"
+
+	//CmdFlagNameRegexLong is the flag for a name regular expression
+	CmdFlagNameRegexLong = "name-regex"
+
+	//CmdFlagNameRegexDescription is the description for flag --name-regex
+	CmdFlagNameRegexDescription = "List the cloud accounts whose name matches this regular expression"
+
+	//CmdFlagSortByLong is the flag for the sort field
+	CmdFlagSortByLong = "sort-by"
+
+	//CmdFlagSortByDescription is the description for flag --sort-by
+	CmdFlagSortByDescription = "Field to sort the cloud accounts by, a leading - sorts in descending order"
+
+	//CmdFlagColumnsLong is the flag for the printed fields
+	CmdFlagColumnsLong = "columns"
+
+	//CmdFlagColumnsDescription is the description for flag --columns
+	CmdFlagColumnsDescription = "Comma separated fields to print"
+
+	//CmdFlagAllLong is the flag for testing all cloud accounts
+	CmdFlagAllLong = "all"
+
+	//CmdFlagTestAllDescription is the description for flag --all of the test command
+	CmdFlagTestAllDescription = "Test the roles of all cloud accounts matching the filter flags"
+
+	//CmdFlagConcurrencyLong is the flag for the number of concurrent requests
+	CmdFlagConcurrencyLong = "concurrency"
+
+	//CmdFlagConcurrencyDescription is the description for flag --concurrency
+	CmdFlagConcurrencyDescription = "Number of cloud accounts tested at the same time"
+
+	//CmdFlagScanConcurrencyDescription is the description for flag --concurrency of cloud scan
+	CmdFlagScanConcurrencyDescription = "Number of accounts added at the same time"
+
+	//CmdFlagScanEnabled is the flag for enabling scans
+	CmdFlagScanEnabled = "scan-enabled"
+
+	//CmdFlagScanEnabledDescription is the description for flag --scan-enabled
+	CmdFlagScanEnabledDescription = "Whether the cloud account is scanned, use --scan-enabled=false to disable scans"
+
+	//CmdFlagScanInterval is the flag for the scan interval
+	CmdFlagScanInterval = "scan-interval"
+
+	//CmdFlagScanIntervalDescription is the description for flag --scan-interval
+	CmdFlagScanIntervalDescription = "How often the cloud account is scanned, e.g. Daily or Weekly"
+
+	//CmdFlagScanRegion is the flag for the scanned region
+	CmdFlagScanRegion = "scan-region"
+
+	//CmdFlagScanRegionDescription is the description for flag --scan-region
+	CmdFlagScanRegionDescription = "Region of the cloud account to scan, All scans every region"
+
+	//CmdFlagDryRun is the flag for printing changes without making them
+	CmdFlagDryRun = "dry-run"
+
+	//CmdFlagUpdateDryRunDescription is the description for flag --dry-run of the update command
+	CmdFlagUpdateDryRunDescription = "Print the JSON patch of the updated fields without updating the cloud account"
+
+	//CmdFlagPolicyDryRunDescription is the description for flag --dry-run of the policy update command
+	CmdFlagPolicyDryRunDescription = "Print the inline policy document without putting it on the role"
+
+	//CmdFlagInlinePolicy is the flag for creating roles with the inline policy
+	CmdFlagInlinePolicy = "inline-policy"
+
+	//CmdFlagInlinePolicyDescription is the description for flag --inline-policy
+	CmdFlagInlinePolicyDescription = "Put the least-privilege inline policy of the VSS policy catalog on the new AWS role in place of attaching --policy-arn"
+
+	//CmdFlagAzureRoles is the flag for the roles assigned to new Azure service principals
+	CmdFlagAzureRoles = "azure-roles"
+
+	//CmdFlagAzureRolesDescription is the description for flag --azure-roles
+	CmdFlagAzureRolesDescription = "Comma separated names of the built-in or custom roles assigned on the subscription to the new Azure service principal"
+
+	//CmdFlagAzureAuthFileDescription is the description for flag --auth-file of cloud add and delete
+	CmdFlagAzureAuthFileDescription = "Auth file of the service principal to call Azure with. If empty the AZURE_CLIENT_ID, AZURE_CLIENT_SECRET and " +
+		"AZURE_TENANT_ID environment variables and then the Azure CLI login are used"
+
 	CmdFlagTags = "tags"
 
 	CmdFlagTagsDescription = "Set tags for account"
+
+	//CmdFlagTagDescription is the description for flag --tag of add, update and tag commands
+	CmdFlagTagDescription = "Tag of the cloud account as key=value or only a key, can be repeated"
+
+	//CmdCloudTagShort short description
+	CmdCloudTagShort = "Manage the tags of a cloud account"
+
+	//CmdCloudTagLong long description
+	CmdCloudTagLong = `Manage the key/value tags of a cloud account.
+
+Tags are given as key=value, or only as a key, with the repeatable --tag flag. The current tags of the
+account are read and written back updated, the other settings of the account are kept. A tag with a key
+that already exists replaces its value.`
+
+	//CmdCloudTagExample example
+	CmdCloudTagExample = `  vss cloud tag add --cloud-id YOUR_CLOUD_ID --tag owner=team-x --tag critical
+  vss cloud tag remove --cloud-id YOUR_CLOUD_ID --tag owner
+  vss cloud tag set --cloud-id YOUR_CLOUD_ID --tag owner=team-y`
+
+	//CmdCloudTagAddShort short description
+	CmdCloudTagAddShort = "Add tags to a cloud account or change their values"
+
+	//CmdCloudTagRemoveShort short description
+	CmdCloudTagRemoveShort = "Remove the tags with the keys, or with the key=value, from a cloud account"
+
+	//CmdCloudTagSetShort short description
+	CmdCloudTagSetShort = "Replace all tags of a cloud account, no --tag removes them all"
+
+	//CmdCloudImportShort short description
+	CmdCloudImportShort = "Add cloud accounts listed in a CSV, YAML or JSON file"
+
+	//CmdCloudImportLong long description
+	CmdCloudImportLong = `Add the cloud accounts listed in a CSV, YAML or JSON file, creating AWS roles as with 'vss cloud add'.
+Accounts that already exist by name or account ID are skipped. A result is printed for every account
+and the command fails when any account could not be added.
+
+CSV files have a header row naming the columns, which are the YAML fields of an account:
+name, provider, accountId, environment, tags (separated by |), draft, email, username, eventStream,
+scanEnabled, scanInterval, scanRegion, roleName, roleArn, externalId, policy, awsProfile, awsProfilePath, key, applicationId, directoryId,
+subscriptionId, authFile, region, projectId, serviceAccountEmail, serviceAccountKey, workloadIdentityProvider, workloadIdentitySubject and gcpCredentials.
+Azure and GCP accounts take the comma separated roles of the new service principal or service account in policy.`
+
+	//CmdCloudImportExample example
+	CmdCloudImportExample = `  vss cloud import -f accounts.csv
+  vss cloud import -f accounts.yaml --setup-events --ignore-missing-trails`
+
+	//CmdFlagImportFileDescription flag description
+	CmdFlagImportFileDescription = "CSV, YAML or JSON file listing the cloud accounts to add"
+
+	//CmdFlagSetupEvents flag
+	CmdFlagSetupEvents = "setup-events"
+
+	//CmdFlagSetupEventsDescription flag description
+	CmdFlagSetupEventsDescription = "Set up the event stream of every account of the file, including the ones that already exist; also done for accounts with eventStream set in the file"
+
+	//ErrorFileRequired error
+	ErrorFileRequired = "A file is required for this command. Use flag '--file'"
+
+	//ErrorImportFailed error
+	ErrorImportFailed = "%d of %d cloud accounts failed to import"
+
+	//ErrorNameRequired error
+	ErrorNameRequired = "name is required"
+
+	//ImportStatusCreated import status
+	ImportStatusCreated = "Created"
+
+	//ImportStatusSkipped import status
+	ImportStatusSkipped = "Skipped"
+
+	//ImportStatusFailed import status
+	ImportStatusFailed = "Failed"
+
+	//ImportStatusEventStreamFailed import status
+	ImportStatusEventStreamFailed = "Added, event stream failed"
+
+	//InfoAccountExists info
+	InfoAccountExists = "Cloud account already exists"
+
+	//InfoAccountExistsEventStreamSetUp info
+	InfoAccountExistsEventStreamSetUp = "Cloud account already exists, event stream set up"
+
+	//InfoEventStreamSetUp info
+	InfoEventStreamSetUp = "Event stream set up"
+
+	//ErrorEventStreamSetup error
+	ErrorEventStreamSetup = "Cloud account added but event stream setup failed: %v"
+
+	//ErrorExistingEventStreamSetup error
+	ErrorExistingEventStreamSetup = "Cloud account already exists but event stream setup failed: %v"
+
+	//CmdCloudExportShort short description
+	CmdCloudExportShort = "Export the cloud accounts as a YAML or JSON inventory file"
+
+	//CmdCloudExportLong long description
+	CmdCloudExportLong = `Export the definitions of the cloud accounts as a versioned YAML or JSON inventory document,
+which can be used with 'vss cloud import' and 'vss apply'. Fields managed by the server such as IDs
+and validation results are left out, Azure keys and GCP service account keys are redacted and have to be added again before
+importing the accounts into another organization.`
+
+	//CmdCloudExportExample example
+	CmdCloudExportExample = `  vss cloud export -o inventory.yaml
+  vss cloud export --format json > inventory.json`
+
+	//CmdFlagOutputLong flag
+	CmdFlagOutputLong = "output"
+
+	//CmdFlagOutputShort flag
+	CmdFlagOutputShort = "o"
+
+	//CmdFlagOutputDescription flag description
+	CmdFlagOutputDescription = "File to write to instead of the standard output"
+
+	//CmdFlagFormat flag
+	CmdFlagFormat = "format"
+
+	//CmdFlagFormatDescription flag description
+	CmdFlagFormatDescription = "Format of the document, yaml or json. json when --json is set"
+
+	//ErrorExportFormat error
+	ErrorExportFormat = "Unsupported format %s, expected yaml or json"
+
+	//InfoExported info
+	InfoExported = "Exported %d cloud accounts to %s\n"
+
+	//ErrorRoleNameRequired error
+	ErrorRoleNameRequired = "The name of the role to create is required for this command. Use flag '--role'"
+
+	//ErrorTagRequired error
+	ErrorTagRequired = "A tag is required for this command. Use flag '--tag'"
+
+	//ErrorInvalidTag error
+	ErrorInvalidTag = "Invalid tag %q, expected key=value or a key without ':'"
+
+	//ErrorValidAndInvalid error
+	ErrorValidAndInvalid = "Use either --valid or --invalid"
+
+	//ErrorNameRegex error
+	ErrorNameRegex = "Invalid --name-regex: %v"
+
+	//ErrorUnknownField error
+	ErrorUnknownField = "Unknown cloud account field %s, expected one of %s"
+
+	//ErrorServicePrincipalNotSupported error
+	ErrorServicePrincipalNotSupported = "The cloud provider can't create Azure service principals"
+
+	//ErrorServiceAccountNotSupported error
+	ErrorServiceAccountNotSupported = "The cloud provider can't create GCP service accounts"
+
+	//ErrorNothingToUpdate error
+	ErrorNothingToUpdate = "No setting to update is given, use the flags of the settings to change"
+
+	//ErrorCloudIDAndAll error
+	ErrorCloudIDAndAll = "Use either --cloud-id or --all"
+
+	//ErrorFilterRequiresAll error
+	ErrorFilterRequiresAll = "Filter flags can only be used with --all"
+
+	//ErrorConcurrency error
+	ErrorConcurrency = "--concurrency must be at least 1"
+
+	//ErrorCloudAccountsInvalid error
+	ErrorCloudAccountsInvalid = "%d of %d cloud accounts are invalid"
+
+	//ErrorCloudAccountsNotTested error
+	ErrorCloudAccountsNotTested = "%d of %d cloud accounts couldn't be tested"
+
+	//TestStatusValid test status
+	TestStatusValid = "Valid"
+
+	//TestStatusInvalid test status
+	TestStatusInvalid = "Invalid"
+
+	//TestStatusError test status of an account whose role couldn't be re-validated
+	TestStatusError = "Error"
+
+	//ErrorDeleteAborted error
+	ErrorDeleteAborted = "Deletion aborted"
+
+	//ErrorDeleteStepFailed error
+	ErrorDeleteStepFailed = "Step %q failed, the following steps were skipped"
+
+	//ErrorRoleArn error
+	ErrorRoleArn = "Can't get the role name from arn %q"
+
+	//ErrorNoEventRegions error
+	ErrorNoEventRegions = "No regions returned"
+
+	//InfoDeleteConfirm info
+	InfoDeleteConfirm = "Remove these resources? [y/N]: "
+
+	//DeleteStepEventStream delete step
+	DeleteStepEventStream = "Remove event stream"
+
+	//DeleteStepRole delete step
+	DeleteStepRole = "Delete role"
+
+	//DeleteStepCloudAccount delete step
+	DeleteStepCloudAccount = "Delete cloud account"
+
+	//DeleteStatusDone delete status
+	DeleteStatusDone = "Done"
+
+	//DeleteStatusFailed delete status
+	DeleteStatusFailed = "Failed"
+
+	//DeleteStatusSkipped delete status
+	DeleteStatusSkipped = "Skipped"
+
+	//DeleteResourceRole deleted resource
+	DeleteResourceRole = "IAM role %s in AWS account %s"
+
+	//DeleteResourceServicePrincipal deleted resource
+	DeleteResourceServicePrincipal = "Azure application and service principal %s with its role assignments"
+
+	//DeleteResourceServiceAccount deleted resource
+	DeleteResourceServiceAccount = "GCP service account %s"
+
+	//DeleteResourceStack deleted resource
+	DeleteResourceStack = "CloudFormation stack %s in %s"
+
+	//DeleteResourceGroup deleted resource
+	DeleteResourceGroup = "Resource group %s in subscription %s"
+
+	//DeleteResourceSubscription deleted resource
+	DeleteResourceSubscription = "Pub/Sub subscription %s/subscriptions/%s"
+
+	//DeleteResourceSink deleted resource
+	DeleteResourceSink = "Log sink %s/sinks/%s"
+
+	//DeleteResourceTopic deleted resource
+	DeleteResourceTopic = "Pub/Sub topic %s/topics/%s"
+
+	//ErrorRotateNotSupported error
+	ErrorRotateNotSupported = "Rotating the credentials of %s cloud accounts isn't supported"
+
+	//ErrorExternalIDUnknown error
+	ErrorExternalIDUnknown = "The external ID of the cloud account is unknown"
+
+	//ErrorApplicationIDUnknown error
+	ErrorApplicationIDUnknown = "The application ID of the cloud account is unknown"
+
+	//ErrorRotationInvalid error
+	ErrorRotationInvalid = "The cloud account isn't valid with the new credentials, %s. The old credentials are kept"
+
+	//ErrorClientSecretUnknown error
+	ErrorClientSecretUnknown = "The client secret of the cloud account is unknown, so it couldn't be restored when the account " +
+		"isn't valid with a new one. Update the account with its current client secret using --key-value first"
+
+	//ErrorOldExternalIDTrusted error
+	ErrorOldExternalIDTrusted = "The external ID was rotated but the role still trusts the old one, %v"
+
+	//ErrorInlinePolicyNotSupported error
+	ErrorInlinePolicyNotSupported = "Inline policies are only supported for AWS roles, not for %s cloud accounts"
+
+	//PolicyStatusUpdated policy status
+	PolicyStatusUpdated = "Updated"
+
+	//PolicyStatusUnchanged policy status
+	PolicyStatusUnchanged = "Unchanged"
+
+	//ErrorRoleNotValid error
+	ErrorRoleNotValid = "The cloud account isn't valid with the new role after %v, %s"
+
+	//ErrorRollbackFailed error
+	ErrorRollbackFailed = "%v\nThese resources couldn't be removed again:\n  %s\nRemove them with:\n  %s"
+
+	//RollbackResourceAccount rolled back resource
+	RollbackResourceAccount = "Cloud account %s"
+
+	//RollbackResourceAccountRole rolled back resource
+	RollbackResourceAccountRole = "Role arn and external ID of cloud account %s"
+
+	//RollbackResourceAccountCredentials rolled back resource
+	RollbackResourceAccountCredentials = "New credentials of cloud account %s"
+
+	//RollbackResourceTrustedExternalID rolled back resource
+	RollbackResourceTrustedExternalID = "New external ID %s trusted by IAM role %s"
+
+	//RollbackResourceClientSecret rolled back resource
+	RollbackResourceClientSecret = "New client secret %s of Azure application %s"
+
+	//RecoveryAWSDetachPolicy recovery command
+	RecoveryAWSDetachPolicy = "aws iam detach-role-policy --role-name %s --policy-arn %s"
+
+	//RecoveryAWSDeleteInlinePolicy recovery command
+	RecoveryAWSDeleteInlinePolicy = "aws iam delete-role-policy --role-name %s --policy-name %s"
+
+	//RecoveryAWSDeleteRole recovery command
+	RecoveryAWSDeleteRole = "aws iam delete-role --role-name %s"
+
+	//RecoveryAzureDeleteApplication recovery command
+	RecoveryAzureDeleteApplication = "az ad app delete --id %s"
+
+	//RecoveryGCPDeleteServiceAccount recovery command
+	RecoveryGCPDeleteServiceAccount = "gcloud iam service-accounts delete %s --project %s --quiet"
+
+	//RecoveryDeleteAccount recovery command
+	RecoveryDeleteAccount = "vss cloud delete --cloud-id %s"
+
+	//RecoveryRevertAccountExternalID recovery command
+	RecoveryRevertAccountExternalID = "vss cloud update --cloud-id %s --external-id '%s'"
+
+	//RecoveryRevertAccountSecret recovery command
+	RecoveryRevertAccountSecret = "vss cloud update --cloud-id %s --key-value YOUR_OLD_CLIENT_SECRET"
+
+	//RecoveryAWSTrustedExternalID recovery command
+	RecoveryAWSTrustedExternalID = "aws iam update-assume-role-policy --role-name %s --policy-document file://TRUST_POLICY_WITHOUT_%s.json"
+
+	//RecoveryAzureDeleteSecret recovery command
+	RecoveryAzureDeleteSecret = "az ad app credential delete --id %s --key-id %s"
+
+	//RecoveryRevertAccountRole recovery command
+	RecoveryRevertAccountRole = "vss cloud update --cloud-id %s --arn '%s' --external-id '%s'"
+
+
+	//InfoCredentialsRotated info
+	InfoCredentialsRotated = "Credentials rotated"
+
+	//InfoScanSummary info
+	InfoScanSummary = "%d cloud accounts added, %d skipped, %d failed\n"
 )
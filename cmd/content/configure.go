@@ -38,6 +38,8 @@ Secret Access Key will  be  written  to  the  shared  credentials  file
 	//CmdConfigureExample is examples for vss configure command
 	CmdConfigureExample = `  vss configure
   vss configure --api-key VSS_API_KEY --api-secret VSS_API_SECRET --team-id VSS_TEAM_ID
+  vss configure --profile staging --csp-endpoint https://console-stg.cloud.vmware.com
+  vss configure --proxy http://proxy.corp:3128 --no-proxy .corp --ca-bundle /etc/ssl/corp-ca.pem
   vss configure list`
 
 	//CmdConfigurePromptAPIKEY prompt for api key
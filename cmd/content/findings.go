@@ -0,0 +1,68 @@
+package content
+
+//CmdFindingsUse is the command for findings
+const CmdFindingsUse = "findings"
+
+//CmdFindingsShort is the short version description for vss findings command
+const CmdFindingsShort = "Query findings"
+
+//CmdFindingsLong is the long version description for vss findings command
+const CmdFindingsLong = "Query the rule violations found on your cloud accounts"
+
+//CmdFindingsListShort is the short version description for vss findings list command
+const CmdFindingsListShort = "List findings"
+
+//CmdFindingsListLong is the long version description for vss findings list command
+const CmdFindingsListLong = "List the findings matching all given filters. " +
+	"Filter flags can be repeated or take comma separated values, a finding matches when it matches any of them. " +
+	"All pages of results are fetched unless --limit is set."
+
+//CmdFindingsListExample is the use case for command findings list
+const CmdFindingsListExample = `  vss findings list --cloud-id YOUR_CLOUD_ID
+  vss findings list --severity High --provider AWS --region us-east-1,us-west-2 --since 24h
+  vss findings list --rule-id RULE_ID --resource-type EC2Instance --since 2020-01-01T00:00:00Z --until 2020-02-01T00:00:00Z --json`
+
+//CmdFlagFindingsCloudIDDescription flag description
+const CmdFlagFindingsCloudIDDescription = "Cloud account ids to list findings of"
+
+//CmdFlagRuleIDLong flag name
+const CmdFlagRuleIDLong = "rule-id"
+
+//CmdFlagRuleIDDescription flag description
+const CmdFlagRuleIDDescription = "Rule ids to list findings of"
+
+//CmdFlagSeverityLong flag name
+const CmdFlagSeverityLong = "severity"
+
+//CmdFlagSeverityDescription flag description
+const CmdFlagSeverityDescription = "Severities to list findings of: High, Medium or Low"
+
+//CmdFlagFindingsProviderDescription flag description
+const CmdFlagFindingsProviderDescription = "Cloud providers to list findings of: AWS or Azure"
+
+//CmdFlagRegionLong flag name
+const CmdFlagRegionLong = "region"
+
+//CmdFlagFindingsRegionDescription flag description
+const CmdFlagFindingsRegionDescription = "Regions to list findings in"
+
+//CmdFlagResourceTypeLong flag name
+const CmdFlagResourceTypeLong = "resource-type"
+
+//CmdFlagResourceTypeDescription flag description
+const CmdFlagResourceTypeDescription = "Resource types to list findings of, e.g. EC2Instance"
+
+//CmdFlagSinceLong flag name
+const CmdFlagSinceLong = "since"
+
+//CmdFlagSinceDescription flag description
+const CmdFlagSinceDescription = "Only list findings last seen after this time, an RFC3339 time or a duration ago such as 90m, 24h or 7d"
+
+//CmdFlagUntilLong flag name
+const CmdFlagUntilLong = "until"
+
+//CmdFlagUntilDescription flag description
+const CmdFlagUntilDescription = "Only list findings last seen before this time, an RFC3339 time or a duration ago such as 90m, 24h or 7d"
+
+//ErrorInvalidTime error
+const ErrorInvalidTime = "invalid time %q for --%s, expected an RFC3339 time such as 2020-01-02T15:04:05Z or a duration such as 24h or 7d"
@@ -18,6 +18,27 @@ const (
 	//AccessKey api key
 	AccessKey = "API_KEY"
 
+	//CSPEndpoint csp endpoint
+	CSPEndpoint = "CSP_ENDPOINT"
+
+	//Proxy proxy url
+	Proxy = "PROXY"
+
+	//NoProxy hosts not to proxy
+	NoProxy = "NO_PROXY"
+
+	//CABundle ca bundle file
+	CABundle = "CA_BUNDLE"
+
+	//ClientCert client certificate file
+	ClientCert = "CLIENT_CERT"
+
+	//ClientKey client key file
+	ClientKey = "CLIENT_KEY"
+
+	//TLSMinVersion tls min version
+	TLSMinVersion = "TLS_MIN_VERSION"
+
 	//TeamID team id
 	TeamID = "TEAM_ID"
 
@@ -27,6 +48,9 @@ const (
 	//DefaultFile default file
 	DefaultFile = "profiles.yaml"
 
+	//TokenCacheFolder folder under the home directory the access tokens are cached in
+	TokenCacheFolder = "tokens"
+
 	//None none
 	None = "None"
 
@@ -129,6 +153,21 @@ const (
 	//CmdFlagVerboseDescription verbose flag description
 	CmdFlagVerboseDescription = "Enable verbose output"
 
+	//CmdFlagRetriesLong retries long
+	CmdFlagRetriesLong = "retries"
+
+	//CmdFlagRetriesDefault retries default
+	CmdFlagRetriesDefault = 3
+
+	//CmdFlagRetriesDescription retries flag description
+	CmdFlagRetriesDescription = "Number of times a failed API request is retried"
+
+	//CmdFlagTimeoutLong timeout long
+	CmdFlagTimeoutLong = "timeout"
+
+	//CmdFlagTimeoutDescription timeout flag description
+	CmdFlagTimeoutDescription = "Time limit for the whole command, e.g. 30s or 2m. No limit by default"
+
 	//CmdFlagFileLong JSON file flag
 	CmdFlagFileLong = "file"
 
@@ -153,6 +192,72 @@ const (
 	//CmdFlagAPIEndpointDescription api endpoint description
 	CmdFlagAPIEndpointDescription = "VMware Secure State API endpoint. Overrides $VSS_API_ENDPOINT."
 
+	//CmdFlagCSPEndpointLong csp endpoint flag long
+	CmdFlagCSPEndpointLong = "csp-endpoint"
+
+	//CmdFlagCSPEndpointDescription csp endpoint description
+	CmdFlagCSPEndpointDescription = "CSP identity endpoint the API key is authorized at. Overrides $VSS_CSP_ENDPOINT and the profile, discovered from the API endpoint by default."
+
+	//CmdFlagProxyLong proxy flag long
+	CmdFlagProxyLong = "proxy"
+
+	//CmdFlagProxyDescription proxy description
+	CmdFlagProxyDescription = "Proxy URL for API, CSP and webhook requests. Overrides the profile, $HTTPS_PROXY is used by default."
+
+	//CmdFlagNoProxyLong no proxy flag long
+	CmdFlagNoProxyLong = "no-proxy"
+
+	//CmdFlagNoProxyDescription no proxy description
+	CmdFlagNoProxyDescription = "Comma separated hosts or domains that are not sent through --proxy or the HTTPS_PROXY/HTTP_PROXY proxy. Overrides the profile."
+
+	//CmdFlagCABundleLong ca bundle flag long
+	CmdFlagCABundleLong = "ca-bundle"
+
+	//CmdFlagCABundleDescription ca bundle description
+	CmdFlagCABundleDescription = "PEM file of root certificates trusted in addition to the system roots. Overrides the profile."
+
+	//CmdFlagClientCertLong client cert flag long
+	CmdFlagClientCertLong = "client-cert"
+
+	//CmdFlagClientCertDescription client cert description
+	CmdFlagClientCertDescription = "PEM file of the client certificate for mutual TLS. Overrides the profile."
+
+	//CmdFlagClientKeyLong client key flag long
+	CmdFlagClientKeyLong = "client-key"
+
+	//CmdFlagClientKeyDescription client key description
+	CmdFlagClientKeyDescription = "PEM file of the client key for mutual TLS. Overrides the profile."
+
+	//CmdFlagTLSMinVersionLong tls min version flag long
+	CmdFlagTLSMinVersionLong = "tls-min-version"
+
+	//CmdFlagTLSMinVersionDescription tls min version description
+	CmdFlagTLSMinVersionDescription = "Lowest TLS version accepted: 1.0, 1.1, 1.2 or 1.3. Overrides the profile, default 1.2."
+
+	//CmdFlagDebugLong debug flag long
+	CmdFlagDebugLong = "debug"
+
+	//CmdFlagDebugDescription debug description
+	CmdFlagDebugDescription = "Trace API, CSP and webhook requests and responses to stderr, secrets are redacted"
+
+	//CmdFlagHARLong har flag long
+	CmdFlagHARLong = "har"
+
+	//CmdFlagHARDescription har description
+	CmdFlagHARDescription = "Write traced requests and responses to this HTTP Archive (HAR) file, secrets are redacted"
+
+	//CmdFlagLimitLong limit flag long
+	CmdFlagLimitLong = "limit"
+
+	//CmdFlagLimitDescription limit description
+	CmdFlagLimitDescription = "Maximum number of results to list, 0 lists all of them"
+
+	//CmdFlagPageSizeLong page size flag long
+	CmdFlagPageSizeLong = "page-size"
+
+	//CmdFlagPageSizeDescription page size description
+	CmdFlagPageSizeDescription = "Number of results fetched per API request"
+
 	//CmdCoreoUse Coreo cmd
 	CmdCoreoUse = "vss"
 
@@ -186,9 +291,42 @@ const (
 	//CmdShowUse show cmd
 	CmdShowUse = "show [flags]"
 
+	//CmdImportUse import cmd
+	CmdImportUse = "import"
+
+	//CmdExportUse export cmd
+	CmdExportUse = "export"
+
+	//CmdRotateUse rotate cmd
+	CmdRotateUse = "rotate"
+
+	//CmdPolicyUse policy cmd
+	CmdPolicyUse = "policy"
+
+	//CmdTagUse tag cmd
+	CmdTagUse = "tag"
+
+	//CmdTagAddUse tag add cmd
+	CmdTagAddUse = "add"
+
+	//CmdTagRemoveUse tag remove cmd
+	CmdTagRemoveUse = "remove"
+
+	//CmdTagSetUse tag set cmd
+	CmdTagSetUse = "set"
+
+	//InfoInterrupted info interrupted
+	InfoInterrupted = "Interrupted, cancelling... press Ctrl-C again to exit immediately"
+
+	//ErrorWritingHAR error writing har
+	ErrorWritingHAR = "Error writing HAR file %s: %v\n"
+
 	//InfoUsingProfile info using profile
 	InfoUsingProfile = "[ OK ] Using Profile %s\n"
 
 	//InfoCommandSuccess info command was executed successfully
 	InfoCommandSuccess = "[ OK ] Command was executed successfully"
+
+	//InfoNoResults info no results
+	InfoNoResults = "No results found."
 )
@@ -51,5 +51,5 @@ const (
 	//CmdTeamDescriptionDescription provide team description
 	CmdTeamDescriptionDescription = "Provide team description"
 
-	ErrorProviderNotSupported = "Provider not supported, either input AWS or Azure\n"
+	ErrorProviderNotSupported = "Provider not supported, either input AWS, Azure or GCP\n"
 )
@@ -15,43 +15,91 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/CloudCoreo/cli/client"
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/coreo"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
 	hostEnvVar         = "VSS_API_ENDPOINT"
+	cspEnvVar          = "VSS_CSP_ENDPOINT"
 	homeEnvVar         = "VSS_HOME"
 	profileEnvVar      = "VSS_PROFILE"
 	defaultAPIEndpoint = "https://app.securestate.vmware.com/api"
 	defaultProfile     = "default"
 )
 
+// Exit codes let scripts tell API failures apart without parsing messages.
+const (
+	exitCodeError          = 1
+	exitCodeUnauthorized   = 3
+	exitCodeForbidden      = 4
+	exitCodeNotFound       = 5
+	exitCodeInvalidRequest = 6
+	exitCodeRetryable      = 7
+	exitCodeInterrupted    = 130
+)
+
+// cleanupTimeout bounds rollback of resources created by a command that failed or was interrupted.
+const cleanupTimeout = 2 * time.Minute
+
+// defaultPropagationBackoff is how a cloud account is re-validated until IAM has propagated its new role or credentials.
+var defaultPropagationBackoff = backoff{Min: 2 * time.Second, Max: 30 * time.Second, Timeout: 3 * time.Minute}
+
+var propagationBackoff = defaultPropagationBackoff
+
 var (
 	coreoHome   string
 	userProfile string
 	key         string
 	teamID      string
 	apiEndpoint string
+	cspEndpoint string
 	jsonFormat  bool
 	verbose     bool
+	retries     int
+	timeout     time.Duration
+
+	// transport is the proxy and TLS configuration of API, CSP and webhook requests.
+	transport client.TransportConfig
+	// httpClient sends requests with transport and traces them with --debug or --har,
+	// it is nil when neither is configured.
+	httpClient *http.Client
+	debug      bool
+	harFile    string
+	// harRecorder collects the traced requests written to harFile.
+	harRecorder *client.HARRecorder
+
+	// rootCtx is cancelled on SIGINT/SIGTERM and carries the --timeout deadline,
+	// every API and cloud call of a command runs under it.
+	rootCtx       = context.Background()
+	cancelTimeout = func() {}
 )
 
 func newRootCmd(out io.Writer) *cobra.Command {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initConfig, initContext)
 	cmd := &cobra.Command{
 		// The first word of Use is the name of this command
 		Use:          content.CmdCoreoUse,
 		Short:        content.CmdCoreoShort,
 		Long:         content.CmdCoreoLong,
 		SilenceUsage: true,
+		// errors are printed by main according to --json
+		SilenceErrors: true,
 	}
 
 	userProfileToUse := os.Getenv(profileEnvVar)
@@ -70,8 +118,19 @@ func newRootCmd(out io.Writer) *cobra.Command {
 	p.StringVar(&key, content.CmdFlagAPIKeyLong, content.None, content.CmdFlagAPIKeyDescription)
 	p.StringVar(&teamID, content.CmdFlagTeamIDLong, content.None, content.CmdFlagTeamIDDescription)
 	p.StringVar(&apiEndpoint, content.CmdFlagAPIEndpointLong, envAPIEndpoint, content.CmdFlagAPIEndpointDescription)
+	p.StringVar(&cspEndpoint, content.CmdFlagCSPEndpointLong, os.Getenv(cspEnvVar), content.CmdFlagCSPEndpointDescription)
 	p.BoolVar(&jsonFormat, content.CmdFlagJSONLong, false, content.CmdFlagJSONDescription)
 	p.BoolVar(&verbose, content.CmdFlagVerboseLong, false, content.CmdFlagVerboseDescription)
+	p.IntVar(&retries, content.CmdFlagRetriesLong, content.CmdFlagRetriesDefault, content.CmdFlagRetriesDescription)
+	p.DurationVar(&timeout, content.CmdFlagTimeoutLong, 0, content.CmdFlagTimeoutDescription)
+	p.StringVar(&transport.ProxyURL, content.CmdFlagProxyLong, "", content.CmdFlagProxyDescription)
+	p.StringVar(&transport.NoProxy, content.CmdFlagNoProxyLong, "", content.CmdFlagNoProxyDescription)
+	p.StringVar(&transport.CABundle, content.CmdFlagCABundleLong, "", content.CmdFlagCABundleDescription)
+	p.StringVar(&transport.ClientCert, content.CmdFlagClientCertLong, "", content.CmdFlagClientCertDescription)
+	p.StringVar(&transport.ClientKey, content.CmdFlagClientKeyLong, "", content.CmdFlagClientKeyDescription)
+	p.StringVar(&transport.TLSMinVersion, content.CmdFlagTLSMinVersionLong, "", content.CmdFlagTLSMinVersionDescription)
+	p.BoolVar(&debug, content.CmdFlagDebugLong, false, content.CmdFlagDebugDescription)
+	p.StringVar(&harFile, content.CmdFlagHARLong, "", content.CmdFlagHARDescription)
 	cmd.AddCommand(
 		newVersionCmd(out),
 		newTeamCmd(out),
@@ -83,18 +142,87 @@ func newRootCmd(out io.Writer) *cobra.Command {
 		// Hidden documentation generator command: 'coreo docs'
 		newDocsCmd(out),
 		newEventCmd(out),
+		newFindingsCmd(out),
+		newPlanCmd(nil, out),
+		newApplyCmd(nil, nil, out),
 	)
 
 	return cmd
 }
 
 func main() {
+	ctx, stop := notifyContext(context.Background())
+	rootCtx = ctx
+
 	cmd := newRootCmd(os.Stdout)
-	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+	err := cmd.Execute()
+	cancelTimeout()
+	stop()
+	writeHAR()
+	if err != nil {
+		util.PrintError(err, jsonFormat)
+		os.Exit(exitCode(err))
+	}
+}
+
+// notifyContext returns a context that is cancelled on the first SIGINT or SIGTERM
+// so that running requests stop and created resources are rolled back. A second
+// signal exits immediately.
+func notifyContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigs:
+			fmt.Fprintln(os.Stderr, content.InfoInterrupted)
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+		<-sigs
+		os.Exit(exitCodeInterrupted)
+	}()
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
 	}
 }
 
+// initContext applies the --timeout deadline once flags are parsed.
+func initContext() {
+	if timeout > 0 {
+		rootCtx, cancelTimeout = context.WithTimeout(rootCtx, timeout)
+	}
+}
+
+// cleanupContext returns a context for rolling back created resources. It is
+// detached from rootCtx so the rollback still runs after Ctrl-C or a timeout.
+func cleanupContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), cleanupTimeout)
+}
+
+// exitCode maps an API error to the exit code of its kind.
+func exitCode(err error) int {
+	switch {
+	case errors.Cause(err) == context.Canceled:
+		return exitCodeInterrupted
+	case errors.Cause(err) == context.DeadlineExceeded:
+		return exitCodeRetryable
+	case client.IsUnauthorized(err):
+		return exitCodeUnauthorized
+	case client.IsForbidden(err):
+		return exitCodeForbidden
+	case client.IsNotFound(err):
+		return exitCodeNotFound
+	case client.IsInvalidRequest(err):
+		return exitCodeInvalidRequest
+	case client.IsRetryable(err):
+		return exitCodeRetryable
+	}
+	return exitCodeError
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 
@@ -131,6 +259,11 @@ func setupCoreoCredentials(cmd *cobra.Command, args []string) error {
 
 	}
 	key = apiKey
+	cspEndpoint = util.CheckCSPEndpointFlag(cspEndpoint, userProfile)
+
+	if err := setupHTTPClient(); err != nil {
+		return err
+	}
 
 	if verbose {
 		fmt.Printf(content.InfoUsingProfile, userProfile)
@@ -139,6 +272,67 @@ func setupCoreoCredentials(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// transportSettings pairs the transport flags with the profile keys they are saved under.
+func transportSettings() map[string]*string {
+	return map[string]*string{
+		content.Proxy:         &transport.ProxyURL,
+		content.NoProxy:       &transport.NoProxy,
+		content.CABundle:      &transport.CABundle,
+		content.ClientCert:    &transport.ClientCert,
+		content.ClientKey:     &transport.ClientKey,
+		content.TLSMinVersion: &transport.TLSMinVersion,
+	}
+}
+
+// setupHTTPClient fills the transport flags that are not set from the profile
+// and builds the http client shared by the API, CSP and webhook requests.
+func setupHTTPClient() error {
+	for profileKey, value := range transportSettings() {
+		*value = util.CheckProfileFlag(*value, userProfile, profileKey)
+	}
+
+	httpClient = nil
+	if transport.IsZero() && !debug && harFile == "" {
+		return nil
+	}
+
+	t, err := transport.Transport()
+	if err != nil {
+		return err
+	}
+	var rt http.RoundTripper = t
+	if debug || harFile != "" {
+		dt := &client.DebugTransport{Next: t}
+		if debug {
+			dt.Out = os.Stderr
+		}
+		if harFile != "" {
+			harRecorder = client.NewHARRecorder(content.CmdCoreoUse+"-cli", version)
+			dt.HAR = harRecorder
+		}
+		rt = dt
+	}
+	httpClient = &http.Client{Transport: rt}
+	return nil
+}
+
+// writeHAR saves the traced requests, also when the command failed.
+func writeHAR() {
+	if harRecorder == nil {
+		return
+	}
+	f, err := os.OpenFile(harFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err == nil {
+		_, err = harRecorder.WriteTo(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, content.ErrorWritingHAR, harFile, err)
+	}
+}
+
 func defaultCoreoHome() string {
 	if home := os.Getenv(homeEnvVar); home != "" {
 		return home
@@ -150,3 +344,32 @@ func defaultCoreoHome() string {
 func homePath() string {
 	return os.ExpandEnv(coreoHome)
 }
+
+// tokenCachePath returns the file the CSP access token of the current profile is cached in.
+func tokenCachePath() string {
+	return filepath.Join(homePath(), content.TokenCacheFolder, userProfile+".json")
+}
+
+// userAgent identifies the CLI and its version to the API.
+func userAgent() string {
+	v := version
+	if v == "" {
+		v = "dev"
+	}
+	return content.CmdCoreoUse + "-cli/" + v
+}
+
+// newCoreoClient returns a coreo client configured by the global flags.
+func newCoreoClient() *coreo.Client {
+	c := coreo.NewClient(
+		coreo.Host(apiEndpoint),
+		coreo.RefreshToken(key),
+		coreo.CSPEndpoint(cspEndpoint),
+		coreo.TokenCacheFile(tokenCachePath()),
+		coreo.Retries(retries),
+		coreo.UserAgent(userAgent()))
+	if httpClient != nil {
+		c.Option(coreo.HTTPClient(httpClient))
+	}
+	return c
+}
@@ -15,35 +15,85 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
 	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/pkg/errors"
 )
 
 type fakeReleaseClient struct {
+	// mu guards the fields changed by the calls, commands make them from several goroutines
+	mu               sync.Mutex
 	cloudAccounts    []*client.CloudAccount
+	findings         []*client.Finding
+	findingFilter    *client.FindingFilter
+	listOptions      client.ListOptions
 	config           client.EventStreamConfig
 	err              error
 	info             client.RoleCreationInfo
 	regions          []string
 	validationResult client.RoleReValidationResult
+	// validationResults are the results of ReValidateRole by cloud ID, validationResult is returned for others
+	validationResults map[string]client.RoleReValidationResult
+	// validations are returned by ReValidateRole in turn before the results above
+	validations []client.RoleReValidationResult
+	// validationErrs are returned by ReValidateRole in turn before the validations
+	validationErrs []error
+	// createErrs are returned by CreateCloudAccount in turn before err
+	createErrs []error
+	// created is the input of the last CreateCloudAccount call
+	created *client.CreateCloudAccountInput
+	// updated is the input of the last UpdateCloudAccount call
+	updated *client.UpdateCloudAccountInput
+	// deleted are the IDs of the deleted cloud accounts
+	deleted []string
 }
 
-func (c *fakeReleaseClient) ListCloudAccounts() ([]*client.CloudAccount, error) {
+func (c *fakeReleaseClient) ListCloudAccounts(ctx context.Context, opts client.ListOptions) ([]*client.CloudAccount, error) {
 	resp := c.cloudAccounts
+	if opts.Match != nil {
+		resp = nil
+		for _, cloud := range c.cloudAccounts {
+			if opts.Match(cloud) {
+				resp = append(resp, cloud)
+			}
+		}
+	}
+	if opts.Limit > 0 && len(resp) > opts.Limit {
+		resp = resp[:opts.Limit]
+	}
 
 	return resp, c.err
 }
 
-func (c *fakeReleaseClient) ShowCloudAccountByID(cloudID string) (*client.CloudAccount, error) {
+func (c *fakeReleaseClient) ShowCloudAccountByID(ctx context.Context, cloudID string) (*client.CloudAccount, error) {
 	resp := &client.CloudAccount{}
 	if len(c.cloudAccounts) > 0 {
 
 		resp = c.cloudAccounts[0]
 	}
+	for _, cloud := range c.cloudAccounts {
+		if cloud.ID == cloudID && cloudID != "" {
+			resp = cloud
+		}
+	}
 
 	return resp, c.err
 }
 
-func (c *fakeReleaseClient) CreateCloudAccount(input *client.CreateCloudAccountInput) (*client.CloudAccount, error) {
+func (c *fakeReleaseClient) CreateCloudAccount(ctx context.Context, input *client.CreateCloudAccountInput) (*client.CloudAccount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.created = input
+	if len(c.createErrs) > 0 {
+		err := c.createErrs[0]
+		c.createErrs = c.createErrs[1:]
+		return nil, err
+	}
 	resp := &client.CloudAccount{}
 	if len(c.cloudAccounts) > 0 {
 
@@ -53,17 +103,20 @@ func (c *fakeReleaseClient) CreateCloudAccount(input *client.CreateCloudAccountI
 	return resp, c.err
 }
 
-func (c *fakeReleaseClient) DeleteCloudAccountByID(cloudID string) error {
+func (c *fakeReleaseClient) DeleteCloudAccountByID(ctx context.Context, cloudID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = append(c.deleted, cloudID)
 	return c.err
 }
 
-func (c *fakeReleaseClient) GetEventStreamConfig(cloudID string) (*client.EventStreamConfig, error) {
+func (c *fakeReleaseClient) GetEventStreamConfig(ctx context.Context, cloudID string) (*client.EventStreamConfig, error) {
 	return &client.EventStreamConfig{
 		AWSEventStreamConfig: client.AWSEventStreamConfig{Regions: c.regions},
 	}, c.err
 }
 
-func (c *fakeReleaseClient) GetEventRemoveConfig(cloudID string) (*client.EventRemoveConfig, error) {
+func (c *fakeReleaseClient) GetEventRemoveConfig(ctx context.Context, cloudID string) (*client.EventRemoveConfig, error) {
 	return &client.EventRemoveConfig{
 		AWSEventRemoveConfig: client.AWSEventRemoveConfig{
 			Regions: c.regions,
@@ -71,12 +124,19 @@ func (c *fakeReleaseClient) GetEventRemoveConfig(cloudID string) (*client.EventR
 	}, c.err
 }
 
-func (c *fakeReleaseClient) GetRoleCreationInfo(input *client.CreateCloudAccountInput) (*client.RoleCreationInfo, error) {
+func (c *fakeReleaseClient) GetRoleCreationInfo(ctx context.Context, input *client.CreateCloudAccountInput) (*client.RoleCreationInfo, error) {
 	resp := c.info
 	return &resp, c.err
 }
 
-func (c *fakeReleaseClient) UpdateCloudAccount(input *client.UpdateCloudAccountInput) (*client.CloudAccount, error) {
+func (c *fakeReleaseClient) GetPolicyCatalog(ctx context.Context) (client.PolicyCatalog, error) {
+	return c.info.Catalog, c.err
+}
+
+func (c *fakeReleaseClient) UpdateCloudAccount(ctx context.Context, input *client.UpdateCloudAccountInput) (*client.CloudAccount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updated = input
 	resp := &client.CloudAccount{}
 	if len(c.cloudAccounts) > 0 {
 
@@ -85,29 +145,134 @@ func (c *fakeReleaseClient) UpdateCloudAccount(input *client.UpdateCloudAccountI
 	return resp, c.err
 }
 
-func (c *fakeReleaseClient) ReValidateRole(cloudID string) (*client.RoleReValidationResult, error) {
-	resp := c.validationResult
+func (c *fakeReleaseClient) ReValidateRole(ctx context.Context, cloudID string) (*client.RoleReValidationResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.validationErrs) > 0 {
+		err := c.validationErrs[0]
+		c.validationErrs = c.validationErrs[1:]
+		return nil, err
+	}
+	if len(c.validations) > 0 {
+		resp := c.validations[0]
+		c.validations = c.validations[1:]
+		return &resp, c.err
+	}
+	resp, ok := c.validationResults[cloudID]
+	if !ok {
+		resp = c.validationResult
+	}
 	return &resp, c.err
 }
 
+func (c *fakeReleaseClient) ListFindings(ctx context.Context, filter *client.FindingFilter, opts client.ListOptions) ([]*client.Finding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.findingFilter = filter
+	c.listOptions = opts
+	return c.findings, c.err
+}
+
 type fakeCloudProvider struct {
 	err        error
 	arn        string
 	externalID string
+	// roleInfo is the input of the last CreateNewRole call
+	roleInfo *client.RoleCreationInfo
+	// deleteErr is returned by DeleteRole and setupErr by SetupEventStream in place of err when set
+	deleteErr error
+	setupErr  error
+	// setups is the number of SetupEventStream calls
+	setups int
+	// deleted are the deleted roles and removed the config of the removed event stream
+	deleted []string
+	removed *client.EventRemoveConfig
 }
 
-func (c *fakeCloudProvider) SetupEventStream(input *client.EventStreamConfig) error {
-
+func (c *fakeCloudProvider) SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error {
+	c.setups++
+	if c.setupErr != nil {
+		return c.setupErr
+	}
 	return c.err
 }
 
-func (c *fakeCloudProvider) CreateNewRole(input *client.RoleCreationInfo) (arn string, externalID string, err error) {
+func (c *fakeCloudProvider) CreateNewRole(ctx context.Context, input *client.RoleCreationInfo) (arn string, externalID string, err error) {
+	c.roleInfo = input
 	return c.arn, c.externalID, c.err
 }
 
-func (c *fakeCloudProvider) DeleteRole(roleName string) {
-
+func (c *fakeCloudProvider) DeleteRole(ctx context.Context, roleName string) error {
+	c.deleted = append(c.deleted, roleName)
+	if c.deleteErr != nil {
+		return c.deleteErr
+	}
+	return c.err
 }
-func (c *fakeCloudProvider) RemoveEventStream(input *client.EventRemoveConfig) error {
+func (c *fakeCloudProvider) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
+	c.removed = input
 	return c.err
 }
+
+// fakeServicePrincipalProvider is a fakeCloudProvider creating Azure service principals
+type fakeServicePrincipalProvider struct {
+	fakeCloudProvider
+	sp *client.ServicePrincipal
+}
+
+func (c *fakeServicePrincipalProvider) CreateServicePrincipal(ctx context.Context, input *client.RoleCreationInfo) (*client.ServicePrincipal, error) {
+	return c.sp, c.err
+}
+
+// fakeServiceAccountProvider is a fakeCloudProvider creating GCP service accounts
+type fakeServiceAccountProvider struct {
+	fakeCloudProvider
+	account *client.ServiceAccount
+	// createErr is returned with the account, e.g. when its key couldn't be created
+	createErr error
+}
+
+func (c *fakeServiceAccountProvider) CreateServiceAccount(ctx context.Context, input *client.RoleCreationInfo) (*client.ServiceAccount, error) {
+	return c.account, c.createErr
+}
+
+type fakeOrganization struct {
+	accounts []*client.OrganizationAccount
+	err      error
+	// providers act in the accounts by ID, accounts without one fail
+	providers map[string]command.CloudProvider
+}
+
+func (o *fakeOrganization) ListAccounts(ctx context.Context) ([]*client.OrganizationAccount, error) {
+	return o.accounts, o.err
+}
+
+func (o *fakeOrganization) AccountProvider(ctx context.Context, account *client.OrganizationAccount) (command.CloudProvider, error) {
+	provider, ok := o.providers[account.ID]
+	if !ok {
+		return nil, errors.New("AccessDenied: can't assume role")
+	}
+	return provider, nil
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{&client.APIError{StatusCode: http.StatusUnauthorized}, exitCodeUnauthorized},
+		{&client.APIError{StatusCode: http.StatusForbidden}, exitCodeForbidden},
+		{errors.Wrap(&client.APIError{StatusCode: http.StatusNotFound}, "show"), exitCodeNotFound},
+		{&client.APIError{StatusCode: http.StatusUnprocessableEntity}, exitCodeInvalidRequest},
+		{&client.APIError{StatusCode: http.StatusServiceUnavailable}, exitCodeRetryable},
+		{context.Canceled, exitCodeInterrupted},
+		{errors.Wrap(context.DeadlineExceeded, "list cloud accounts"), exitCodeRetryable},
+		{errors.New("Error"), exitCodeError},
+	}
+
+	for _, tt := range tests {
+		if code := exitCode(tt.err); code != tt.code {
+			t.Errorf("exitCode(%v) = %d, expected %d", tt.err, code, tt.code)
+		}
+	}
+}
@@ -8,13 +8,13 @@ import (
 
 	"github.com/CloudCoreo/cli/pkg/aws"
 
-	"github.com/CloudCoreo/cli/pkg/coreo"
 
 	"github.com/CloudCoreo/cli/cmd/util"
 
 	"github.com/CloudCoreo/cli/cmd/content"
 
 	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/CloudCoreo/cli/pkg/gcp"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -25,9 +25,11 @@ type eventRemoveCmd struct {
 	out            io.Writer
 	awsProfile     string
 	awsProfilePath string
+	session        awsSession
 	cloudID        string
 	authFile       string
 	region         string
+	gcpCredentials string
 }
 
 func newEventRemoveCmd(client command.Interface, provider command.CloudProvider, out io.Writer) *cobra.Command {
@@ -48,9 +50,7 @@ func newEventRemoveCmd(client command.Interface, provider command.CloudProvider,
 				return err
 			}
 			if eventRemove.client == nil {
-				eventRemove.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				eventRemove.client = newCoreoClient()
 			}
 
 			return eventRemove.run()
@@ -59,31 +59,38 @@ func newEventRemoveCmd(client command.Interface, provider command.CloudProvider,
 	f := cmd.Flags()
 	f.StringVarP(&eventRemove.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
 	f.StringVarP(&eventRemove.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	eventRemove.session.addFlags(cmd)
 	f.StringVarP(&eventRemove.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
 	f.StringVarP(&eventRemove.authFile, content.CmdEventAuthFile, "", "", content.CmdEventAuthFileDescription)
 	f.StringVarP(&eventRemove.region, content.CmdEventRegion, "", "eastus", content.CmdEventRegionDescription)
+	f.StringVarP(&eventRemove.gcpCredentials, content.CmdFlagGCPCredentials, "", "", content.CmdFlagGCPCredentialsDescription)
 
 	return cmd
 }
 
 func (t *eventRemoveCmd) run() error {
-	config, err := t.client.GetEventRemoveConfig(t.cloudID)
+	config, err := t.client.GetEventRemoveConfig(rootCtx, t.cloudID)
 	if err != nil {
 		return err
 	}
 	if t.cloud == nil {
 		if config.Provider == "AWS" {
-			newServiceInput := &aws.NewServiceInput{
-				AwsProfile:     t.awsProfile,
-				AwsProfilePath: t.awsProfilePath,
-			}
+			newServiceInput := t.session.serviceInput(t.awsProfile, t.awsProfilePath)
 			t.cloud = aws.NewService(newServiceInput)
 		} else if config.Provider == "Azure" {
 			newServiceInput := &azure.NewServiceInput{
-				AuthFile: t.authFile,
-				Region:   t.region,
+				AuthFile:   t.authFile,
+				Region:     t.region,
+				HTTPClient: httpClient,
 			}
 			t.cloud = azure.NewService(newServiceInput)
+		} else if config.Provider == "GCP" {
+			newServiceInput := &gcp.NewServiceInput{
+				ProjectID:       config.ProjectID,
+				CredentialsFile: t.gcpCredentials,
+				HTTPClient:      httpClient,
+			}
+			t.cloud = gcp.NewService(newServiceInput)
 		} else {
 			return errors.New("unsupported provider type " + config.Provider + " ")
 		}
@@ -93,7 +100,7 @@ func (t *eventRemoveCmd) run() error {
 		return errors.New("No regions returned")
 	}
 
-	err = t.cloud.RemoveEventStream(config)
+	err = t.cloud.RemoveEventStream(rootCtx, config)
 	if err != nil {
 		return err
 	}
@@ -11,7 +11,7 @@ import (
 	"github.com/CloudCoreo/cli/pkg/aws"
 	"github.com/CloudCoreo/cli/pkg/azure"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
+	"github.com/CloudCoreo/cli/pkg/gcp"
 	"github.com/spf13/cobra"
 )
 
@@ -21,10 +21,12 @@ type eventSetupCmd struct {
 	out                 io.Writer
 	awsProfile          string
 	awsProfilePath      string
+	session             awsSession
 	cloudID             string
 	ignoreMissingTrails bool
 	authFile            string
 	region              string
+	gcpCredentials      string
 }
 
 func newEventSetupCmd(client command.Interface, provider command.CloudProvider, out io.Writer) *cobra.Command {
@@ -45,9 +47,7 @@ func newEventSetupCmd(client command.Interface, provider command.CloudProvider,
 				return err
 			}
 			if eventSetup.client == nil {
-				eventSetup.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				eventSetup.client = newCoreoClient()
 			}
 
 			return eventSetup.run()
@@ -56,34 +56,41 @@ func newEventSetupCmd(client command.Interface, provider command.CloudProvider,
 	f := cmd.Flags()
 	f.StringVarP(&eventSetup.awsProfile, content.CmdFlagAwsProfile, "", "", content.CmdFlagAwsProfileDescription)
 	f.StringVarP(&eventSetup.awsProfilePath, content.CmdFlagAwsProfilePath, "", "", content.CmdFlagAwsProfilePathDescription)
+	eventSetup.session.addFlags(cmd)
 	f.StringVarP(&eventSetup.cloudID, content.CmdFlagCloudIDLong, "", "", content.CmdFlagCloudIDDescription)
 	f.BoolVarP(&eventSetup.ignoreMissingTrails, content.CmdFlagIgnoreMissingTrails, "", false, content.CmdFlagIgnoreMissingTrailsDescription)
 	f.StringVarP(&eventSetup.authFile, content.CmdEventAuthFile, "", "", content.CmdEventAuthFileDescription)
 	f.StringVarP(&eventSetup.region, content.CmdEventRegion, "", "eastus", content.CmdEventRegionDescription)
+	f.StringVarP(&eventSetup.gcpCredentials, content.CmdFlagGCPCredentials, "", "", content.CmdFlagGCPCredentialsDescription)
 	return cmd
 }
 
 func (t *eventSetupCmd) run() error {
 
-	config, err := t.client.GetEventStreamConfig(t.cloudID)
+	config, err := t.client.GetEventStreamConfig(rootCtx, t.cloudID)
 	if err != nil {
 		return err
 	}
 
 	if t.cloud == nil {
 		if config.Provider == "AWS" {
-			newServiceInput := &aws.NewServiceInput{
-				AwsProfile:          t.awsProfile,
-				AwsProfilePath:      t.awsProfilePath,
-				IgnoreMissingTrails: t.ignoreMissingTrails,
-			}
+			newServiceInput := t.session.serviceInput(t.awsProfile, t.awsProfilePath)
+			newServiceInput.IgnoreMissingTrails = t.ignoreMissingTrails
 			t.cloud = aws.NewService(newServiceInput)
 		} else if config.Provider == "Azure" {
 			newServiceInput := &azure.NewServiceInput{
-				AuthFile: t.authFile,
-				Region:   t.region,
+				AuthFile:   t.authFile,
+				Region:     t.region,
+				HTTPClient: httpClient,
 			}
 			t.cloud = azure.NewService(newServiceInput)
+		} else if config.Provider == "GCP" {
+			newServiceInput := &gcp.NewServiceInput{
+				ProjectID:       config.ProjectID,
+				CredentialsFile: t.gcpCredentials,
+				HTTPClient:      httpClient,
+			}
+			t.cloud = gcp.NewService(newServiceInput)
 		} else {
 			return errors.New("unsupported provider type " + config.Provider + " ")
 		}
@@ -93,7 +100,7 @@ func (t *eventSetupCmd) run() error {
 	if config.Provider == "AWS" && len(config.Regions) == 0 {
 		return errors.New("No regions returned")
 	}
-	err = t.cloud.SetupEventStream(config)
+	err = t.cloud.SetupEventStream(rootCtx, config)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/spf13/cobra"
+)
+
+func newFindingsCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               content.CmdFindingsUse,
+		Short:             content.CmdFindingsShort,
+		Long:              content.CmdFindingsLong,
+		PersistentPreRunE: setupCoreoConfig,
+	}
+	cmd.AddCommand(newFindingsListCmd(nil, out))
+	return cmd
+}
+
+type findingsListCmd struct {
+	out    io.Writer
+	client command.Interface
+	filter client.FindingFilter
+	opts   client.ListOptions
+	since  string
+	until  string
+}
+
+func newFindingsListCmd(client command.Interface, out io.Writer) *cobra.Command {
+	findingsList := &findingsListCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdListUse,
+		Short:   content.CmdFindingsListShort,
+		Long:    content.CmdFindingsListLong,
+		Example: content.CmdFindingsListExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if findingsList.client == nil {
+				findingsList.client = newCoreoClient()
+			}
+
+			return findingsList.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&findingsList.filter.CloudAccountIDs, content.CmdFlagCloudIDLong, nil, content.CmdFlagFindingsCloudIDDescription)
+	f.StringSliceVar(&findingsList.filter.RuleIDs, content.CmdFlagRuleIDLong, nil, content.CmdFlagRuleIDDescription)
+	f.StringSliceVar(&findingsList.filter.Levels, content.CmdFlagSeverityLong, nil, content.CmdFlagSeverityDescription)
+	f.StringSliceVar(&findingsList.filter.Providers, content.CmdFlagProvider, nil, content.CmdFlagFindingsProviderDescription)
+	f.StringSliceVar(&findingsList.filter.Regions, content.CmdFlagRegionLong, nil, content.CmdFlagFindingsRegionDescription)
+	f.StringSliceVar(&findingsList.filter.ObjectTypes, content.CmdFlagResourceTypeLong, nil, content.CmdFlagResourceTypeDescription)
+	f.StringVar(&findingsList.since, content.CmdFlagSinceLong, "", content.CmdFlagSinceDescription)
+	f.StringVar(&findingsList.until, content.CmdFlagUntilLong, "", content.CmdFlagUntilDescription)
+	addListFlags(cmd, &findingsList.opts)
+
+	return cmd
+}
+
+func (t *findingsListCmd) run() error {
+	now := time.Now()
+	var err error
+	if t.filter.LastSeenFrom, err = util.ParseTimeFlag(content.CmdFlagSinceLong, t.since, now); err != nil {
+		return err
+	}
+	if t.filter.LastSeenTo, err = util.ParseTimeFlag(content.CmdFlagUntilLong, t.until, now); err != nil {
+		return err
+	}
+
+	findings, err := t.client.ListFindings(rootCtx, &t.filter, t.opts)
+	if err != nil {
+		return err
+	}
+
+	b := make([]interface{}, len(findings))
+	for i := range findings {
+		b[i] = findings[i]
+	}
+
+	util.PrintResult(
+		t.out,
+		b,
+		[]string{"RuleName", "Level", "Status", "CloudAccountID", "Region", "ObjectType", "ObjectID", "LastSeen"},
+		map[string]string{
+			"RuleName":       "Rule",
+			"Level":          "Severity",
+			"Status":         "Status",
+			"CloudAccountID": "Cloud Account ID",
+			"Region":         "Region",
+			"ObjectType":     "Resource Type",
+			"ObjectID":       "Resource ID",
+			"LastSeen":       "Last Seen",
+		},
+		jsonFormat,
+		verbose)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/pkg/errors"
+)
+
+func TestFindingsListCmd(t *testing.T) {
+	tests := []struct {
+		cmds  string
+		desc  string
+		flags []string
+		resp  []*client.Finding
+		err   bool
+		xout  string
+	}{
+		{
+			cmds:  "vss findings list, success",
+			desc:  "list findings",
+			flags: []string{"--severity", "High,Medium", "--cloud-id", "cloud1", "--region", "us-east-1", "--since", "24h"},
+			resp: []*client.Finding{
+				{RuleName: "S3 bucket is public", Level: "High", Status: "Open", CloudAccountID: "cloud1", Region: "us-east-1", ObjectType: "S3Bucket", ObjectID: "bucket1", LastSeen: "2020-01-01T00:00:00Z"},
+			},
+			xout: `Rule[\s\S]*Severity[\s\S]*Resource ID[\s\S]*S3 bucket is public[\s\S]*High[\s\S]*bucket1`,
+		},
+		{
+			cmds: "vss findings list, no findings",
+			desc: "list findings without results",
+			xout: "No results found.",
+		},
+		{
+			cmds:  "vss findings list, invalid time",
+			desc:  "list findings with an invalid --until",
+			flags: []string{"--until", "yesterday"},
+			err:   true,
+		},
+		{
+			cmds: "vss findings list, failure",
+			desc: "list findings",
+			err:  true,
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{findings: tt.resp}
+		if tt.err {
+			frc.err = errors.New("Error")
+		}
+
+		cmd := newFindingsListCmd(frc, &buf)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, nil)
+
+		if (err != nil) != tt.err {
+			t.Errorf("%q. expected error, got '%v'", tt.desc, err)
+		}
+
+		re := regexp.MustCompile(tt.xout)
+		if !re.Match(buf.Bytes()) {
+			t.Fatalf("%q\n\t%s:\nexpected\n\t%q\nactual\n\t%q", tt.cmds, tt.desc, tt.xout, buf.String())
+		}
+		buf.Reset()
+	}
+}
+
+func TestFindingsListCmdFilter(t *testing.T) {
+	frc := &fakeReleaseClient{}
+	cmd := newFindingsListCmd(frc, &bytes.Buffer{})
+	cmd.ParseFlags([]string{"--severity", "High", "--severity", "Low", "--provider", "AWS", "--rule-id", "rule1", "--resource-type", "EC2Instance", "--since", "2020-01-01T00:00:00Z", "--limit", "50", "--page-size", "10"})
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := frc.findingFilter
+	if len(filter.Levels) != 2 || filter.Levels[1] != "Low" {
+		t.Errorf("expected severities High and Low, got %v", filter.Levels)
+	}
+	if len(filter.Providers) != 1 || len(filter.RuleIDs) != 1 || len(filter.ObjectTypes) != 1 {
+		t.Errorf("expected provider, rule and resource type filters, got %+v", filter)
+	}
+	if frc.listOptions.Limit != 50 || frc.listOptions.PageSize != 10 {
+		t.Errorf("expected limit 50 and page size 10, got %+v", frc.listOptions)
+	}
+	if filter.LastSeenFrom == nil || filter.LastSeenFrom.Year() != 2020 || filter.LastSeenTo != nil {
+		t.Errorf("expected only the start of the time window, got %v - %v", filter.LastSeenFrom, filter.LastSeenTo)
+	}
+}
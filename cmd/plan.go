@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/cmd/util"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/CloudCoreo/cli/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+type planCmd struct {
+	out    io.Writer
+	client command.Interface
+	file   string
+	prune  bool
+}
+
+func newPlanCmd(client command.Interface, out io.Writer) *cobra.Command {
+	plan := &planCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     content.CmdPlanUse,
+		Short:   content.CmdPlanShort,
+		Long:    content.CmdPlanLong,
+		Example: content.CmdPlanExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if plan.file == "" {
+				return fmt.Errorf(content.ErrorFileRequired)
+			}
+			if plan.client == nil {
+				plan.client = newCoreoClient()
+			}
+
+			return plan.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&plan.file, content.CmdFlagFileLong, content.CmdFlagFileShort, "", content.CmdFlagInventoryFileDescription)
+	f.BoolVarP(&plan.prune, content.CmdFlagPrune, "", false, content.CmdFlagPruneDescription)
+
+	return cmd
+}
+
+func (t *planCmd) run() error {
+	changes, unmanaged, err := planInventory(t.client, t.file, t.prune)
+	if err != nil {
+		return err
+	}
+
+	if jsonFormat {
+		util.PrettyPrintJSON(changes)
+		return nil
+	}
+	printPlan(t.out, changes, unmanaged)
+	return nil
+}
+
+// planInventory returns the changes needed for the cloud accounts to match the
+// inventory file and the number of accounts missing from it that are kept
+// without prune. The accounts to add are validated as in 'vss cloud import'.
+func planInventory(c command.Interface, file string, prune bool) ([]inventory.Change, int, error) {
+	accounts, err := inventory.Load(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	current, err := c.ListCloudAccounts(rootCtx, client.ListOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	changes, err := inventory.Plan(accounts, current, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	validate := &cloudImportCmd{client: c}
+	kept := make([]inventory.Change, 0, len(changes))
+	unmanaged := 0
+	for _, change := range changes {
+		if change.Action == inventory.ActionCreate {
+			if _, err := validate.createCmd(*change.Account); err != nil {
+				return nil, 0, fmt.Errorf("%s: %v", change.Name, err)
+			}
+		}
+		if change.Action == inventory.ActionDelete && !prune {
+			unmanaged++
+			continue
+		}
+		kept = append(kept, change)
+	}
+
+	return kept, unmanaged, nil
+}
+
+// printPlan prints the changes as a diff
+func printPlan(out io.Writer, changes []inventory.Change, unmanaged int) {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Action]++
+		switch change.Action {
+		case inventory.ActionCreate:
+			fmt.Fprintf(out, "+ add %s\n", change.Name)
+		case inventory.ActionUpdate:
+			fmt.Fprintf(out, "~ update %s (%s)\n", change.Name, change.CloudID)
+			for _, field := range change.Fields {
+				fmt.Fprintf(out, "    %s: %q => %q\n", field.Field, field.From, field.To)
+			}
+		case inventory.ActionDelete:
+			fmt.Fprintf(out, "- delete %s (%s)\n", change.Name, change.CloudID)
+		}
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(out, content.InfoPlanNoChanges)
+	} else {
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, content.InfoPlanSummary, counts[inventory.ActionCreate], counts[inventory.ActionUpdate], counts[inventory.ActionDelete])
+	}
+	if unmanaged > 0 {
+		fmt.Fprintf(out, content.InfoPlanUnmanaged, unmanaged)
+	}
+}
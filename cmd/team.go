@@ -20,7 +20,6 @@ import (
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -59,9 +58,7 @@ func newTeamListCmd(client command.Interface, out io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if teamList.client == nil {
-				teamList.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				teamList.client = newCoreoClient()
 			}
 			_, err := fmt.Fprint(out, "Teams are deprecated, only csp token is required` \n")
 			return err
@@ -20,7 +20,6 @@ import (
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -44,9 +43,7 @@ func newTeamCreateCmd(client command.Interface, out io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if teamCreate.client == nil {
-				teamCreate.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				teamCreate.client = newCoreoClient()
 			}
 			_, err := fmt.Fprint(out, "Teams are deprecated` \n")
 			return err
@@ -20,7 +20,6 @@ import (
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -42,9 +41,7 @@ func newTeamShowCmd(client command.Interface, out io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if teamShow.client == nil {
-				teamShow.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				teamShow.client = newCoreoClient()
 			}
 
 			_, err := fmt.Fprint(out, "Teams are deprecated` \n")
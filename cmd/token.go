@@ -20,7 +20,6 @@ import (
 
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -57,9 +56,7 @@ func newTokenListCmd(client command.Interface, out io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if tokenList.client == nil {
-				tokenList.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				tokenList.client = newCoreoClient()
 			}
 			_, err := fmt.Fprint(out, "Tokens are deprecated, only csp token is required` \n")
 			return err
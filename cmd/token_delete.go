@@ -22,7 +22,6 @@ import (
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/CloudCoreo/cli/cmd/util"
 	"github.com/CloudCoreo/cli/pkg/command"
-	"github.com/CloudCoreo/cli/pkg/coreo"
 	"github.com/spf13/cobra"
 )
 
@@ -49,9 +48,7 @@ func newTokenDeleteCmd(client command.Interface, out io.Writer) *cobra.Command {
 			}
 
 			if tokenDelete.client == nil {
-				tokenDelete.client = coreo.NewClient(
-					coreo.Host(apiEndpoint),
-					coreo.RefreshToken(key))
+				tokenDelete.client = newCoreoClient()
 			}
 			_, err := fmt.Fprint(out, "Tokens are deprecated, only csp token is required` \n")
 			return err
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/cmd/content"
+	"github.com/CloudCoreo/cli/pkg/aws"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/pkg/errors"
+)
+
+// backoff is how a condition is polled, the delay starts at Min and doubles up
+// to Max until Timeout passes
+type backoff struct {
+	Min     time.Duration
+	Max     time.Duration
+	Timeout time.Duration
+}
+
+// poll calls check until it is done, fails or the timeout of b passes, it
+// tells whether check got done
+func poll(ctx context.Context, b backoff, check func() (bool, error)) (bool, error) {
+	start := time.Now()
+	delay := b.Min
+	for {
+		done, err := check()
+		if done || err != nil {
+			return done, err
+		}
+		if time.Since(start)+delay > b.Timeout {
+			return false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > b.Max {
+			delay = b.Max
+		}
+	}
+}
+
+// waitUntilValid re-validates the cloud account until it is valid, a new role or
+// new credentials can only be used once IAM has propagated them. Transient
+// errors are retried. The last result is returned, it isn't valid when the
+// propagation timeout passed.
+func waitUntilValid(c command.Interface, cloudID string) (*client.RoleReValidationResult, error) {
+	var res *client.RoleReValidationResult
+	var lastErr error
+	_, err := poll(rootCtx, propagationBackoff, func() (bool, error) {
+		r, err := c.ReValidateRole(rootCtx, cloudID)
+		if err != nil {
+			if rootCtx.Err() == nil && isTransient(err) {
+				lastErr = err
+				return false, nil
+			}
+			return false, err
+		}
+		res, lastErr = r, nil
+		return res.IsValid, nil
+	})
+	if err == nil && res == nil {
+		err = lastErr
+	}
+	return res, err
+}
+
+// createCloudAccount adds the cloud account of a new role. VSS validates the
+// role when the account is added, which fails until IAM has propagated the
+// role, so adding it is retried until the propagation timeout passes. Adding an
+// account isn't idempotent, so it is only retried when the API rejected the
+// request: because it can't assume the role yet or with 429.
+func createCloudAccount(c command.Interface, input *client.CreateCloudAccountInput) (*client.CloudAccount, error) {
+	var cloud *client.CloudAccount
+	var lastErr error
+	done, err := poll(rootCtx, propagationBackoff, func() (bool, error) {
+		var err error
+		cloud, err = c.CreateCloudAccount(rootCtx, input)
+		if err != nil {
+			if rootCtx.Err() == nil && (isAssumeRoleError(err) || client.StatusCode(err) == http.StatusTooManyRequests) {
+				lastErr = err
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+	if err == nil && !done {
+		err = lastErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cloud, nil
+}
+
+// isTransient tells whether a failed request may succeed when it is sent
+// again: it got no response or the API asked to retry it.
+func isTransient(err error) bool {
+	if _, ok := errors.Cause(err).(*client.APIError); !ok {
+		return true
+	}
+	return client.IsRetryable(err)
+}
+
+// assumeRoleErrors are parts of the code or message of the API error of a cloud
+// account whose role VSS can't assume, in lower case
+var assumeRoleErrors = []string{"assumerole", "assume role", "assume the role"}
+
+// isAssumeRoleError tells whether the API rejected a new cloud account because
+// it can't assume its role, which it does until IAM has propagated the role
+func isAssumeRoleError(err error) bool {
+	apiErr, ok := errors.Cause(err).(*client.APIError)
+	if !ok || (apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusUnprocessableEntity) {
+		return false
+	}
+	message := apiErr.Message
+	if message == "" {
+		message = apiErr.Body
+	}
+	text := strings.ToLower(apiErr.Code + " " + message)
+	for _, part := range assumeRoleErrors {
+		if strings.Contains(text, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// transaction records the resources a command creates, they are undone newest
+// first when a later step fails
+type transaction struct {
+	steps []undoStep
+}
+
+type undoStep struct {
+	resource string
+	// recovery are the commands undoing the step by hand
+	recovery []string
+	undo     func(ctx context.Context) error
+}
+
+// record adds a created resource to the transaction
+func (t *transaction) record(resource string, recovery []string, undo func(ctx context.Context) error) {
+	t.steps = append(t.steps, undoStep{resource: resource, recovery: recovery, undo: undo})
+}
+
+// rollback undoes the recorded steps after cause made the command fail. Every
+// step is tried even when one fails, the commands undoing the failed ones by
+// hand are then part of the returned error.
+func (t *transaction) rollback(cause error) error {
+	ctx, cancel := cleanupContext()
+	defer cancel()
+
+	rollbackErr := &rollbackError{cause: cause}
+	for i := len(t.steps) - 1; i >= 0; i-- {
+		step := t.steps[i]
+		if err := step.undo(ctx); err != nil {
+			rollbackErr.failures = append(rollbackErr.failures, step.resource+": "+err.Error())
+			rollbackErr.recovery = append(rollbackErr.recovery, step.recovery...)
+		}
+	}
+	t.steps = nil
+
+	if len(rollbackErr.failures) == 0 {
+		return cause
+	}
+	return rollbackErr
+}
+
+// rollbackError is the error of a command whose created resources couldn't all
+// be removed again
+type rollbackError struct {
+	cause    error
+	failures []string
+	recovery []string
+}
+
+func (e *rollbackError) Error() string {
+	return fmt.Sprintf(content.ErrorRollbackFailed, e.cause, strings.Join(e.failures, "\n  "), strings.Join(e.recovery, "\n  "))
+}
+
+// Cause returns the error that made the command fail, the exit code is the one of that error
+func (e *rollbackError) Cause() error {
+	return e.cause
+}
+
+// awsRoleRecovery returns the AWS CLI commands deleting a role created with the
+// managed policy or, when inline is set, with the inline policy
+func awsRoleRecovery(roleName, policy string, inline bool, profile string) []string {
+	var options string
+	if profile != "" {
+		options = " --profile " + profile
+	}
+	removePolicy := fmt.Sprintf(content.RecoveryAWSDetachPolicy, roleName, policy)
+	if inline {
+		removePolicy = fmt.Sprintf(content.RecoveryAWSDeleteInlinePolicy, roleName, aws.InlinePolicyName)
+	}
+	return []string{
+		removePolicy + options,
+		fmt.Sprintf(content.RecoveryAWSDeleteRole, roleName) + options,
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoll(t *testing.T) {
+	calls := 0
+	done, err := poll(context.Background(), backoff{Timeout: time.Second}, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	assert.True(t, done)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+
+	// without time left check is called once
+	calls = 0
+	done, err = poll(context.Background(), backoff{Min: time.Second, Timeout: time.Second / 2}, func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	assert.False(t, done)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, calls)
+
+	_, err = poll(context.Background(), backoff{Timeout: time.Second}, func() (bool, error) {
+		return false, errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = poll(ctx, backoff{Min: time.Second, Timeout: time.Minute}, func() (bool, error) {
+		return false, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestTransactionRollback(t *testing.T) {
+	var undone []string
+	tx := &transaction{}
+	for _, name := range []string{"role", "account", "stream"} {
+		name := name
+		tx.record(name, []string{"remove " + name}, func(ctx context.Context) error {
+			undone = append(undone, name)
+			if name == "role" {
+				return errors.New("AccessDenied")
+			}
+			return nil
+		})
+	}
+
+	cause := &client.APIError{StatusCode: http.StatusUnprocessableEntity}
+	err := tx.rollback(cause)
+	assert.Equal(t, []string{"stream", "account", "role"}, undone)
+	assert.Contains(t, err.Error(), "These resources couldn't be removed again:\n  role: AccessDenied\nRemove them with:\n  remove role")
+	assert.Equal(t, exitCodeInvalidRequest, exitCode(err))
+
+	// nothing is left to undo
+	assert.Equal(t, cause, tx.rollback(cause))
+}
+
+func TestCloudAccountCreateRollback(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{Timeout: time.Second}
+
+	frc := &fakeReleaseClient{
+		cloudAccounts:    []*client.CloudAccount{{ID: "cloud-1"}},
+		validations:      []client.RoleReValidationResult{{Message: "can't assume role"}},
+		validationResult: client.RoleReValidationResult{IsValid: true},
+	}
+	provider := &fakeCloudProvider{arn: "arn:aws:iam::123456789012:role/vss"}
+	create := &cloudCreateCmd{out: &bytes.Buffer{}, client: frc, cloud: provider, roleName: "vss", provider: "AWS"}
+	_, err := create.create()
+	assert.Nil(t, err)
+	assert.Empty(t, frc.deleted)
+
+	// the account and the role are deleted when the account doesn't validate
+	propagationBackoff = backoff{}
+	frc.validationResult = client.RoleReValidationResult{Message: "can't assume role"}
+	_, err = create.create()
+	assert.EqualError(t, err, "The cloud account isn't valid with the new role after 0s, can't assume role")
+	assert.Equal(t, []string{"cloud-1"}, frc.deleted)
+	assert.Equal(t, []string{"vss"}, provider.deleted)
+
+	// the commands removing the role are given when it can't be deleted
+	provider.deleteErr = errors.New("AccessDenied")
+	create.awsProfile = "admin"
+	create.policy = "arn:aws:iam::aws:policy/SecurityAudit"
+	_, err = create.create()
+	assert.EqualError(t, err, `The cloud account isn't valid with the new role after 0s, can't assume role
+These resources couldn't be removed again:
+  IAM role vss in AWS account 123456789012: AccessDenied
+Remove them with:
+  aws iam detach-role-policy --role-name vss --policy-arn arn:aws:iam::aws:policy/SecurityAudit --profile admin
+  aws iam delete-role --role-name vss --profile admin`)
+}
+
+func TestWaitUntilValidRetriesTransientErrors(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{Timeout: time.Second}
+
+	frc := &fakeReleaseClient{
+		validationErrs:   []error{&client.APIError{StatusCode: http.StatusServiceUnavailable}, errors.New("connection reset")},
+		validationResult: client.RoleReValidationResult{IsValid: true},
+	}
+	res, err := waitUntilValid(frc, "cloud-1")
+	assert.Nil(t, err)
+	assert.True(t, res.IsValid)
+
+	// other API errors don't pass by waiting
+	frc.validationErrs = []error{&client.APIError{StatusCode: http.StatusUnauthorized}}
+	_, err = waitUntilValid(frc, "cloud-1")
+	assert.True(t, client.IsUnauthorized(err))
+
+	// the last error is returned when the timeout passes
+	propagationBackoff = backoff{}
+	frc.validationErrs = []error{&client.APIError{StatusCode: http.StatusServiceUnavailable}}
+	_, err = waitUntilValid(frc, "cloud-1")
+	assert.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+}
+
+func TestCloudAccountCreateRetriesRoleValidation(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{Timeout: time.Second}
+
+	frc := &fakeReleaseClient{
+		cloudAccounts:    []*client.CloudAccount{{ID: "cloud-1"}},
+		createErrs:       []error{&client.APIError{StatusCode: http.StatusBadRequest, Message: "can't assume role"}},
+		validationResult: client.RoleReValidationResult{IsValid: true},
+	}
+	provider := &fakeCloudProvider{arn: "arn:aws:iam::123456789012:role/vss"}
+	create := &cloudCreateCmd{out: &bytes.Buffer{}, client: frc, cloud: provider, roleName: "vss", provider: "AWS"}
+	cloud, err := create.create()
+	assert.Nil(t, err)
+	assert.Equal(t, "cloud-1", cloud.ID)
+	assert.Empty(t, provider.deleted)
+
+	// the role is deleted when the account still can't be added after the timeout
+	propagationBackoff = backoff{}
+	frc.createErrs = []error{&client.APIError{StatusCode: http.StatusBadRequest, Message: "can't assume role"}}
+	_, err = create.create()
+	assert.EqualError(t, err, "can't assume role")
+	assert.Equal(t, []string{"vss"}, provider.deleted)
+}
+
+func TestCreateCloudAccountRetries(t *testing.T) {
+	defer func() { propagationBackoff = defaultPropagationBackoff }()
+	propagationBackoff = backoff{Timeout: time.Second}
+
+	tests := []struct {
+		desc  string
+		err   error
+		retry bool
+	}{
+		{"role not propagated", &client.APIError{StatusCode: http.StatusBadRequest, Code: "AccessDenied", Message: "Not authorized to perform sts:AssumeRole"}, true},
+		{"role not propagated without JSON", &client.APIError{StatusCode: http.StatusUnprocessableEntity, Body: "Unable to assume the role"}, true},
+		{"rate limited", &client.APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"invalid account", &client.APIError{StatusCode: http.StatusBadRequest, Message: "name is already used"}, false},
+		{"server error", &client.APIError{StatusCode: http.StatusBadGateway}, false},
+		{"no response", errors.New("net/http: timeout awaiting response headers"), false},
+	}
+
+	for _, tt := range tests {
+		frc := &fakeReleaseClient{cloudAccounts: []*client.CloudAccount{{ID: "cloud-1"}}, createErrs: []error{tt.err}}
+		cloud, err := createCloudAccount(frc, &client.CreateCloudAccountInput{})
+		if tt.retry {
+			assert.Nil(t, err, tt.desc)
+			assert.Equal(t, "cloud-1", cloud.ID, tt.desc)
+		} else {
+			assert.Equal(t, tt.err, err, tt.desc)
+		}
+	}
+}
+
+func TestCloudAccountCreateRollbackRoleWithoutPolicy(t *testing.T) {
+	frc := &fakeReleaseClient{cloudAccounts: []*client.CloudAccount{{ID: "cloud-1"}}}
+	// the role is created but its policy couldn't be attached
+	provider := &fakeCloudProvider{arn: "arn:aws:iam::123456789012:role/vss", err: errors.New("AttachRolePolicy failed")}
+	create := &cloudCreateCmd{out: &bytes.Buffer{}, client: frc, cloud: provider, roleName: "vss", provider: "AWS"}
+	_, err := create.create()
+	assert.NotNil(t, err)
+	assert.Nil(t, frc.created)
+	assert.Equal(t, []string{"vss"}, provider.deleted)
+}
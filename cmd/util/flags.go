@@ -16,6 +16,9 @@ package util
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/CloudCoreo/cli/cmd/content"
 )
@@ -72,13 +75,27 @@ func CheckCloudAddFlagsForAWS(externalID, roleArn, roleName, environment string)
 }
 
 // CheckCloudAddFlags flag check for cloud add command when adding azure cloud account
-func CheckCloudAddFlagsForAzure(keyValue, applicationID, directoryID, subscriptionID, environment string) error {
-	if keyValue == "" || applicationID == "" || directoryID == "" || subscriptionID == "" {
+func CheckCloudAddFlagsForAzure(keyValue, applicationID, directoryID, subscriptionID, roleName, environment string) error {
+	if roleName != "" && subscriptionID == "" {
+		return fmt.Errorf("Please provide the Subscription ID to assign the roles of the new service principal in ")
+	}
+	if roleName == "" && (keyValue == "" || applicationID == "" || directoryID == "" || subscriptionID == "") {
 		return fmt.Errorf("Please provide all the required info: Key Value, Application ID, Directory ID and Subscription ID ")
 	}
 	return checkEnvironment(environment)
 }
 
+// CheckCloudAddFlagsForGCP flag check for cloud add command when adding GCP cloud account
+func CheckCloudAddFlagsForGCP(projectID, serviceAccountEmail, serviceAccountKey, workloadIdentityProvider, roleName, environment string) error {
+	if projectID == "" {
+		return fmt.Errorf("Please provide the Project ID of the GCP project ")
+	}
+	if roleName == "" && serviceAccountKey == "" && (serviceAccountEmail == "" || workloadIdentityProvider == "") {
+		return fmt.Errorf("Please either provide the name of the new service account, the key of an existing one or its email and a workload identity provider ")
+	}
+	return checkEnvironment(environment)
+}
+
 func checkEnvironment(environment string) error {
 	envSet := map[string]bool{
 		"Production":  true,
@@ -120,9 +137,53 @@ func CheckAPIKeyFlag(apiKey string, userProfile string) (string, error) {
 	return apiKey, nil
 }
 
+// CheckCSPEndpointFlag falls back to the CSP endpoint of the profile when the flag is not set
+func CheckCSPEndpointFlag(cspEndpoint string, userProfile string) string {
+	return CheckProfileFlag(cspEndpoint, userProfile, content.CSPEndpoint)
+}
+
+// CheckProfileFlag falls back to the value of profileKey in the profile when the flag is not set
+func CheckProfileFlag(value, userProfile, profileKey string) string {
+	if value == "" {
+		if v := GetValueFromConfig(fmt.Sprintf("%s.%s", userProfile, profileKey), false); v != content.None {
+			value = v
+		}
+	}
+
+	return value
+}
+
 func CheckProviderFlag(provider string) error {
-	if provider != "AWS" && provider != "Azure" {
+	if provider != "AWS" && provider != "Azure" && provider != "GCP" {
 		return fmt.Errorf(content.ErrorProviderNotSupported)
 	}
 	return nil
 }
+
+// ParseTimeFlag parses an RFC3339 time or a duration before now such as 90m, 24h or 7d,
+// it returns nil when the flag is not set
+func ParseTimeFlag(flag, value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	var d time.Duration
+	var err error
+	if strings.HasSuffix(value, "d") {
+		var days int
+		days, err = strconv.Atoi(strings.TrimSuffix(value, "d"))
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf(content.ErrorInvalidTime, value, flag)
+	}
+
+	t := now.Add(-d)
+	return &t, nil
+}
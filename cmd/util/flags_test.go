@@ -2,6 +2,7 @@ package util
 
 import (
 	"testing"
+	"time"
 
 	"github.com/CloudCoreo/cli/cmd/content"
 
@@ -47,3 +48,62 @@ func TestCheckCloudAddFlagsFailure(t *testing.T) {
 	assert.NotNil(t, err, "TestCloudAddFlagsFailure should return error")
 	assert.Equal(t, "Please either provide both externalID and roleArn or the name of the new role ", err.Error())
 }
+
+func TestCheckCloudAddFlagsForGCP(t *testing.T) {
+	assert.Nil(t, CheckCloudAddFlagsForGCP("project-1", "", "", "", "vss", ""))
+	assert.Nil(t, CheckCloudAddFlagsForGCP("project-1", "", "key.json", "", "", "Production"))
+	assert.Nil(t, CheckCloudAddFlagsForGCP("project-1", "vss@project-1.iam.gserviceaccount.com", "", "projects/1/locations/global/workloadIdentityPools/vss/providers/aws", "", ""))
+
+	assert.EqualError(t, CheckCloudAddFlagsForGCP("", "", "", "", "vss", ""), "Please provide the Project ID of the GCP project ")
+	assert.NotNil(t, CheckCloudAddFlagsForGCP("project-1", "vss@project-1.iam.gserviceaccount.com", "", "", "", ""))
+	assert.NotNil(t, CheckCloudAddFlagsForGCP("project-1", "", "", "", "vss", "Prod"))
+}
+
+func TestCheckCloudAddFlagsForAzure(t *testing.T) {
+	assert.Nil(t, CheckCloudAddFlagsForAzure("key", "app-1", "tenant-1", "sub-1", "", "Production"))
+	assert.Nil(t, CheckCloudAddFlagsForAzure("", "", "", "sub-1", "vss", ""))
+
+	assert.NotNil(t, CheckCloudAddFlagsForAzure("", "", "", "", "vss", ""))
+	assert.NotNil(t, CheckCloudAddFlagsForAzure("", "app-1", "tenant-1", "sub-1", "", ""))
+}
+
+func TestCheckProviderFlag(t *testing.T) {
+	for _, provider := range []string{"AWS", "Azure", "GCP"} {
+		assert.Nil(t, CheckProviderFlag(provider))
+	}
+	assert.EqualError(t, CheckProviderFlag("gcp"), content.ErrorProviderNotSupported)
+}
+
+func TestCheckCSPEndpointFlag(t *testing.T) {
+	assert.Equal(t, "https://csp.test", CheckCSPEndpointFlag("https://csp.test", "default"))
+	assert.Equal(t, "", CheckCSPEndpointFlag("", "invalid"))
+}
+
+func TestCheckProfileFlag(t *testing.T) {
+	assert.Equal(t, "http://proxy.test", CheckProfileFlag("http://proxy.test", "default", content.Proxy))
+	assert.Equal(t, "", CheckProfileFlag("", "invalid", content.Proxy))
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	v, err := ParseTimeFlag("since", "", now)
+	assert.Nil(t, err)
+	assert.Nil(t, v)
+
+	v, err = ParseTimeFlag("since", "2020-01-01T00:00:00Z", now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), *v)
+
+	v, err = ParseTimeFlag("since", "90m", now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.Add(-90*time.Minute), *v)
+
+	v, err = ParseTimeFlag("since", "7d", now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 1, 3, 12, 0, 0, 0, time.UTC), *v)
+
+	_, err = ParseTimeFlag("until", "yesterday", now)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "--until")
+}
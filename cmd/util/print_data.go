@@ -25,8 +25,10 @@ import (
 
 	"io"
 
+	"github.com/CloudCoreo/cli/client"
 	"github.com/CloudCoreo/cli/cmd/content"
 	"github.com/bndr/gotabulate"
+	"github.com/pkg/errors"
 )
 
 // Table struct
@@ -179,12 +181,34 @@ func PrettyJSON(obj interface{}) string {
 	return string(buf.String())
 }
 
-//PrintError print error
+type errorResult struct {
+	Error interface{} `json:"error"`
+}
+
+// ReportedError is returned by commands that already printed the details of
+// their failure as part of their result
+type ReportedError struct {
+	Message string
+}
+
+func (e *ReportedError) Error() string {
+	return e.Message
+}
+
+//PrintError print error, API errors keep their status, code and request id in json format
 func PrintError(err error, json bool) {
+	if _, ok := errors.Cause(err).(*ReportedError); ok && json {
+		// the JSON result already describes the failure
+		return
+	}
 	if json {
-		PrettyPrintJSON(err)
+		var obj interface{} = map[string]string{"message": err.Error()}
+		if apiErr, ok := errors.Cause(err).(*client.APIError); ok {
+			obj = apiErr
+		}
+		PrettyPrintJSON(errorResult{Error: obj})
 	} else {
-		fmt.Fprintf(os.Stderr, err.Error())
+		fmt.Fprintln(os.Stderr, "Error:", err.Error())
 	}
 }
 
@@ -192,6 +216,9 @@ func PrintError(err error, json bool) {
 func PrintResult(out io.Writer, t interface{}, headers []string, headersMap map[string]string, json, verbose bool) {
 	if json {
 		PrettyPrintJSON(t)
+	} else if rows, ok := t.([]interface{}); ok && len(rows) == 0 {
+		// the table can't render without rows
+		fmt.Fprintln(out, content.InfoNoResults)
 	} else {
 		table := NewTable()
 		table.SetHeader(headers)
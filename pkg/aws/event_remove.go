@@ -1,8 +1,14 @@
 package aws
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 
 	"github.com/aws/aws-sdk-go/service/sns"
@@ -12,82 +18,82 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 )
 
+// stackDeleteDelay is the delay between the checks whether a stack is deleted
+var stackDeleteDelay = 30 * time.Second
+
 //RemoveService contains info needed for AWS event stream removal
 type RemoveService struct {
-	awsProfilePath string
-	awsProfile     string
+	sessions *sessionFactory
 }
 
 // NewRemoveService returns an instance of RemoveService
 func NewRemoveService(input *NewServiceInput) *RemoveService {
 	return &RemoveService{
-		awsProfile:     input.AwsProfile,
-		awsProfilePath: input.AwsProfilePath,
+		sessions: newSessionFactory(input),
 	}
 }
 
-func (a *RemoveService) newSession() (*session.Session, error) {
-	var sess *session.Session
-	var err error
-
-	if a.awsProfile != "" {
-		if a.awsProfilePath != "" {
-			sess, err = session.NewSessionWithOptions(session.Options{Profile: a.awsProfile, SharedConfigFiles: []string{a.awsProfilePath}, SharedConfigState: session.SharedConfigEnable})
-		} else {
-			sess, err = session.NewSessionWithOptions(session.Options{Profile: a.awsProfile, SharedConfigState: session.SharedConfigEnable})
-		}
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		sess, err = session.NewSession()
-		if err != nil {
-			return nil, err
-		}
-	}
-	return sess, nil
-}
-
-func (a *RemoveService) snsPublish(sess *session.Session, arnType, region, cloudAccountID, topicName string) error {
+func (a *RemoveService) snsPublish(ctx context.Context, sess *session.Session, arnType, region, cloudAccountID, topicName string) error {
 	svc := sns.New(sess, aws.NewConfig().WithRegion(region))
 	topicArn := fmt.Sprintf("arn:%s:sns:%s:%s:%s", arnType, region, cloudAccountID, topicName)
 	publishInput := &sns.PublishInput{
 		Message:  aws.String("UnsubscribeConfirmation"),
 		TopicArn: aws.String(topicArn),
 	}
-	_, err := svc.Publish(publishInput)
+	_, err := svc.PublishWithContext(ctx, publishInput)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == sns.ErrCodeNotFoundException {
+		// the topic is removed with the stack
+		return nil
+	}
 	return err
 }
 
-//RemoveEventStream perform the same function as event stream removal script
-func (a *RemoveService) RemoveEventStream(input *client.EventRemoveConfig) error {
+//RemoveEventStream perform the same function as event stream removal script. The stacks of all
+//regions are deleted even when some of them fail, the failures are returned together.
+func (a *RemoveService) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
 	regions := input.Regions
-	sess, err := a.newSession()
+	sess, err := a.sessions.session()
 	if err != nil {
 		return err
 	}
 	fmt.Println("Deactivating devTime for cloud account", input.CloudAccountID)
+	var failures []string
 	for _, region := range regions {
-		err := a.snsPublish(sess, input.ArnType, region, input.CloudAccountID, input.TopicName)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := a.snsPublish(ctx, sess, input.ArnType, region, input.CloudAccountID, input.TopicName)
 		if err != nil {
-			fmt.Println(err.Error())
+			failures = append(failures, "Unsubscribe "+input.TopicName+" on "+region+" failed, "+err.Error())
 		}
 
 		// Delete stack
-		err = a.deleteStack(sess, region, input.StackName)
+		err = a.deleteStack(ctx, sess, region, input.StackName)
 		if err != nil {
-			fmt.Println(err.Error())
+			failures = append(failures, "Delete stack "+input.StackName+" on "+region+" failed, "+err.Error())
 		}
 	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
 	return nil
 }
 
-func (a *RemoveService) deleteStack(sess *session.Session, region, stackName string) error {
+// deleteStack deletes the stack and waits until it is deleted, so that the
+// role the stack uses is only deleted afterwards
+func (a *RemoveService) deleteStack(ctx context.Context, sess *session.Session, region, stackName string) error {
 	fmt.Println("Deleting", stackName, "on", region)
 	cloudFormation := cloudformation.New(sess, aws.NewConfig().WithRegion(region))
 	deleteStackInput := &cloudformation.DeleteStackInput{
 		StackName: aws.String(stackName),
 	}
-	_, err := cloudFormation.DeleteStack(deleteStackInput)
-	return err
+	if _, err := cloudFormation.DeleteStackWithContext(ctx, deleteStackInput); err != nil {
+		return err
+	}
+	return cloudFormation.WaitUntilStackDeleteCompleteWithContext(ctx,
+		&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)},
+		request.WithWaiterDelay(request.ConstantWaiterDelay(stackDeleteDelay)))
 }
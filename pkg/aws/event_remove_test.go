@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRemoveService returns a RemoveService calling a fake SNS and CloudFormation API, whose
+// DeleteStack fails in failRegion. The calls are recorded as "region Action".
+func newTestRemoveService(t *testing.T, failRegion string) (*RemoveService, *[]string) {
+
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		// the region is part of the credential scope, Credential=AKID/DATE/REGION/SERVICE/aws4_request
+		scope := strings.Split(strings.SplitN(r.Header.Get("Authorization"), "Credential=", 2)[1], "/")
+		action := r.PostForm.Get("Action")
+		calls = append(calls, scope[2]+" "+action)
+
+		switch {
+		case action == "Publish":
+			fmt.Fprint(w, `<PublishResponse><PublishResult><MessageId>1</MessageId></PublishResult></PublishResponse>`)
+		case action == "DeleteStack" && scope[2] == failRegion:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>Not allowed</Message></Error></ErrorResponse>`)
+		case action == "DeleteStack":
+			fmt.Fprint(w, `<DeleteStackResponse></DeleteStackResponse>`)
+		case action == "DescribeStacks":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>ValidationError</Code><Message>Stack with id vss does not exist</Message></Error></ErrorResponse>`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := NewRemoveService(&NewServiceInput{Credentials: credentials.NewStaticCredentials("AKID", "secret", "")})
+	s.sessions.config = &aws.Config{Endpoint: aws.String(server.URL), Region: aws.String("us-east-1")}
+	return s, &calls
+}
+
+func TestRemoveEventStream(t *testing.T) {
+	s, calls := newTestRemoveService(t, "")
+	defer func(delay time.Duration) { stackDeleteDelay = delay }(stackDeleteDelay)
+	stackDeleteDelay = time.Millisecond
+
+	err := s.RemoveEventStream(context.Background(), &client.EventRemoveConfig{
+		Regions: []string{"us-east-1"}, ArnType: "aws", CloudAccountID: "111111111111", TopicName: "vss", StackName: "vss",
+	})
+	assert.Nil(t, err)
+	// the stack is deleted once DescribeStacks doesn't find it anymore
+	assert.Equal(t, []string{"us-east-1 Publish", "us-east-1 DeleteStack", "us-east-1 DescribeStacks"}, *calls)
+}
+
+func TestRemoveEventStreamFailure(t *testing.T) {
+	s, calls := newTestRemoveService(t, "eu-west-1")
+	defer func(delay time.Duration) { stackDeleteDelay = delay }(stackDeleteDelay)
+	stackDeleteDelay = time.Millisecond
+
+	err := s.RemoveEventStream(context.Background(), &client.EventRemoveConfig{
+		Regions: []string{"eu-west-1", "us-east-1"}, ArnType: "aws", CloudAccountID: "111111111111", TopicName: "vss", StackName: "vss",
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Delete stack vss on eu-west-1 failed")
+	// the other regions are still removed
+	assert.Contains(t, *calls, "us-east-1 DescribeStacks")
+}
@@ -0,0 +1,272 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/pkg/command"
+	"github.com/aws/aws-sdk-go/aws"
+	awsclient "github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/jsonrpc"
+)
+
+// DefaultCrossAccountRole is the role AWS Organizations creates in new member accounts
+const DefaultCrossAccountRole = "OrganizationAccountAccessRole"
+
+// DefaultRoleSessionName is the session name of roles assumed in member accounts
+const DefaultRoleSessionName = "vss-cli"
+
+// OrganizationService lists the accounts of an AWS organization from its
+// management account and acts in member accounts by assuming a cross-account role
+type OrganizationService struct {
+	// sessions creates the session of the management account
+	sessions         *sessionFactory
+	crossAccountRole string
+	roleSessionName  string
+	duration         int64
+	// config overrides the session config of the Organizations API
+	config *aws.Config
+}
+
+// NewOrganizationService returns a new OrganizationService
+func NewOrganizationService(input *NewServiceInput) *OrganizationService {
+	o := &OrganizationService{
+		sessions:         newSessionFactory(input),
+		crossAccountRole: input.CrossAccountRole,
+		roleSessionName:  input.RoleSessionName,
+		duration:         input.Duration,
+	}
+	if o.crossAccountRole == "" {
+		o.crossAccountRole = DefaultCrossAccountRole
+	}
+	if o.roleSessionName == "" {
+		o.roleSessionName = DefaultRoleSessionName
+	}
+	return o
+}
+
+// ListAccounts returns the active accounts of the organization with the
+// organizational units containing them
+func (o *OrganizationService) ListAccounts(ctx context.Context) ([]*client.OrganizationAccount, error) {
+	sess, err := o.sessions.session()
+	if err != nil {
+		return nil, err
+	}
+	api := newOrganizationsAPI(sess, o.config)
+
+	org := &describeOrganizationOutput{}
+	if err := api.call(ctx, "DescribeOrganization", &struct{}{}, org); err != nil {
+		return nil, err
+	}
+	managementID := aws.StringValue(org.Organization.MasterAccountID)
+
+	var accounts []*client.OrganizationAccount
+	var walk func(parentID string, units []client.OrganizationalUnit) error
+	walk = func(parentID string, units []client.OrganizationalUnit) error {
+		err := api.pages(ctx, "ListAccountsForParent", &listForParentInput{ParentID: aws.String(parentID)}, func() pagedOutput { return &listAccountsOutput{} }, func(page pagedOutput) {
+			for _, account := range page.(*listAccountsOutput).Accounts {
+				if aws.StringValue(account.Status) != "ACTIVE" {
+					continue
+				}
+				accounts = append(accounts, &client.OrganizationAccount{
+					ID:                  aws.StringValue(account.ID),
+					Name:                aws.StringValue(account.Name),
+					Email:               aws.StringValue(account.Email),
+					Status:              aws.StringValue(account.Status),
+					Management:          aws.StringValue(account.ID) == managementID,
+					OrganizationalUnits: units,
+				})
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		var children []client.OrganizationalUnit
+		err = api.pages(ctx, "ListOrganizationalUnitsForParent", &listForParentInput{ParentID: aws.String(parentID)}, func() pagedOutput { return &listUnitsOutput{} }, func(page pagedOutput) {
+			for _, unit := range page.(*listUnitsOutput).OrganizationalUnits {
+				children = append(children, client.OrganizationalUnit{ID: aws.StringValue(unit.ID), Name: aws.StringValue(unit.Name)})
+			}
+		})
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			path := append(append([]client.OrganizationalUnit(nil), units...), child)
+			if err := walk(child.ID, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var roots []string
+	err = api.pages(ctx, "ListRoots", &listRootsInput{}, func() pagedOutput { return &listUnitsOutput{} }, func(page pagedOutput) {
+		for _, root := range page.(*listUnitsOutput).Roots {
+			roots = append(roots, aws.StringValue(root.ID))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		if err := walk(root, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return accounts, nil
+}
+
+// AccountProvider returns the Service acting in an account of the organization,
+// with the session of the management account for it and with the cross-account
+// role assumed for member accounts
+func (o *OrganizationService) AccountProvider(ctx context.Context, account *client.OrganizationAccount) (command.CloudProvider, error) {
+	if account.Management {
+		return newService(&o.sessions.input, o.sessions), nil
+	}
+
+	sess, err := o.sessions.session()
+	if err != nil {
+		return nil, err
+	}
+	roleArn := fmt.Sprintf("arn:aws:iam::%s:role/%s", account.ID, o.crossAccountRole)
+	creds := stscreds.NewCredentials(sess, roleArn, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = o.roleSessionName
+		if o.duration > 0 {
+			p.Duration = time.Duration(o.duration) * time.Second
+		}
+	})
+	// the member keeps the settings of the input, its credentials replace the
+	// profile and role it was assumed with
+	input := o.sessions.input
+	input.Credentials = creds
+	input.AssumeRoleArn, input.ExternalID, input.MFASerial, input.WebIdentityTokenFile = "", "", "", ""
+	return NewService(&input), nil
+}
+
+// organizationsAPI calls the AWS Organizations JSON API, which the vendored SDK has no client for
+type organizationsAPI struct {
+	*awsclient.Client
+}
+
+func newOrganizationsAPI(p awsclient.ConfigProvider, cfgs ...*aws.Config) *organizationsAPI {
+	c := p.ClientConfig("organizations", cfgs...)
+	api := &organizationsAPI{
+		Client: awsclient.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:   "organizations",
+				ServiceID:     "Organizations",
+				SigningName:   c.SigningName,
+				SigningRegion: c.SigningRegion,
+				Endpoint:      c.Endpoint,
+				APIVersion:    "2016-11-28",
+				JSONVersion:   "1.1",
+				TargetPrefix:  "AWSOrganizationsV20161128",
+			},
+			c.Handlers,
+		),
+	}
+
+	api.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	api.Handlers.Build.PushBackNamed(jsonrpc.BuildHandler)
+	api.Handlers.Unmarshal.PushBackNamed(jsonrpc.UnmarshalHandler)
+	api.Handlers.UnmarshalMeta.PushBackNamed(jsonrpc.UnmarshalMetaHandler)
+	api.Handlers.UnmarshalError.PushBackNamed(jsonrpc.UnmarshalErrorHandler)
+	return api
+}
+
+func (api *organizationsAPI) call(ctx context.Context, operation string, input, output interface{}) error {
+	req := api.NewRequest(&request.Operation{Name: operation, HTTPMethod: "POST", HTTPPath: "/"}, input, output)
+	req.SetContext(ctx)
+	return req.Send()
+}
+
+// pagedOutput is the output of a list operation
+type pagedOutput interface {
+	nextToken() *string
+}
+
+// pagedInput is the input of a list operation
+type pagedInput interface {
+	setNextToken(token *string)
+}
+
+// pages calls a list operation until the last page, passing every page to fn
+func (api *organizationsAPI) pages(ctx context.Context, operation string, input pagedInput, newOutput func() pagedOutput, fn func(pagedOutput)) error {
+	for {
+		output := newOutput()
+		if err := api.call(ctx, operation, input, output); err != nil {
+			return err
+		}
+		fn(output)
+
+		token := output.nextToken()
+		if aws.StringValue(token) == "" {
+			return nil
+		}
+		input.setNextToken(token)
+	}
+}
+
+type describeOrganizationOutput struct {
+	_            struct{} `type:"structure"`
+	Organization struct {
+		_               struct{} `type:"structure"`
+		MasterAccountID *string  `locationName:"MasterAccountId" type:"string"`
+	} `type:"structure"`
+}
+
+type listRootsInput struct {
+	_         struct{} `type:"structure"`
+	NextToken *string  `type:"string"`
+}
+
+func (i *listRootsInput) setNextToken(token *string) { i.NextToken = token }
+
+type listForParentInput struct {
+	_         struct{} `type:"structure"`
+	ParentID  *string  `locationName:"ParentId" type:"string"`
+	NextToken *string  `type:"string"`
+}
+
+func (i *listForParentInput) setNextToken(token *string) { i.NextToken = token }
+
+type organizationAccount struct {
+	_      struct{} `type:"structure"`
+	ID     *string  `locationName:"Id" type:"string"`
+	Name   *string  `type:"string"`
+	Email  *string  `type:"string"`
+	Status *string  `type:"string"`
+}
+
+type listAccountsOutput struct {
+	_         struct{}               `type:"structure"`
+	Accounts  []*organizationAccount `type:"list"`
+	NextToken *string                `type:"string"`
+}
+
+func (o *listAccountsOutput) nextToken() *string { return o.NextToken }
+
+type organizationUnit struct {
+	_    struct{} `type:"structure"`
+	ID   *string  `locationName:"Id" type:"string"`
+	Name *string  `type:"string"`
+}
+
+// listUnitsOutput is the output of ListRoots and ListOrganizationalUnitsForParent
+type listUnitsOutput struct {
+	_                   struct{}            `type:"structure"`
+	Roots               []*organizationUnit `type:"list"`
+	OrganizationalUnits []*organizationUnit `type:"list"`
+	NextToken           *string             `type:"string"`
+}
+
+func (o *listUnitsOutput) nextToken() *string { return o.NextToken }
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOrganizationService(t *testing.T, handler http.HandlerFunc) *OrganizationService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &aws.Config{
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}
+	o := NewOrganizationService(&NewServiceInput{})
+	o.sessions.sess = session.Must(session.NewSession(cfg))
+	o.config = cfg
+	return o
+}
+
+func TestOrganizationListAccounts(t *testing.T) {
+	o := newTestOrganizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]string
+		json.NewDecoder(r.Body).Decode(&input)
+
+		switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AWSOrganizationsV20161128.") {
+		case "DescribeOrganization":
+			fmt.Fprint(w, `{"Organization": {"MasterAccountId": "111111111111"}}`)
+		case "ListRoots":
+			fmt.Fprint(w, `{"Roots": [{"Id": "r-root", "Name": "Root"}]}`)
+		case "ListAccountsForParent":
+			switch input["ParentId"] + input["NextToken"] {
+			case "r-root":
+				fmt.Fprint(w, `{"Accounts": [{"Id": "111111111111", "Name": "management", "Status": "ACTIVE"}], "NextToken": "page2"}`)
+			case "r-rootpage2":
+				fmt.Fprint(w, `{"Accounts": [{"Id": "999999999999", "Name": "closed", "Status": "SUSPENDED"}]}`)
+			case "ou-prod":
+				fmt.Fprint(w, `{"Accounts": [{"Id": "222222222222", "Name": "prod", "Email": "prod@example.com", "Status": "ACTIVE"}]}`)
+			default:
+				fmt.Fprint(w, `{"Accounts": []}`)
+			}
+		case "ListOrganizationalUnitsForParent":
+			if input["ParentId"] == "r-root" {
+				fmt.Fprint(w, `{"OrganizationalUnits": [{"Id": "ou-prod", "Name": "Production"}]}`)
+			} else {
+				fmt.Fprint(w, `{"OrganizationalUnits": []}`)
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	accounts, err := o.ListAccounts(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []*client.OrganizationAccount{
+		{ID: "111111111111", Name: "management", Status: "ACTIVE", Management: true},
+		{ID: "222222222222", Name: "prod", Email: "prod@example.com", Status: "ACTIVE", OrganizationalUnits: []client.OrganizationalUnit{{ID: "ou-prod", Name: "Production"}}},
+	}, accounts)
+}
+
+func TestOrganizationListAccountsError(t *testing.T) {
+	o := newTestOrganizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type": "AWSOrganizationsNotInUseException", "message": "not in an organization"}`)
+	})
+
+	_, err := o.ListAccounts(context.Background())
+	assert.Contains(t, err.Error(), "AWSOrganizationsNotInUseException")
+}
+
+func TestOrganizationAccountProvider(t *testing.T) {
+	hc := &http.Client{}
+	o := NewOrganizationService(&NewServiceInput{
+		AwsProfile:          "management",
+		Duration:            900,
+		AssumeRoleArn:       "arn:aws:iam::111111111111:role/admin",
+		ExternalID:          "external-1",
+		IgnoreMissingTrails: true,
+		HTTPClient:          hc,
+	})
+	o.sessions.sess = session.Must(session.NewSession())
+
+	provider, err := o.AccountProvider(context.Background(), &client.OrganizationAccount{ID: "222222222222"})
+	assert.Nil(t, err)
+	member := provider.(*Service)
+	assert.NotNil(t, member.role.sessions.input.Credentials)
+	// the member keeps the settings of the input but not the role assumed in the management account
+	assert.Equal(t, hc, member.role.sessions.input.HTTPClient)
+	assert.True(t, member.setup.ignoreMissingTrail)
+	assert.Empty(t, member.role.sessions.input.AssumeRoleArn)
+	_, err = member.role.sessions.session()
+	assert.Nil(t, err)
+
+	provider, err = o.AccountProvider(context.Background(), &client.OrganizationAccount{ID: "111111111111", Management: true})
+	assert.Nil(t, err)
+	// the management account shares the session listing the organization
+	assert.Equal(t, o.sessions, provider.(*Service).role.sessions)
+	assert.Equal(t, o.sessions, provider.(*Service).setup.sessions)
+}
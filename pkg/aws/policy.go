@@ -0,0 +1,228 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+// InlinePolicyName is the name of the inline policy put on the role of a cloud account
+const InlinePolicyName = "VSSSecureStateReadOnly"
+
+// maxInlinePolicySize is the IAM limit of the inline policies of a role, in characters
+const maxInlinePolicySize = 10240
+
+var (
+	servicePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+	// actions are named in full, a wildcard would grant actions added to the service later
+	actionPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+// DefaultPolicyCatalog are the read-only actions VSS calls, it is used when
+// the VSS configuration has no catalog
+var DefaultPolicyCatalog = client.PolicyCatalog{
+	"acm":            {"DescribeCertificate", "ListCertificates"},
+	"autoscaling":    {"DescribeAutoScalingGroups", "DescribeLaunchConfigurations"},
+	"cloudformation": {"DescribeStacks", "ListStacks"},
+	"cloudfront":     {"GetDistributionConfig", "ListDistributions"},
+	"cloudtrail":     {"DescribeTrails", "GetEventSelectors", "GetTrailStatus"},
+	"cloudwatch":     {"DescribeAlarms"},
+	"config":         {"DescribeConfigurationRecorderStatus", "DescribeConfigurationRecorders", "DescribeDeliveryChannels"},
+	"dynamodb":       {"DescribeContinuousBackups", "DescribeTable", "ListTables"},
+	"ec2": {
+		"DescribeAddresses", "DescribeFlowLogs", "DescribeImages", "DescribeInstances", "DescribeNetworkAcls",
+		"DescribeNetworkInterfaces", "DescribeRegions", "DescribeRouteTables", "DescribeSecurityGroups",
+		"DescribeSnapshotAttribute", "DescribeSnapshots", "DescribeSubnets", "DescribeVolumes", "DescribeVpcs",
+		"GetEbsEncryptionByDefault",
+	},
+	"ecr":                  {"DescribeRepositories", "GetRepositoryPolicy"},
+	"ecs":                  {"DescribeClusters", "DescribeTaskDefinition", "ListClusters", "ListTaskDefinitions"},
+	"eks":                  {"DescribeCluster", "ListClusters"},
+	"elasticloadbalancing": {"DescribeListeners", "DescribeLoadBalancerAttributes", "DescribeLoadBalancers", "DescribeTargetGroups"},
+	"es":                   {"DescribeElasticsearchDomain", "ListDomainNames"},
+	"guardduty":            {"GetDetector", "ListDetectors"},
+	"iam": {
+		"GenerateCredentialReport", "GetAccountPasswordPolicy", "GetAccountSummary", "GetCredentialReport",
+		"GetPolicyVersion", "GetRolePolicy", "GetUserPolicy", "ListAccessKeys", "ListAttachedRolePolicies",
+		"ListAttachedUserPolicies", "ListEntitiesForPolicy", "ListGroups", "ListMFADevices", "ListPolicies",
+		"ListRolePolicies", "ListRoles", "ListUserPolicies", "ListUsers", "ListVirtualMFADevices",
+	},
+	"kms":      {"DescribeKey", "GetKeyPolicy", "GetKeyRotationStatus", "ListAliases", "ListKeys"},
+	"lambda":   {"GetFunctionConfiguration", "GetPolicy", "ListFunctions"},
+	"logs":     {"DescribeLogGroups", "DescribeMetricFilters"},
+	"rds":      {"DescribeDBClusters", "DescribeDBInstances", "DescribeDBSnapshotAttributes", "DescribeDBSnapshots"},
+	"redshift": {"DescribeClusters", "DescribeLoggingStatus"},
+	"s3": {
+		"GetAccountPublicAccessBlock", "GetBucketAcl", "GetBucketLocation", "GetBucketLogging", "GetBucketPolicy",
+		"GetBucketPolicyStatus", "GetBucketPublicAccessBlock", "GetBucketVersioning", "GetEncryptionConfiguration",
+		"ListAllMyBuckets",
+	},
+	"sns": {"GetTopicAttributes", "ListSubscriptions", "ListTopics"},
+	"sqs": {"GetQueueAttributes", "ListQueues"},
+}
+
+type policyStatement struct {
+	Sid      string
+	Effect   string
+	Action   []string
+	Resource string
+}
+
+type policyDocument struct {
+	Version   string
+	Statement []policyStatement
+}
+
+// PolicyDocument returns the policy allowing the actions of the catalog, one
+// statement per service, DefaultPolicyCatalog is used for an empty catalog.
+// The document is compact as IAM limits the size of inline policies.
+func PolicyDocument(catalog client.PolicyCatalog) (string, error) {
+	if len(catalog) == 0 {
+		catalog = DefaultPolicyCatalog
+	}
+
+	services := make([]string, 0, len(catalog))
+	for service := range catalog {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	document := policyDocument{Version: "2012-10-17"}
+	for _, service := range services {
+		if !servicePattern.MatchString(service) {
+			return "", fmt.Errorf("invalid service %q in the policy catalog", service)
+		}
+		actions, err := serviceActions(service, catalog[service])
+		if err != nil {
+			return "", err
+		}
+		if len(actions) == 0 {
+			continue
+		}
+		document.Statement = append(document.Statement, policyStatement{
+			Sid:      statementID(service),
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: "*",
+		})
+	}
+	if len(document.Statement) == 0 {
+		return "", errors.New("the policy catalog allows no action")
+	}
+
+	b, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	if len(b) > maxInlinePolicySize {
+		return "", fmt.Errorf("the policy has %d characters, more than the %d IAM allows for inline policies", len(b), maxInlinePolicySize)
+	}
+	return string(b), nil
+}
+
+// serviceActions returns the sorted actions of the service prefixed by it, without duplicates
+func serviceActions(service string, names []string) ([]string, error) {
+	seen := map[string]bool{}
+	actions := make([]string, 0, len(names))
+	for _, name := range names {
+		if !actionPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid action %q of service %s in the policy catalog", name, service)
+		}
+		action := service + ":" + name
+		if !seen[action] {
+			seen[action] = true
+			actions = append(actions, action)
+		}
+	}
+	sort.Strings(actions)
+	return actions, nil
+}
+
+// statementID returns the Sid of the statement of a service, only letters and digits are allowed
+func statementID(service string) string {
+	sid := "VSS"
+	for _, part := range strings.Split(service, "-") {
+		if part != "" {
+			sid += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return sid
+}
+
+// samePolicy tells whether two policy documents are equal once parsed
+func samePolicy(a, b string) bool {
+	var x, y interface{}
+	if json.Unmarshal([]byte(a), &x) != nil || json.Unmarshal([]byte(b), &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func (c *RoleService) putInlinePolicy(ctx context.Context, svc *iam.IAM, roleName, document string) error {
+	_, err := svc.PutRolePolicyWithContext(ctx, &iam.PutRolePolicyInput{
+		PolicyDocument: aws.String(document),
+		PolicyName:     aws.String(InlinePolicyName),
+		RoleName:       aws.String(roleName),
+	})
+	if err != nil {
+		return errors.New("Put inline policy " + InlinePolicyName + " for " + roleName + " failed, " + err.Error())
+	}
+	return nil
+}
+
+// UpdateInlinePolicy puts the inline policy allowing the actions of the catalog
+// on the role, it tells whether the policy of the role changed
+func (c *RoleService) UpdateInlinePolicy(ctx context.Context, roleName string, catalog client.PolicyCatalog) (bool, error) {
+	document, err := PolicyDocument(catalog)
+	if err != nil {
+		return false, err
+	}
+	sess, err := c.sessions.session()
+	if err != nil {
+		return false, err
+	}
+	svc := iam.New(sess)
+
+	current, err := svc.GetRolePolicyWithContext(ctx, &iam.GetRolePolicyInput{
+		PolicyName: aws.String(InlinePolicyName),
+		RoleName:   aws.String(roleName),
+	})
+	if err == nil {
+		// the policy document is returned URL encoded
+		existing, err := url.QueryUnescape(aws.StringValue(current.PolicyDocument))
+		if err == nil && samePolicy(existing, document) {
+			return false, nil
+		}
+	} else if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+		return false, errors.New("Get inline policy " + InlinePolicyName + " of " + roleName + " failed, " + err.Error())
+	}
+
+	if err := c.putInlinePolicy(ctx, svc, roleName, document); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *RoleService) getInlineRolePolicies(ctx context.Context, svc *iam.IAM, roleName string) ([]string, error) {
+	var names []string
+	input := &iam.ListRolePoliciesInput{
+		RoleName: &roleName,
+	}
+	err := svc.ListRolePoliciesPagesWithContext(ctx, input, func(output *iam.ListRolePoliciesOutput, last bool) bool {
+		names = append(names, aws.StringValueSlice(output.PolicyNames)...)
+		return true
+	})
+	return names, err
+}
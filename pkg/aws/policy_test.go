@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyDocument(t *testing.T) {
+	document, err := PolicyDocument(client.PolicyCatalog{
+		"s3":                   {"ListAllMyBuckets", "GetBucketAcl", "GetBucketAcl"},
+		"elasticloadbalancing": {"DescribeLoadBalancers"},
+		"sqs":                  {},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Version":"2012-10-17","Statement":[`+
+		`{"Sid":"VSSElasticloadbalancing","Effect":"Allow","Action":["elasticloadbalancing:DescribeLoadBalancers"],"Resource":"*"},`+
+		`{"Sid":"VSSS3","Effect":"Allow","Action":["s3:GetBucketAcl","s3:ListAllMyBuckets"],"Resource":"*"}]}`, document)
+}
+
+func TestPolicyDocumentDefaultCatalog(t *testing.T) {
+	document, err := PolicyDocument(nil)
+	assert.Nil(t, err)
+	assert.True(t, len(document) <= maxInlinePolicySize)
+
+	policy := policyDocument{}
+	assert.Nil(t, json.Unmarshal([]byte(document), &policy))
+	assert.Len(t, policy.Statement, len(DefaultPolicyCatalog))
+	for _, statement := range policy.Statement {
+		for _, action := range statement.Action {
+			// the default catalog only reads
+			name := action[strings.Index(action, ":")+1:]
+			assert.Regexp(t, "^(Describe|Get|List|GenerateCredentialReport)", name)
+		}
+	}
+}
+
+func TestPolicyDocumentInvalidCatalog(t *testing.T) {
+	for _, catalog := range []client.PolicyCatalog{
+		{"EC2": {"DescribeInstances"}},
+		{"ec2": {"Describe Instances"}},
+		{"ec2": {"*"}},
+		{"ec2": {"Describe*"}},
+		{"ec2": {}},
+		{"ec2": {strings.Repeat("DescribeInstances", 1000)}},
+	} {
+		_, err := PolicyDocument(catalog)
+		assert.NotNil(t, err, catalog)
+	}
+}
+
+func TestSamePolicy(t *testing.T) {
+	document, _ := PolicyDocument(client.PolicyCatalog{"s3": {"ListAllMyBuckets"}})
+	indented := `{
+		"Version": "2012-10-17",
+		"Statement": [{"Sid": "VSSS3", "Effect": "Allow", "Action": ["s3:ListAllMyBuckets"], "Resource": "*"}]
+	}`
+	assert.True(t, samePolicy(document, indented))
+	assert.False(t, samePolicy(document, strings.Replace(indented, "ListAllMyBuckets", "GetBucketAcl", 1)))
+	assert.False(t, samePolicy(document, "not json"))
+}
@@ -1,25 +1,27 @@
 package aws
 
 import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
 	"github.com/CloudCoreo/cli/client"
 	"github.com/pkg/errors"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
 )
 
 // RoleService interacts with aws role
 type RoleService struct {
-	awsProfilePath string
-	awsProfile     string
+	sessions *sessionFactory
 }
 
 // NewRoleService returns a new RoleService
 func NewRoleService(input *NewServiceInput) *RoleService {
 	return &RoleService{
-		awsProfile:     input.AwsProfile,
-		awsProfilePath: input.AwsProfilePath,
+		sessions: newSessionFactory(input),
 	}
 }
 
@@ -43,32 +45,49 @@ func (c *RoleService) createAssumeRolePolicyDocument(awsAccount string, external
 }`
 }
 
-// CreateNewRole created a role with specified policy attached
-func (c *RoleService) CreateNewRole(input *client.RoleCreationInfo) (arn string, externalID string, err error) {
-	sess, err := c.newSession()
+// CreateNewRole created a role with specified policy attached, or with the
+// inline policy of the catalog put when input.InlinePolicy is set. The arn of
+// a role whose policy couldn't be attached is returned with the error.
+func (c *RoleService) CreateNewRole(ctx context.Context, input *client.RoleCreationInfo) (arn string, externalID string, err error) {
+	var document string
+	if input.InlinePolicy {
+		// an invalid catalog fails before the role is created
+		if document, err = PolicyDocument(input.Catalog); err != nil {
+			return "", "", err
+		}
+	}
+	sess, err := c.sessions.session()
+	if err != nil {
+		return "", "", err
+	}
 	svc := iam.New(sess)
 	// Create a new session for iam
-	result, err := c.createNewAwsRole(input.AwsAccount, input.ExternalID, input.RoleName, svc)
+	result, err := c.createNewAwsRole(ctx, input.AwsAccount, input.ExternalID, input.RoleName, svc)
 	if err != nil {
 		return "", "", err
 	}
 	roleArn := result.Role.Arn
-	_, err = c.attachRolePolicy(svc, input.Policy, input.RoleName)
+	if input.InlinePolicy {
+		err = c.putInlinePolicy(ctx, svc, input.RoleName, document)
+	} else {
+		_, err = c.attachRolePolicy(ctx, svc, input.Policy, input.RoleName)
+	}
 	if err != nil {
-		return "", "", err
+		// the role without its policy is of no use, the caller deletes it again
+		return *roleArn, input.ExternalID, err
 	}
 
 	return *roleArn, input.ExternalID, nil
 }
 
-func (c *RoleService) createNewAwsRole(awsAccount, externalID, roleName string, svc *iam.IAM) (*iam.CreateRoleOutput, error) {
+func (c *RoleService) createNewAwsRole(ctx context.Context, awsAccount, externalID, roleName string, svc *iam.IAM) (*iam.CreateRoleOutput, error) {
 	input := &iam.CreateRoleInput{
 		AssumeRolePolicyDocument: aws.String(c.createAssumeRolePolicyDocument(awsAccount, externalID)),
 		Path:     aws.String("/"),
 		RoleName: aws.String(roleName),
 	}
 
-	result, err := svc.CreateRole(input)
+	result, err := svc.CreateRoleWithContext(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -76,41 +95,20 @@ func (c *RoleService) createNewAwsRole(awsAccount, externalID, roleName string,
 	return result, nil
 }
 
-func (c *RoleService) attachRolePolicy(svc *iam.IAM, policyArn, roleName string) (*iam.AttachRolePolicyOutput, error) {
+func (c *RoleService) attachRolePolicy(ctx context.Context, svc *iam.IAM, policyArn, roleName string) (*iam.AttachRolePolicyOutput, error) {
 	input := &iam.AttachRolePolicyInput{
 		PolicyArn: aws.String(policyArn),
 		RoleName:  aws.String(roleName),
 	}
 
-	result, err := svc.AttachRolePolicy(input)
+	result, err := svc.AttachRolePolicyWithContext(ctx, input)
 	return result, err
 }
 
-func (c *RoleService) newSession() (*session.Session, error) {
-	var sess *session.Session
-	var err error
-
-	if c.awsProfile != "" {
-		if c.awsProfilePath != "" {
-			sess, err = session.NewSessionWithOptions(session.Options{Profile: c.awsProfile, SharedConfigFiles: []string{c.awsProfilePath}, SharedConfigState: session.SharedConfigEnable})
-		} else {
-			sess, err = session.NewSessionWithOptions(session.Options{Profile: c.awsProfile, SharedConfigState: session.SharedConfigEnable})
-		}
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		sess, err = session.NewSession()
-		if err != nil {
-			return nil, err
-		}
-	}
-	return sess, nil
-}
 
 //DetachPolicy removes all policy for the role
-func (c *RoleService) DetachPolicy(roleName, policyArn string) error {
-	sess, err := c.newSession()
+func (c *RoleService) DetachPolicy(ctx context.Context, roleName, policyArn string) error {
+	sess, err := c.sessions.session()
 
 	if err != nil {
 		return err
@@ -121,7 +119,7 @@ func (c *RoleService) DetachPolicy(roleName, policyArn string) error {
 		PolicyArn: aws.String(policyArn),
 		RoleName:  aws.String(roleName),
 	}
-	_, err = svc.DetachRolePolicy(detachPolicyInput)
+	_, err = svc.DetachRolePolicyWithContext(ctx, detachPolicyInput)
 	if err != nil {
 		return errors.New("Detach role policy " + policyArn + "for " + roleName + " failed, " + err.Error())
 	}
@@ -130,8 +128,8 @@ func (c *RoleService) DetachPolicy(roleName, policyArn string) error {
 }
 
 // DeleteRole will remove the role created before if the cloud account add fails
-func (c *RoleService) DeleteRole(roleName string) error {
-	sess, err := c.newSession()
+func (c *RoleService) DeleteRole(ctx context.Context, roleName string) error {
+	sess, err := c.sessions.session()
 
 	if err != nil {
 		return err
@@ -139,31 +137,46 @@ func (c *RoleService) DeleteRole(roleName string) error {
 
 	svc := iam.New(sess)
 
-	policies, err := c.getManagedRolePolicies(svc, roleName)
+	policies, err := c.getManagedRolePolicies(ctx, svc, roleName)
 	for _, policy := range policies {
 		policyArn := *(policy.PolicyArn)
 		detachPolicyInput := &iam.DetachRolePolicyInput{
 			PolicyArn: aws.String(policyArn),
 			RoleName:  aws.String(roleName),
 		}
-		_, err = svc.DetachRolePolicy(detachPolicyInput)
+		_, err = svc.DetachRolePolicyWithContext(ctx, detachPolicyInput)
 		if err != nil {
 			return errors.New("Detach role policy " + policyArn + "for " + roleName + " failed, " + err.Error())
 		}
 	}
 
+	// a role can only be deleted without inline policies
+	names, err := c.getInlineRolePolicies(ctx, svc, roleName)
+	if err != nil {
+		return errors.New("List inline policies of " + roleName + " failed, " + err.Error())
+	}
+	for _, name := range names {
+		_, err = svc.DeleteRolePolicyWithContext(ctx, &iam.DeleteRolePolicyInput{
+			PolicyName: aws.String(name),
+			RoleName:   aws.String(roleName),
+		})
+		if err != nil {
+			return errors.New("Delete inline policy " + name + " for " + roleName + " failed, " + err.Error())
+		}
+	}
+
 	deleteRoleInput := &iam.DeleteRoleInput{
 		RoleName: aws.String(roleName),
 	}
-	_, err = svc.DeleteRole(deleteRoleInput)
+	_, err = svc.DeleteRoleWithContext(ctx, deleteRoleInput)
 	if err != nil {
 		return errors.New("Delete role " + roleName + " failed, " + err.Error())
 	}
 	return nil
 }
 
-func (c *RoleService) checkRolePolicy(roleName, policy string) (bool, error) {
-	sess, err := c.newSession()
+func (c *RoleService) checkRolePolicy(ctx context.Context, roleName, policy string) (bool, error) {
+	sess, err := c.sessions.session()
 
 	if err != nil {
 		return false, err
@@ -172,7 +185,7 @@ func (c *RoleService) checkRolePolicy(roleName, policy string) (bool, error) {
 	svc := iam.New(sess)
 	input := &iam.ListAttachedRolePoliciesInput{}
 	input.SetRoleName(roleName)
-	res, err := svc.ListAttachedRolePolicies(input)
+	res, err := svc.ListAttachedRolePoliciesWithContext(ctx, input)
 	if err != nil {
 		return false, err
 	}
@@ -184,15 +197,106 @@ func (c *RoleService) checkRolePolicy(roleName, policy string) (bool, error) {
 	return false, nil
 }
 
-func (c *RoleService) getManagedRolePolicies(svc *iam.IAM, roleName string) ([]*iam.AttachedPolicy, error) {
+func (c *RoleService) getManagedRolePolicies(ctx context.Context, svc *iam.IAM, roleName string) ([]*iam.AttachedPolicy, error) {
 	res := make([]*iam.AttachedPolicy, 0)
 
 	input := &iam.ListAttachedRolePoliciesInput{
 		RoleName: &roleName,
 	}
-	err := svc.ListAttachedRolePoliciesPages(input, func(output *iam.ListAttachedRolePoliciesOutput, last bool) bool {
+	err := svc.ListAttachedRolePoliciesPagesWithContext(ctx, input, func(output *iam.ListAttachedRolePoliciesOutput, last bool) bool {
 		res = append(res, output.AttachedPolicies...)
 		return true
 	})
 	return res, err
 }
+
+//UpdateExternalID replaces the external id current in the trust policy of the
+//role with externalIDs, several external ids are trusted while rotating it.
+//The other statements and conditions of the policy are kept.
+func (c *RoleService) UpdateExternalID(ctx context.Context, roleName, current string, externalIDs []string) error {
+	sess, err := c.sessions.session()
+	if err != nil {
+		return err
+	}
+	svc := iam.New(sess)
+
+	role, err := svc.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		return errors.New("Get role " + roleName + " failed, " + err.Error())
+	}
+	// the policy document is returned URL encoded
+	document, err := url.QueryUnescape(aws.StringValue(role.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return err
+	}
+	document, err = replaceExternalID(document, current, externalIDs)
+	if err != nil {
+		return errors.New("Update trust policy of role " + roleName + " failed, " + err.Error())
+	}
+
+	_, err = svc.UpdateAssumeRolePolicyWithContext(ctx, &iam.UpdateAssumeRolePolicyInput{
+		PolicyDocument: aws.String(document),
+		RoleName:       aws.String(roleName),
+	})
+	if err != nil {
+		return errors.New("Update trust policy of role " + roleName + " failed, " + err.Error())
+	}
+	return nil
+}
+
+// replaceExternalID replaces the sts:ExternalId conditions of the policy
+// document that allow current with externalIDs
+func replaceExternalID(document, current string, externalIDs []string) (string, error) {
+	policy := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return "", err
+	}
+
+	var value interface{} = externalIDs
+	if len(externalIDs) == 1 {
+		value = externalIDs[0]
+	}
+	statements, ok := policy["Statement"].([]interface{})
+	if !ok {
+		statements = []interface{}{policy["Statement"]}
+	}
+	replaced := false
+	for _, statement := range statements {
+		statement, _ := statement.(map[string]interface{})
+		conditions, _ := statement["Condition"].(map[string]interface{})
+		for operator, condition := range conditions {
+			condition, ok := condition.(map[string]interface{})
+			if !ok || !strings.HasPrefix(operator, "StringEquals") {
+				continue
+			}
+			for key, ids := range condition {
+				// condition keys are case insensitive
+				if strings.EqualFold(key, "sts:ExternalId") && allows(ids, current) {
+					condition[key] = value
+					replaced = true
+				}
+			}
+		}
+	}
+	if !replaced {
+		return "", errors.New("no statement requires the external id " + current)
+	}
+
+	b, err := json.Marshal(policy)
+	return string(b), err
+}
+
+// allows tells whether the condition value, one or several strings, contains s
+func allows(value interface{}, s string) bool {
+	switch v := value.(type) {
+	case string:
+		return v == s
+	case []interface{}:
+		for _, item := range v {
+			if item == s {
+				return true
+			}
+		}
+	}
+	return false
+}
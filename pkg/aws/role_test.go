@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const trustPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [
+		{
+			"Effect": "Allow",
+			"Principal": {"AWS": "arn:aws:iam::123456789012:root"},
+			"Action": "sts:AssumeRole",
+			"Condition": {"StringEquals": {"sts:ExternalId": "old-id"}, "Bool": {"aws:SecureTransport": "true"}}
+		},
+		{
+			"Effect": "Allow",
+			"Principal": {"Service": "ec2.amazonaws.com"},
+			"Action": "sts:AssumeRole"
+		}
+	]
+}`
+
+func TestReplaceExternalID(t *testing.T) {
+	both, err := replaceExternalID(trustPolicy, "old-id", []string{"old-id", "new-id"})
+	assert.Nil(t, err)
+	assert.Contains(t, both, `"sts:ExternalId":["old-id","new-id"]`)
+	assert.Contains(t, both, `"aws:SecureTransport":"true"`)
+	assert.Contains(t, both, `"ec2.amazonaws.com"`)
+
+	rotated, err := replaceExternalID(both, "new-id", []string{"new-id"})
+	assert.Nil(t, err)
+	assert.Contains(t, rotated, `"sts:ExternalId":"new-id"`)
+
+	_, err = replaceExternalID(rotated, "old-id", []string{"other-id"})
+	assert.NotNil(t, err)
+}
+
+func TestReplaceExternalIDSingleStatement(t *testing.T) {
+	policy := `{"Statement": {"Effect": "Allow", "Condition": {"StringEquals": {"STS:EXTERNALID": ["a", "old-id"]}}}}`
+	rotated, err := replaceExternalID(policy, "old-id", []string{"new-id"})
+	assert.Nil(t, err)
+	assert.Contains(t, rotated, `"STS:EXTERNALID":"new-id"`)
+}
@@ -1,9 +1,11 @@
 package aws
 
 import (
-	"fmt"
+	"context"
+	"net/http"
 
 	"github.com/CloudCoreo/cli/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 )
 
 // Service contains three aws service groups
@@ -15,44 +17,70 @@ type Service struct {
 
 // NewServiceInput contains the info for creating a new Service
 type NewServiceInput struct {
-	AwsProfile          string
-	AwsProfilePath      string
-	Policy              string
-	RoleSessionName     string
-	Duration            int64
+	AwsProfile      string
+	AwsProfilePath  string
+	Policy          string
+	RoleSessionName string
+	Duration        int64
+	// CrossAccountRole is assumed in the member accounts of an organization
+	CrossAccountRole    string
 	IgnoreMissingTrails bool
+	// Credentials are used instead of the profile when set, e.g. for a role assumed in another account
+	Credentials *credentials.Credentials
+	// AssumeRoleArn is the role assumed with the credentials of the profile, it
+	// lets a central identity account act in member accounts. RoleSessionName
+	// and Duration are the ones of the assumed role.
+	AssumeRoleArn string
+	// ExternalID is passed when assuming the role of AssumeRoleArn
+	ExternalID string
+	// MFASerial is the MFA device whose token is asked for when assuming the role of AssumeRoleArn
+	MFASerial string
+	// WebIdentityTokenFile is the OIDC token the role of AssumeRoleArn is
+	// assumed with instead of the profile, e.g. the token of a CI job
+	WebIdentityTokenFile string
+	// HTTPClient gets the credentials of SSO profiles, http.DefaultClient is used when nil
+	HTTPClient *http.Client
 }
 
-// NewService returns a new aws service group
+// NewService returns a new aws service group, its services share one session
 func NewService(input *NewServiceInput) *Service {
+	return newService(input, newSessionFactory(input))
+}
+
+func newService(input *NewServiceInput, sessions *sessionFactory) *Service {
 	return &Service{
-		setup:  NewSetupService(input),
-		role:   NewRoleService(input),
-		remove: NewRemoveService(input),
+		setup:  &SetupService{sessions: sessions, ignoreMissingTrail: input.IgnoreMissingTrails},
+		role:   &RoleService{sessions: sessions},
+		remove: &RemoveService{sessions: sessions},
 	}
 }
 
 // SetupEventStream calls the SetupEventStream function in SetupService
-func (s *Service) SetupEventStream(input *client.EventStreamConfig) error {
-	return s.setup.SetupEventStream(input)
+func (s *Service) SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error {
+	return s.setup.SetupEventStream(ctx, input)
 }
 
 // CreateNewRole calls the CreateNewRole function in RoleService
-func (s *Service) CreateNewRole(input *client.RoleCreationInfo) (arn string, externalID string, err error) {
-	return s.role.CreateNewRole(input)
+func (s *Service) CreateNewRole(ctx context.Context, input *client.RoleCreationInfo) (arn string, externalID string, err error) {
+	return s.role.CreateNewRole(ctx, input)
 }
 
 // DeleteRole calls the DeleteRole function in RoleService
-func (s *Service) DeleteRole(roleName string) {
-	err := s.role.DeleteRole(roleName)
-	if err != nil {
-		fmt.Println(err.Error())
-	} else {
-		fmt.Println("Deleted role successfully!")
-	}
+func (s *Service) DeleteRole(ctx context.Context, roleName string) error {
+	return s.role.DeleteRole(ctx, roleName)
+}
+
+// UpdateExternalID calls the UpdateExternalID function in RoleService
+func (s *Service) UpdateExternalID(ctx context.Context, roleName, current string, externalIDs []string) error {
+	return s.role.UpdateExternalID(ctx, roleName, current, externalIDs)
+}
+
+// UpdateInlinePolicy calls the UpdateInlinePolicy function in RoleService
+func (s *Service) UpdateInlinePolicy(ctx context.Context, roleName string, catalog client.PolicyCatalog) (bool, error) {
+	return s.role.UpdateInlinePolicy(ctx, roleName, catalog)
 }
 
-//RemoveEventStream perform the same function as event stream removal script
-func (s *Service) RemoveEventStream(input *client.EventRemoveConfig) error {
-	return s.remove.RemoveEventStream(input)
+// RemoveEventStream perform the same function as event stream removal script
+func (s *Service) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
+	return s.remove.RemoveEventStream(ctx, input)
 }
@@ -0,0 +1,351 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+var (
+	// mfaTokenProvider asks for the MFA token of the roles assumed with an MFA device
+	mfaTokenProvider = stscreds.StdinTokenProvider
+
+	// ssoPortalURL returns the URL of the AWS SSO portal API of a region
+	ssoPortalURL = func(region string) string {
+		return "https://portal.sso." + region + ".amazonaws.com"
+	}
+)
+
+// ssoRequestTimeout limits a request for the credentials of an SSO profile
+const ssoRequestTimeout = 30 * time.Second
+
+// sessionFactory creates the session the services of a Service share. The
+// session is created once, so an MFA token is only asked for once.
+type sessionFactory struct {
+	input NewServiceInput
+	// config overrides the config of the session, e.g. the endpoint in tests
+	config *aws.Config
+
+	mu   sync.Mutex
+	sess *session.Session
+}
+
+func newSessionFactory(input *NewServiceInput) *sessionFactory {
+	return &sessionFactory{input: *input}
+}
+
+// session returns the session of the credentials of the input: the
+// credentials when set, otherwise the ones of the profile, which may be an
+// SSO profile, run a credential_process, assume a role with an MFA token or a
+// web identity token. The role of AssumeRoleArn is then assumed with them.
+func (f *sessionFactory) session() (*session.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.sess == nil {
+		sess, err := f.newSession()
+		if err != nil {
+			return nil, err
+		}
+		f.sess = sess
+	}
+	return f.sess, nil
+}
+
+func (f *sessionFactory) newSession() (*session.Session, error) {
+	input := f.input
+	if input.AssumeRoleArn == "" && (input.ExternalID != "" || input.MFASerial != "" || input.WebIdentityTokenFile != "") {
+		return nil, errors.New("An external ID, MFA device or web identity token needs the ARN of the role to assume")
+	}
+	if input.WebIdentityTokenFile != "" && (input.ExternalID != "" || input.MFASerial != "") {
+		return nil, errors.New("A role assumed with a web identity token takes no external ID or MFA device")
+	}
+
+	var sess *session.Session
+	var err error
+	if input.Credentials != nil {
+		sess, err = session.NewSession(&aws.Config{Credentials: input.Credentials}, f.config)
+	} else {
+		options := session.Options{
+			Profile:                 input.AwsProfile,
+			SharedConfigState:       session.SharedConfigEnable,
+			AssumeRoleTokenProvider: mfaTokenProvider,
+		}
+		if input.AwsProfilePath != "" {
+			// the profile path is the credentials file, the profiles of the
+			// config file still apply unless the credentials file overrides them
+			options.SharedConfigFiles = []string{sharedConfigFile(), input.AwsProfilePath}
+		}
+		var sso *ssoProfile
+		sso, err = loadSSOProfile(sharedConfigFile(), profileName(input.AwsProfile))
+		if err != nil {
+			return nil, err
+		}
+		if sso != nil {
+			options.Config.Credentials = credentials.NewCredentials(newSSOProvider(sso, input.HTTPClient))
+		}
+		options.Config.MergeIn(f.config)
+		sess, err = session.NewSessionWithOptions(options)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if input.AssumeRoleArn == "" {
+		return sess, nil
+	}
+	return sess.Copy(&aws.Config{Credentials: f.assumeRole(sess)}), nil
+}
+
+// assumeRole returns the credentials of the role of AssumeRoleArn assumed with
+// the credentials of sess, or with the web identity token when one is set
+func (f *sessionFactory) assumeRole(sess *session.Session) *credentials.Credentials {
+	input := f.input
+	roleSessionName := input.RoleSessionName
+	if roleSessionName == "" {
+		roleSessionName = DefaultRoleSessionName
+	}
+	if input.WebIdentityTokenFile != "" {
+		return stscreds.NewWebIdentityCredentials(sess, input.AssumeRoleArn, roleSessionName, input.WebIdentityTokenFile)
+	}
+
+	return stscreds.NewCredentials(sess, input.AssumeRoleArn, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = roleSessionName
+		if input.Duration > 0 {
+			p.Duration = time.Duration(input.Duration) * time.Second
+		}
+		if input.ExternalID != "" {
+			p.ExternalID = aws.String(input.ExternalID)
+		}
+		if input.MFASerial != "" {
+			p.SerialNumber = aws.String(input.MFASerial)
+			p.TokenProvider = mfaTokenProvider
+		}
+	})
+}
+
+// profileName returns the profile the SDK uses when none is given
+func profileName(profile string) string {
+	for _, name := range []string{profile, os.Getenv("AWS_PROFILE"), os.Getenv("AWS_DEFAULT_PROFILE")} {
+		if name != "" {
+			return name
+		}
+	}
+	return "default"
+}
+
+// sharedConfigFile returns the config file the SDK reads profiles from, which
+// is the one SSO profiles are in
+func sharedConfigFile() string {
+	if file := os.Getenv("AWS_CONFIG_FILE"); file != "" {
+		return file
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", "config")
+}
+
+// ssoProfile is a profile signed in with 'aws sso login'
+type ssoProfile struct {
+	Name      string
+	StartURL  string
+	Region    string
+	AccountID string
+	RoleName  string
+	// Session is the sso-session section of the profile, if any
+	Session string
+}
+
+// loadSSOProfile returns the SSO settings of the profile in the config file,
+// nil when the file or profile doesn't exist or the profile isn't an SSO one
+func loadSSOProfile(file, profile string) (*ssoProfile, error) {
+	sections, err := readINI(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := sections["profile "+profile]
+	if !ok {
+		values = sections[profile]
+	}
+	if values["sso_account_id"] == "" && values["sso_role_name"] == "" {
+		return nil, nil
+	}
+
+	sso := &ssoProfile{
+		Name:      profile,
+		StartURL:  values["sso_start_url"],
+		Region:    values["sso_region"],
+		AccountID: values["sso_account_id"],
+		RoleName:  values["sso_role_name"],
+		Session:   values["sso_session"],
+	}
+	if sso.Session != "" {
+		ssoSession, ok := sections["sso-session "+sso.Session]
+		if !ok {
+			return nil, fmt.Errorf("The sso-session %s of profile %s doesn't exist in %s", sso.Session, profile, file)
+		}
+		sso.StartURL, sso.Region = ssoSession["sso_start_url"], ssoSession["sso_region"]
+	}
+	if sso.StartURL == "" || sso.Region == "" || sso.AccountID == "" || sso.RoleName == "" {
+		return nil, fmt.Errorf("The SSO profile %s needs sso_start_url, sso_region, sso_account_id and sso_role_name", profile)
+	}
+	return sso, nil
+}
+
+// readINI returns the key values of the sections of an AWS config file. The
+// vendored SDK parses the file with an internal package and doesn't know the
+// SSO settings, so they are read here.
+func readINI(file string) (map[string]map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sections := map[string]map[string]string{}
+	var current map[string]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			// blank line or comment
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			if sections[name] == nil {
+				sections[name] = map[string]string{}
+			}
+			current = sections[name]
+		case current != nil && strings.Contains(line, "="):
+			i := strings.Index(line, "=")
+			current[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// ssoProvider retrieves the credentials of the role of an SSO profile with the
+// access token 'aws sso login' cached
+type ssoProvider struct {
+	credentials.Expiry
+
+	profile  *ssoProfile
+	cacheDir string
+	client   *http.Client
+}
+
+func newSSOProvider(profile *ssoProfile, client *http.Client) *ssoProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	home, _ := os.UserHomeDir()
+	return &ssoProvider{
+		profile:  profile,
+		cacheDir: filepath.Join(home, ".aws", "sso", "cache"),
+		client:   client,
+	}
+}
+
+// ssoToken is a cached access token of 'aws sso login'
+type ssoToken struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+type ssoRoleCredentials struct {
+	RoleCredentials struct {
+		AccessKeyID     string `json:"accessKeyId"`
+		SecretAccessKey string `json:"secretAccessKey"`
+		SessionToken    string `json:"sessionToken"`
+		// Expiration is in milliseconds since the epoch
+		Expiration int64 `json:"expiration"`
+	} `json:"roleCredentials"`
+}
+
+// Retrieve returns the credentials of the role of the profile. The request
+// isn't cancelled with the command, the credentials may be refreshed while a
+// cancelled command rolls back.
+func (p *ssoProvider) Retrieve() (credentials.Value, error) {
+	token, err := p.token()
+	if err != nil {
+		return credentials.Value{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ssoRequestTimeout)
+	defer cancel()
+	query := url.Values{"account_id": {p.profile.AccountID}, "role_name": {p.profile.RoleName}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ssoPortalURL(p.profile.Region)+"/federation/credentials?"+query.Encode(), nil)
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	req.Header.Set("x-amz-sso_bearer_token", token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return credentials.Value{}, errors.Wrap(err, "Get the SSO credentials of profile "+p.profile.Name+" failed")
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return credentials.Value{}, fmt.Errorf("Get the SSO credentials of profile %s failed, %s: %s", p.profile.Name, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	output := &ssoRoleCredentials{}
+	if err := json.Unmarshal(body, output); err != nil {
+		return credentials.Value{}, err
+	}
+	role := output.RoleCredentials
+	p.SetExpiration(time.Unix(0, role.Expiration*int64(time.Millisecond)), time.Minute)
+	return credentials.Value{
+		AccessKeyID:     role.AccessKeyID,
+		SecretAccessKey: role.SecretAccessKey,
+		SessionToken:    role.SessionToken,
+		ProviderName:    "SSOProvider",
+	}, nil
+}
+
+// token returns the cached access token of the profile, the cache file is
+// named after the sso-session or, for older profiles, the start URL
+func (p *ssoProvider) token() (string, error) {
+	key := p.profile.StartURL
+	if p.profile.Session != "" {
+		key = p.profile.Session
+	}
+	hash := sha1.Sum([]byte(key))
+	b, err := ioutil.ReadFile(filepath.Join(p.cacheDir, hex.EncodeToString(hash[:])+".json"))
+	if err != nil {
+		return "", fmt.Errorf("No SSO session of profile %s found, run 'aws sso login --profile %s'", p.profile.Name, p.profile.Name)
+	}
+
+	token := &ssoToken{}
+	if err := json.Unmarshal(b, token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" || !time.Now().Before(token.ExpiresAt) {
+		return "", fmt.Errorf("The SSO session of profile %s expired, run 'aws sso login --profile %s'", p.profile.Name, p.profile.Name)
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,292 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+[default]
+region = us-east-1
+
+[profile legacy]
+sso_start_url = https://example.awsapps.com/start
+sso_region = eu-west-1
+sso_account_id = 111111111111
+sso_role_name = SecurityAudit
+
+[profile member]
+sso_session = central
+sso_account_id = 222222222222
+sso_role_name = Admin
+region = us-west-2
+
+[sso-session central]
+sso_start_url = https://central.awsapps.com/start
+sso_region = us-east-2
+
+[profile broken]
+sso_session = unknown
+sso_account_id = 222222222222
+sso_role_name = Admin
+`
+
+func writeFile(t *testing.T, dir, name, data string) string {
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(data), 0600))
+	return path
+}
+
+// writeSSOToken caches the access token of an SSO session the way 'aws sso login' does
+func writeSSOToken(t *testing.T, cacheDir, key, token string, expiresAt time.Time) {
+	hash := sha1.Sum([]byte(key))
+	writeFile(t, cacheDir, hex.EncodeToString(hash[:])+".json",
+		fmt.Sprintf(`{"accessToken": %q, "expiresAt": %q}`, token, expiresAt.UTC().Format(time.RFC3339)))
+}
+
+// newTestSSOPortal returns a portal API handing out the credentials of role Admin for token-1
+func newTestSSOPortal(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/federation/credentials", r.URL.Path)
+		assert.Equal(t, "Admin", r.URL.Query().Get("role_name"))
+		if r.Header.Get("x-amz-sso_bearer_token") != "token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "Session token not found or invalid"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"roleCredentials": {"accessKeyId": "AKID-%s", "secretAccessKey": "secret", "sessionToken": "session", "expiration": %d}}`,
+			r.URL.Query().Get("account_id"), time.Now().Add(time.Hour).Unix()*1000)
+	}))
+	t.Cleanup(server.Close)
+
+	portalURL := ssoPortalURL
+	t.Cleanup(func() { ssoPortalURL = portalURL })
+	ssoPortalURL = func(region string) string {
+		return server.URL
+	}
+	return server
+}
+
+func TestLoadSSOProfile(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "config", testConfig)
+
+	sso, err := loadSSOProfile(file, "legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, &ssoProfile{
+		Name:      "legacy",
+		StartURL:  "https://example.awsapps.com/start",
+		Region:    "eu-west-1",
+		AccountID: "111111111111",
+		RoleName:  "SecurityAudit",
+	}, sso)
+
+	sso, err = loadSSOProfile(file, "member")
+	assert.Nil(t, err)
+	assert.Equal(t, &ssoProfile{
+		Name:      "member",
+		StartURL:  "https://central.awsapps.com/start",
+		Region:    "us-east-2",
+		AccountID: "222222222222",
+		RoleName:  "Admin",
+		Session:   "central",
+	}, sso)
+
+	for _, profile := range []string{"default", "unknown"} {
+		sso, err = loadSSOProfile(file, profile)
+		assert.Nil(t, err)
+		assert.Nil(t, sso)
+	}
+	_, err = loadSSOProfile(file, "broken")
+	assert.EqualError(t, err, "The sso-session unknown of profile broken doesn't exist in "+file)
+
+	sso, err = loadSSOProfile(filepath.Join(t.TempDir(), "missing"), "default")
+	assert.Nil(t, err)
+	assert.Nil(t, sso)
+}
+
+func TestSSOProviderRetrieve(t *testing.T) {
+	newTestSSOPortal(t)
+	cacheDir := t.TempDir()
+	writeSSOToken(t, cacheDir, "central", "token-1", time.Now().Add(time.Hour))
+
+	p := newSSOProvider(&ssoProfile{Name: "member", Region: "us-east-2", AccountID: "222222222222", RoleName: "Admin", Session: "central"}, nil)
+	p.cacheDir = cacheDir
+	value, err := p.Retrieve()
+	assert.Nil(t, err)
+	assert.Equal(t, credentials.Value{AccessKeyID: "AKID-222222222222", SecretAccessKey: "secret", SessionToken: "session", ProviderName: "SSOProvider"}, value)
+	assert.False(t, p.IsExpired())
+}
+
+func TestSSOProviderExpiredToken(t *testing.T) {
+	newTestSSOPortal(t)
+	cacheDir := t.TempDir()
+	profile := &ssoProfile{Name: "legacy", StartURL: "https://example.awsapps.com/start", AccountID: "111111111111", RoleName: "Admin"}
+
+	p := newSSOProvider(profile, nil)
+	p.cacheDir = cacheDir
+	_, err := p.Retrieve()
+	assert.EqualError(t, err, "No SSO session of profile legacy found, run 'aws sso login --profile legacy'")
+
+	writeSSOToken(t, cacheDir, profile.StartURL, "token-1", time.Now().Add(-time.Minute))
+	_, err = p.Retrieve()
+	assert.EqualError(t, err, "The SSO session of profile legacy expired, run 'aws sso login --profile legacy'")
+
+	writeSSOToken(t, cacheDir, profile.StartURL, "revoked", time.Now().Add(time.Hour))
+	_, err = p.Retrieve()
+	assert.Contains(t, err.Error(), "Session token not found or invalid")
+}
+
+func TestSSOProviderTimeout(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeSSOToken(t, cacheDir, "central", "token-1", time.Now().Add(time.Hour))
+
+	var deadline time.Time
+	hc := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var ok bool
+		deadline, ok = req.Context().Deadline()
+		assert.True(t, ok, "the request should time out")
+		return nil, context.DeadlineExceeded
+	})}
+	p := newSSOProvider(&ssoProfile{Name: "member", Region: "us-east-2", AccountID: "222222222222", RoleName: "Admin", Session: "central"}, hc)
+	p.cacheDir = cacheDir
+	_, err := p.Retrieve()
+	assert.NotNil(t, err)
+	assert.WithinDuration(t, time.Now().Add(ssoRequestTimeout), deadline, time.Second)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSessionSSOProfile(t *testing.T) {
+	newTestSSOPortal(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cacheDir := filepath.Join(home, ".aws", "sso", "cache")
+	assert.Nil(t, os.MkdirAll(cacheDir, 0700))
+	writeSSOToken(t, cacheDir, "central", "token-1", time.Now().Add(time.Hour))
+	t.Setenv("AWS_CONFIG_FILE", writeFile(t, home, "config", testConfig))
+	// --aws-profile-path is the credentials file, the SSO profile is still read from the config file
+	credentialsFile := writeFile(t, home, "credentials", "[other]\naws_access_key_id = AKID\naws_secret_access_key = secret\n")
+
+	sess, err := newSessionFactory(&NewServiceInput{AwsProfile: "member", AwsProfilePath: credentialsFile}).session()
+	assert.Nil(t, err)
+	assert.Equal(t, "us-west-2", aws.StringValue(sess.Config.Region))
+	value, err := sess.Config.Credentials.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "AKID-222222222222", value.AccessKeyID)
+}
+
+// newTestSTS returns the config of a session calling an STS API that returns
+// the credentials of any role, the requests are recorded in the returned slice
+func newTestSTS(t *testing.T) (*aws.Config, *[]url.Values) {
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		requests = append(requests, r.PostForm)
+		action := r.PostForm.Get("Action")
+		fmt.Fprintf(w, `<%[1]sResponse><%[1]sResult><Credentials>`+
+			`<AccessKeyId>AKID-%[1]s</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>session</SessionToken>`+
+			`<Expiration>%[2]s</Expiration></Credentials></%[1]sResult></%[1]sResponse>`, action, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	t.Cleanup(server.Close)
+
+	return &aws.Config{Endpoint: aws.String(server.URL), Region: aws.String("us-east-1")}, &requests
+}
+
+func TestSessionAssumeRole(t *testing.T) {
+	defer func(provider func() (string, error)) {
+		mfaTokenProvider = provider
+	}(mfaTokenProvider)
+	prompts := 0
+	mfaTokenProvider = func() (string, error) {
+		prompts++
+		return "123456", nil
+	}
+	config, requests := newTestSTS(t)
+
+	f := newSessionFactory(&NewServiceInput{
+		Credentials:     credentials.NewStaticCredentials("central", "secret", ""),
+		AssumeRoleArn:   "arn:aws:iam::222222222222:role/admin",
+		ExternalID:      "external-1",
+		MFASerial:       "arn:aws:iam::111111111111:mfa/user",
+		RoleSessionName: "ci",
+		Duration:        1800,
+	})
+	f.config = config
+	sess, err := f.session()
+	assert.Nil(t, err)
+	value, err := sess.Config.Credentials.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "AKID-AssumeRole", value.AccessKeyID)
+
+	// the services of a Service share the session, the token is asked for once
+	again, err := f.session()
+	assert.Nil(t, err)
+	assert.True(t, sess == again)
+	assert.Equal(t, 1, prompts)
+
+	assert.Len(t, *requests, 1)
+	request := (*requests)[0]
+	assert.Equal(t, "arn:aws:iam::222222222222:role/admin", request.Get("RoleArn"))
+	assert.Equal(t, "external-1", request.Get("ExternalId"))
+	assert.Equal(t, "arn:aws:iam::111111111111:mfa/user", request.Get("SerialNumber"))
+	assert.Equal(t, "123456", request.Get("TokenCode"))
+	assert.Equal(t, "ci", request.Get("RoleSessionName"))
+	assert.Equal(t, "1800", request.Get("DurationSeconds"))
+}
+
+func TestSessionWebIdentity(t *testing.T) {
+	// the token is the only credential of a CI job
+	t.Setenv("HOME", t.TempDir())
+	config, requests := newTestSTS(t)
+	token := writeFile(t, t.TempDir(), "token", "oidc-token")
+
+	f := newSessionFactory(&NewServiceInput{
+		AssumeRoleArn:        "arn:aws:iam::222222222222:role/ci",
+		WebIdentityTokenFile: token,
+	})
+	f.config = config
+	sess, err := f.session()
+	assert.Nil(t, err)
+	value, err := sess.Config.Credentials.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "AKID-AssumeRoleWithWebIdentity", value.AccessKeyID)
+
+	request := (*requests)[0]
+	assert.Equal(t, "oidc-token", request.Get("WebIdentityToken"))
+	assert.Equal(t, DefaultRoleSessionName, request.Get("RoleSessionName"))
+}
+
+func TestSessionInvalidInput(t *testing.T) {
+	for _, input := range []*NewServiceInput{{WebIdentityTokenFile: "token"}, {ExternalID: "external-1"}, {MFASerial: "arn:aws:iam::111111111111:mfa/user"}} {
+		_, err := newSessionFactory(input).session()
+		assert.EqualError(t, err, "An external ID, MFA device or web identity token needs the ARN of the role to assume")
+	}
+
+	_, err := newSessionFactory(&NewServiceInput{AssumeRoleArn: "arn:aws:iam::222222222222:role/ci", WebIdentityTokenFile: "token", ExternalID: "external-1"}).session()
+	assert.NotNil(t, err)
+}
+
+func TestServiceSharesSession(t *testing.T) {
+	s := NewService(&NewServiceInput{AwsProfile: "admin", IgnoreMissingTrails: true})
+	assert.True(t, s.setup.sessions == s.role.sessions)
+	assert.True(t, s.role.sessions == s.remove.sessions)
+	assert.True(t, s.setup.ignoreMissingTrail)
+}
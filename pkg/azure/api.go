@@ -0,0 +1,151 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/CloudCoreo/cli/pkg/rest"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+)
+
+// endpoints are the base URLs of Microsoft Graph and Azure Resource Manager
+type endpoints struct {
+	Graph      string
+	Management string
+}
+
+var defaultEndpoints = endpoints{
+	Graph:      "https://graph.microsoft.com/v1.0/",
+	Management: "https://management.azure.com/",
+}
+
+// resources the access tokens are requested for
+const (
+	graphResource      = "https://graph.microsoft.com/"
+	managementResource = "https://management.azure.com/"
+)
+
+// restAPI calls Microsoft Graph and the Azure authorization API
+type restAPI struct {
+	httpClient *http.Client
+	endpoints  endpoints
+	// authorizer returns the authorizer of requests to resource
+	authorizer func(resource string) (autorest.Authorizer, error)
+
+	// authorizers are the authorizers by resource, they refresh their tokens
+	// so one is created per resource for all requests
+	mu          sync.Mutex
+	authorizers map[string]autorest.Authorizer
+}
+
+func newRESTAPI(input *NewServiceInput) *restAPI {
+	api := &restAPI{
+		httpClient:  httpClientOrDefault(input.HTTPClient),
+		endpoints:   defaultEndpoints,
+		authorizers: map[string]autorest.Authorizer{},
+	}
+	authFile := input.AuthFile
+	api.authorizer = func(resource string) (autorest.Authorizer, error) {
+		return newAuthorizer(authFile, resource)
+	}
+	return api
+}
+
+// newAuthorizer returns the authorizer of requests to resource. The credentials
+// are read from the auth file, the environment or the Azure CLI in that order.
+func newAuthorizer(authFile, resource string) (autorest.Authorizer, error) {
+	if authFile != "" {
+		return newAuthorizerFromFile(authFile, resource)
+	}
+	if os.Getenv(auth.ClientID) != "" {
+		return auth.NewAuthorizerFromEnvironmentWithResource(resource)
+	}
+	return auth.NewAuthorizerFromCLIWithResource(resource)
+}
+
+// newAuthorizerFromFile returns the authorizer of the service principal of an
+// auth file created by 'az ad sp create-for-rbac --sdk-auth'
+func newAuthorizerFromFile(authFile, resource string) (autorest.Authorizer, error) {
+	b, err := ioutil.ReadFile(authFile)
+	if err != nil {
+		return nil, err
+	}
+	settings := struct {
+		ClientID                string `json:"clientId"`
+		ClientSecret            string `json:"clientSecret"`
+		TenantID                string `json:"tenantId"`
+		ActiveDirectoryEndpoint string `json:"activeDirectoryEndpointUrl"`
+	}{}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return nil, fmt.Errorf("Read auth file %s failed, %s", authFile, err)
+	}
+	if settings.ClientID == "" || settings.ClientSecret == "" || settings.TenantID == "" {
+		return nil, fmt.Errorf("The auth file %s is missing the client ID, client secret or tenant ID", authFile)
+	}
+	if settings.ActiveDirectoryEndpoint == "" {
+		settings.ActiveDirectoryEndpoint = azure.PublicCloud.ActiveDirectoryEndpoint
+	}
+
+	config, err := adal.NewOAuthConfig(settings.ActiveDirectoryEndpoint, settings.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := adal.NewServicePrincipalToken(*config, settings.ClientID, settings.ClientSecret, resource)
+	if err != nil {
+		return nil, err
+	}
+	return autorest.NewBearerAuthorizer(token), nil
+}
+
+// authorize returns the authorizer of requests to resource, creating it on first use
+func (api *restAPI) authorize(resource string) (autorest.Authorizer, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if authorizer, ok := api.authorizers[resource]; ok {
+		return authorizer, nil
+	}
+	authorizer, err := api.authorizer(resource)
+	if err != nil {
+		return nil, err
+	}
+	api.authorizers[resource] = authorizer
+	return authorizer, nil
+}
+
+// do sends a request with a JSON body to the API of resource and decodes the
+// JSON response into out
+func (api *restAPI) do(ctx context.Context, resource, method, url string, in, out interface{}) error {
+	authorizer, err := api.authorize(resource)
+	if err != nil {
+		return err
+	}
+	return rest.Do(ctx, api.httpClient, func(req *http.Request) (*http.Request, error) {
+		return autorest.Prepare(req, authorizer.WithAuthorization())
+	}, method, url, in, out)
+}
+
+// graph sends a request to Microsoft Graph, path is relative to its version
+func (api *restAPI) graph(ctx context.Context, method, path string, in, out interface{}) error {
+	return api.do(ctx, graphResource, method, api.endpoints.Graph+path, in, out)
+}
+
+// management sends a request to Azure Resource Manager, path is relative to its root
+func (api *restAPI) management(ctx context.Context, method, path string, in, out interface{}) error {
+	return api.do(ctx, managementResource, method, api.endpoints.Management+strings.TrimPrefix(path, "/"), in, out)
+}
+
+// odataString quotes s as a string literal of an OData filter
+func odataString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -15,26 +15,27 @@ import (
 
 //RemoveService removes Azure event stream
 type RemoveService struct {
-	authFile string
-	region   string
+	authFile   string
+	region     string
+	httpClient *http.Client
 }
 
 // NewRemoveService returns an instance of RemoveService
 func NewRemoveService(input *NewServiceInput) *RemoveService {
 	return &RemoveService{
-		authFile: input.AuthFile,
-		region:   input.Region,
+		authFile:   input.AuthFile,
+		region:     input.Region,
+		httpClient: httpClientOrDefault(input.HTTPClient),
 	}
 }
 
 //RemoveEventStream removes Azure event stream
-func (a *RemoveService) RemoveEventStream(input *client.EventRemoveConfig) error {
-	ctx := context.Background()
+func (a *RemoveService) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
 	err := a.removeResourceGroup(ctx, input)
 	if err != nil {
 		return err
 	}
-	return a.sendRemoveEvent(input)
+	return a.sendRemoveEvent(ctx, input)
 }
 
 func (a *RemoveService) removeResourceGroup(ctx context.Context, input *client.EventRemoveConfig) error {
@@ -64,7 +65,7 @@ func (a *RemoveService) getGroupsClient(input *client.EventRemoveConfig) (*resou
 	return &groupsClient, nil
 }
 
-func (a *RemoveService) sendRemoveEvent(input *client.EventRemoveConfig) error {
+func (a *RemoveService) sendRemoveEvent(ctx context.Context, input *client.EventRemoveConfig) error {
 	fmt.Println("Sending Event Removal message")
 	data := fmt.Sprintf("{\"data\": {\"context\": {\"activityLog\": {\"subscriptionId\": \"%s\", \"operationName\": \"AzureStreamNotReady\"}}}}", input.SubscriptionID)
 	req, err := http.NewRequest("POST", input.WebhookServiceURI, bytes.NewBuffer([]byte(data)))
@@ -72,9 +73,12 @@ func (a *RemoveService) sendRemoveEvent(input *client.EventRemoveConfig) error {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := a.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
 	io.Copy(ioutil.Discard, resp.Body)
 	defer resp.Body.Close()
 
-	return err
+	return nil
 }
@@ -17,21 +17,22 @@ import (
 
 //SetupService is Azure SetupService
 type SetupService struct {
-	authFile string
-	region   string
+	authFile   string
+	region     string
+	httpClient *http.Client
 }
 
 // NewSetupService returns a new Azure SetupService
 func NewSetupService(input *NewServiceInput) *SetupService {
 	return &SetupService{
-		authFile: input.AuthFile,
-		region:   input.Region,
+		authFile:   input.AuthFile,
+		region:     input.Region,
+		httpClient: httpClientOrDefault(input.HTTPClient),
 	}
 }
 
 //SetupEventStream sets up Azure event stream
-func (a *SetupService) SetupEventStream(input *client.EventStreamConfig) error {
-	ctx := context.Background()
+func (a *SetupService) SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error {
 	err := a.createResourceGroup(ctx, input)
 	if err != nil {
 		return err
@@ -44,7 +45,7 @@ func (a *SetupService) SetupEventStream(input *client.EventStreamConfig) error {
 
 	err = a.deployAlert(ctx, input)
 
-	return a.sendSuccessEvent(input)
+	return a.sendSuccessEvent(ctx, input)
 }
 
 func (a *SetupService) createResourceGroup(ctx context.Context, input *client.EventStreamConfig) error {
@@ -142,7 +143,7 @@ func (a *SetupService) deployAlert(ctx context.Context, input *client.EventStrea
 	return err
 }
 
-func (a *SetupService) sendSuccessEvent(input *client.EventStreamConfig) error {
+func (a *SetupService) sendSuccessEvent(ctx context.Context, input *client.EventStreamConfig) error {
 	//No additional whitespace is allowed in the below string, other with the http request may fail
 	//TODO: Discuss to see whether it needs to be a struct
 	data := fmt.Sprintf("{\"data\": {\"context\": {\"activityLog\": {\"subscriptionId\": \"%s\", \"operationName\": \"AzureStreamReady\"}}}}", input.SubscriptionID)
@@ -151,11 +152,14 @@ func (a *SetupService) sendSuccessEvent(input *client.EventStreamConfig) error {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := a.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
 	io.Copy(ioutil.Discard, resp.Body)
 	defer resp.Body.Close()
 
-	return err
+	return nil
 }
 
 func readJSON(s string) *map[string]interface{} {
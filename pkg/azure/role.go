@@ -0,0 +1,266 @@
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/pkg/rest"
+	"github.com/pkg/errors"
+)
+
+// DefaultRoles are the roles assigned to the service principal when none are given
+const DefaultRoles = "Reader"
+
+// authorizationAPIVersion is the version of the Azure authorization API
+const authorizationAPIVersion = "2022-04-01"
+
+// assignmentAttempts is how often a role assignment is tried while the new
+// service principal replicates, assignmentRetryDelay is the delay between tries
+var (
+	assignmentAttempts   = 10
+	assignmentRetryDelay = 6 * time.Second
+)
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+//RoleService creates the app registration and service principal of VSS
+type RoleService struct {
+	api            *restAPI
+	subscriptionID string
+}
+
+// NewRoleService returns a new Azure RoleService
+func NewRoleService(input *NewServiceInput) *RoleService {
+	return &RoleService{
+		api:            newRESTAPI(input),
+		subscriptionID: input.SubscriptionID,
+	}
+}
+
+// application is an app registration of Microsoft Graph
+type application struct {
+	ID          string `json:"id"`
+	AppID       string `json:"appId"`
+	DisplayName string `json:"displayName"`
+}
+
+//CreateServicePrincipal registers an application named after the role, creates
+//its service principal and client secret and assigns the comma separated roles
+//of the policy to it at subscription scope. When a step fails after the
+//application is registered, the service principal with its application ID is
+//returned with the error so that DeleteRole can remove it again.
+func (c *RoleService) CreateServicePrincipal(ctx context.Context, input *client.RoleCreationInfo) (*client.ServicePrincipal, error) {
+	subscriptionID := input.SubscriptionID
+	if subscriptionID == "" {
+		subscriptionID = c.subscriptionID
+	}
+	if subscriptionID == "" {
+		return nil, errors.New("Please provide the Subscription ID to assign the roles in")
+	}
+
+	existing, err := c.findApplications(ctx, input.RoleName)
+	if err != nil {
+		return nil, errors.New("Look up application " + input.RoleName + " failed, " + err.Error())
+	}
+	if len(existing) > 0 {
+		return nil, errors.New("An application named " + input.RoleName + " already exists")
+	}
+
+	app := &application{}
+	err = c.api.graph(ctx, "POST", "applications", map[string]string{"displayName": input.RoleName}, app)
+	if err != nil {
+		return nil, errors.New("Create application " + input.RoleName + " failed, " + err.Error())
+	}
+	sp := &client.ServicePrincipal{ApplicationID: app.AppID}
+
+	principal := struct {
+		ID string `json:"id"`
+	}{}
+	err = c.api.graph(ctx, "POST", "servicePrincipals", map[string]string{"appId": app.AppID}, &principal)
+	if err != nil {
+		return sp, errors.New("Create service principal of " + input.RoleName + " failed, " + err.Error())
+	}
+
+	secret := struct {
+		SecretText string `json:"secretText"`
+	}{}
+	err = c.api.graph(ctx, "POST", "applications/"+app.ID+"/addPassword", map[string]interface{}{
+		"passwordCredential": map[string]string{"displayName": "VMware Secure State"},
+	}, &secret)
+	if err != nil {
+		return sp, errors.New("Create client secret of " + input.RoleName + " failed, " + err.Error())
+	}
+
+	subscription := struct {
+		TenantID string `json:"tenantId"`
+	}{}
+	err = c.api.management(ctx, "GET", "subscriptions/"+subscriptionID+"?api-version=2020-01-01", nil, &subscription)
+	if err != nil {
+		return sp, errors.New("Read subscription " + subscriptionID + " failed, " + err.Error())
+	}
+
+	for _, role := range roles(input.Policy) {
+		if err := c.assignRole(ctx, subscriptionID, principal.ID, role); err != nil {
+			return sp, errors.New("Assign role " + role + " failed, " + err.Error())
+		}
+	}
+
+	sp.DirectoryID = subscription.TenantID
+	sp.KeyValue = secret.SecretText
+	return sp, nil
+}
+
+// roles returns the comma separated role names of policy
+func roles(policy string) []string {
+	if policy == "" {
+		policy = DefaultRoles
+	}
+	var names []string
+	for _, name := range strings.Split(policy, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// assignRole assigns the role definition named role to the principal at subscription scope
+func (c *RoleService) assignRole(ctx context.Context, subscriptionID, principalID, role string) error {
+	scope := "subscriptions/" + subscriptionID
+	definitions := struct {
+		Value []struct {
+			ID string `json:"id"`
+		} `json:"value"`
+	}{}
+	filter := url.QueryEscape("roleName eq " + odataString(role))
+	err := c.api.management(ctx, "GET", scope+"/providers/Microsoft.Authorization/roleDefinitions?api-version="+authorizationAPIVersion+"&$filter="+filter, nil, &definitions)
+	if err != nil {
+		return err
+	}
+	if len(definitions.Value) == 0 {
+		return errors.New("no role named " + role + " in subscription " + subscriptionID)
+	}
+
+	name, err := newGUID()
+	if err != nil {
+		return err
+	}
+	id := scope + "/providers/Microsoft.Authorization/roleAssignments/" + name
+	assignment := map[string]interface{}{
+		"properties": map[string]string{
+			"roleDefinitionId": definitions.Value[0].ID,
+			"principalId":      principalID,
+			"principalType":    "ServicePrincipal",
+		},
+	}
+	for attempt := 1; ; attempt++ {
+		err = c.api.management(ctx, "PUT", id+"?api-version="+authorizationAPIVersion, assignment, nil)
+		// a new service principal takes a while to replicate to Azure Resource Manager
+		if !rest.IsCode(err, "PrincipalNotFound") || attempt == assignmentAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(assignmentRetryDelay):
+		}
+	}
+}
+
+//DeleteRole deletes the role assignments and the application of the service
+//principal, roleName is either the application name or its application ID
+func (c *RoleService) DeleteRole(ctx context.Context, roleName string) error {
+	apps, err := c.findApplications(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return errors.New("No application " + roleName + " found")
+	}
+	if len(apps) > 1 {
+		return errors.New("Several applications are named " + roleName + ", please give the application ID instead")
+	}
+	app := apps[0]
+
+	if c.subscriptionID != "" {
+		assignments, err := c.roleAssignments(ctx, app.AppID)
+		if err != nil {
+			return err
+		}
+		for _, id := range assignments {
+			err := c.api.management(ctx, "DELETE", id+"?api-version="+authorizationAPIVersion, nil, nil)
+			if err != nil && !rest.IsStatus(err, http.StatusNotFound) {
+				return errors.New("Delete role assignment " + id + " failed, " + err.Error())
+			}
+		}
+	}
+
+	err = c.api.graph(ctx, "DELETE", "applications/"+app.ID, nil, nil)
+	if err != nil && !rest.IsStatus(err, http.StatusNotFound) {
+		return errors.New("Delete application " + app.DisplayName + " failed, " + err.Error())
+	}
+	return nil
+}
+
+// findApplications returns the applications with the application ID or the display name
+func (c *RoleService) findApplications(ctx context.Context, nameOrAppID string) ([]application, error) {
+	field := "displayName"
+	if guidPattern.MatchString(nameOrAppID) {
+		field = "appId"
+	}
+	apps := struct {
+		Value []application `json:"value"`
+	}{}
+	err := c.api.graph(ctx, "GET", "applications?$filter="+url.QueryEscape(field+" eq "+odataString(nameOrAppID)), nil, &apps)
+	return apps.Value, err
+}
+
+// roleAssignments returns the IDs of the role assignments of the service
+// principal of the application in the subscription
+func (c *RoleService) roleAssignments(ctx context.Context, appID string) ([]string, error) {
+	principals := struct {
+		Value []struct {
+			ID string `json:"id"`
+		} `json:"value"`
+	}{}
+	err := c.api.graph(ctx, "GET", "servicePrincipals?$filter="+url.QueryEscape("appId eq "+odataString(appID)), nil, &principals)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, principal := range principals.Value {
+		assignments := struct {
+			Value []struct {
+				ID string `json:"id"`
+			} `json:"value"`
+		}{}
+		filter := url.QueryEscape("principalId eq " + odataString(principal.ID))
+		err := c.api.management(ctx, "GET", "subscriptions/"+c.subscriptionID+"/providers/Microsoft.Authorization/roleAssignments?api-version="+authorizationAPIVersion+"&$filter="+filter, nil, &assignments)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignment := range assignments.Value {
+			ids = append(ids, assignment.ID)
+		}
+	}
+	return ids, nil
+}
+
+// newGUID returns a random (version 4) GUID naming a role assignment
+func newGUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
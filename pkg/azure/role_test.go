@@ -0,0 +1,227 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// request is a request received by the test server
+type request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   map[string]interface{}
+}
+
+// newTestService returns a Service calling handler for every API, requests
+// are recorded in the returned slice
+func newTestService(t *testing.T, input *NewServiceInput, handler func(w http.ResponseWriter, r *request)) (*Service, *[]request) {
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query().Get("$filter")}
+		json.NewDecoder(r.Body).Decode(&req.Body)
+		requests = append(requests, req)
+		handler(w, &req)
+	}))
+	t.Cleanup(server.Close)
+
+	s := NewService(input)
+	s.role.api.endpoints = endpoints{
+		Graph:      server.URL + "/graph/",
+		Management: server.URL + "/arm/",
+	}
+	s.role.api.authorizer = func(resource string) (autorest.Authorizer, error) {
+		return autorest.NullAuthorizer{}, nil
+	}
+	return s, &requests
+}
+
+func TestCreateServicePrincipal(t *testing.T) {
+	defer func(attempts int, delay time.Duration) {
+		assignmentAttempts, assignmentRetryDelay = attempts, delay
+	}(assignmentAttempts, assignmentRetryDelay)
+	assignmentAttempts, assignmentRetryDelay = 3, 0
+	var assignment interface{}
+	attempts := 0
+	s, requests := newTestService(t, &NewServiceInput{SubscriptionID: "sub-1"}, func(w http.ResponseWriter, r *request) {
+		switch {
+		case r.Method == "GET" && r.Path == "/graph/applications":
+			assert.Equal(t, "displayName eq 'vss'", r.Query)
+			fmt.Fprint(w, `{"value": []}`)
+		case r.Method == "POST" && r.Path == "/graph/applications":
+			fmt.Fprint(w, `{"id": "object-1", "appId": "app-1", "displayName": "vss"}`)
+		case r.Method == "POST" && r.Path == "/graph/servicePrincipals":
+			assert.Equal(t, "app-1", r.Body["appId"])
+			fmt.Fprint(w, `{"id": "principal-1"}`)
+		case r.Method == "POST" && r.Path == "/graph/applications/object-1/addPassword":
+			fmt.Fprint(w, `{"secretText": "secret-1"}`)
+		case r.Method == "GET" && r.Path == "/arm/subscriptions/sub-1":
+			fmt.Fprint(w, `{"tenantId": "tenant-1"}`)
+		case r.Method == "GET" && r.Path == "/arm/subscriptions/sub-1/providers/Microsoft.Authorization/roleDefinitions":
+			role := strings.TrimSuffix(strings.TrimPrefix(r.Query, "roleName eq '"), "'")
+			fmt.Fprintf(w, `{"value": [{"id": "/definitions/%s"}]}`, role)
+		case r.Method == "PUT" && strings.HasPrefix(r.Path, "/arm/subscriptions/sub-1/providers/Microsoft.Authorization/roleAssignments/"):
+			// the first assignment fails until the service principal is replicated
+			if attempts++; attempts == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": {"code": "PrincipalNotFound", "message": "Principal principal-1 does not exist"}}`)
+				return
+			}
+			assignment = r.Body["properties"]
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	sp, err := s.CreateServicePrincipal(context.Background(), &client.RoleCreationInfo{RoleName: "vss", Policy: "Reader, Security Reader"})
+	assert.Nil(t, err)
+	assert.Equal(t, &client.ServicePrincipal{ApplicationID: "app-1", DirectoryID: "tenant-1", KeyValue: "secret-1"}, sp)
+	assert.Len(t, *requests, 10)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, map[string]interface{}{
+		"roleDefinitionId": "/definitions/Security Reader",
+		"principalId":      "principal-1",
+		"principalType":    "ServicePrincipal",
+	}, assignment)
+}
+
+func TestCreateServicePrincipalFailure(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{SubscriptionID: "sub-1"}, func(w http.ResponseWriter, r *request) {
+		switch {
+		case r.Method == "GET" && r.Path == "/graph/applications":
+			fmt.Fprint(w, `{"value": []}`)
+		case r.Method == "POST" && r.Path == "/graph/applications":
+			fmt.Fprint(w, `{"id": "object-1", "appId": "app-1", "displayName": "vss"}`)
+		case r.Method == "POST" && r.Path == "/graph/servicePrincipals":
+			fmt.Fprint(w, `{"id": "principal-1"}`)
+		case r.Method == "POST" && r.Path == "/graph/applications/object-1/addPassword":
+			fmt.Fprint(w, `{"secretText": "secret-1"}`)
+		case r.Method == "GET" && r.Path == "/arm/subscriptions/sub-1":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"code": "AuthorizationFailed", "message": "Not allowed"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	// the application is returned for the command to delete it again
+	sp, err := s.CreateServicePrincipal(context.Background(), &client.RoleCreationInfo{RoleName: "vss"})
+	assert.EqualError(t, err, "Read subscription sub-1 failed, Not allowed (403 AuthorizationFailed)")
+	assert.Equal(t, &client.ServicePrincipal{ApplicationID: "app-1"}, sp)
+	assert.Len(t, *requests, 5)
+}
+
+func TestAuthorizerCreatedOncePerResource(t *testing.T) {
+	s, _ := newTestService(t, &NewServiceInput{}, func(w http.ResponseWriter, r *request) {
+		fmt.Fprint(w, `{"value": []}`)
+	})
+	created := map[string]int{}
+	s.role.api.authorizer = func(resource string) (autorest.Authorizer, error) {
+		created[resource]++
+		return autorest.NullAuthorizer{}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := s.role.findApplications(context.Background(), "vss")
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, map[string]int{graphResource: 1}, created)
+}
+
+func TestCreateServicePrincipalExists(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{SubscriptionID: "sub-1"}, func(w http.ResponseWriter, r *request) {
+		fmt.Fprint(w, `{"value": [{"id": "object-1", "appId": "app-1", "displayName": "vss"}]}`)
+	})
+
+	_, err := s.CreateServicePrincipal(context.Background(), &client.RoleCreationInfo{RoleName: "vss"})
+	assert.EqualError(t, err, "An application named vss already exists")
+	assert.Len(t, *requests, 1)
+}
+
+func TestDeleteRole(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{SubscriptionID: "sub-1"}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "GET /graph/applications":
+			assert.Equal(t, "appId eq '0f9e3d4c-1b2a-4c5d-8e7f-112233445566'", r.Query)
+			fmt.Fprint(w, `{"value": [{"id": "object-1", "appId": "0f9e3d4c-1b2a-4c5d-8e7f-112233445566", "displayName": "vss"}]}`)
+		case "GET /graph/servicePrincipals":
+			fmt.Fprint(w, `{"value": [{"id": "principal-1"}]}`)
+		case "GET /arm/subscriptions/sub-1/providers/Microsoft.Authorization/roleAssignments":
+			assert.Equal(t, "principalId eq 'principal-1'", r.Query)
+			fmt.Fprint(w, `{"value": [{"id": "/subscriptions/sub-1/providers/Microsoft.Authorization/roleAssignments/a-1"}]}`)
+		case "DELETE /arm/subscriptions/sub-1/providers/Microsoft.Authorization/roleAssignments/a-1", "DELETE /graph/applications/object-1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	assert.Nil(t, s.role.DeleteRole(context.Background(), "0f9e3d4c-1b2a-4c5d-8e7f-112233445566"))
+	assert.Len(t, *requests, 5)
+}
+
+func TestDeleteRoleAmbiguousName(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{}, func(w http.ResponseWriter, r *request) {
+		fmt.Fprint(w, `{"value": [{"id": "object-1"}, {"id": "object-2"}]}`)
+	})
+
+	assert.NotNil(t, s.role.DeleteRole(context.Background(), "vss"))
+	assert.Len(t, *requests, 1)
+}
+
+func TestNewGUID(t *testing.T) {
+	guid, err := newGUID()
+	assert.Nil(t, err)
+	assert.Regexp(t, guidPattern, guid)
+	assert.Equal(t, "4", guid[14:15])
+}
+
+func TestRotateSecrets(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "GET /graph/applications":
+			fmt.Fprint(w, `{"value": [{"id": "object-1", "appId": "0f9e3d4c-1b2a-4c5d-8e7f-112233445566"}]}`)
+		case "GET /graph/applications/object-1":
+			fmt.Fprint(w, `{"passwordCredentials": [{"keyId": "key-1", "hint": "abc", "endDateTime": "2030-01-01T00:00:00Z"}]}`)
+		case "POST /graph/applications/object-1/addPassword":
+			fmt.Fprint(w, `{"keyId": "key-2", "hint": "xyz", "secretText": "xyz-secret"}`)
+		case "POST /graph/applications/object-1/removePassword":
+			assert.Equal(t, "key-1", r.Body["keyId"])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+	ctx, appID := context.Background(), "0f9e3d4c-1b2a-4c5d-8e7f-112233445566"
+
+	secrets, err := s.ListSecrets(ctx, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, []*client.Secret{{KeyID: "key-1", Hint: "abc", EndDateTime: "2030-01-01T00:00:00Z"}}, secrets)
+
+	secret, err := s.AddSecret(ctx, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, "xyz-secret", secret.Value)
+
+	assert.Nil(t, s.RemoveSecret(ctx, appID, "key-1"))
+	assert.Len(t, *requests, 6)
+}
+
+func TestSecretsUnknownApplication(t *testing.T) {
+	s, _ := newTestService(t, &NewServiceInput{}, func(w http.ResponseWriter, r *request) {
+		fmt.Fprint(w, `{"value": []}`)
+	})
+
+	_, err := s.AddSecret(context.Background(), "0f9e3d4c-1b2a-4c5d-8e7f-112233445566")
+	assert.EqualError(t, err, "No application with the application ID 0f9e3d4c-1b2a-4c5d-8e7f-112233445566 found")
+}
@@ -0,0 +1,88 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/pkg/errors"
+)
+
+// passwordCredential is a client secret of an application in Microsoft Graph
+type passwordCredential struct {
+	KeyID       string `json:"keyId"`
+	DisplayName string `json:"displayName"`
+	Hint        string `json:"hint"`
+	EndDateTime string `json:"endDateTime"`
+	SecretText  string `json:"secretText"`
+}
+
+func (p *passwordCredential) secret() *client.Secret {
+	return &client.Secret{
+		KeyID:       p.KeyID,
+		DisplayName: p.DisplayName,
+		Hint:        p.Hint,
+		EndDateTime: p.EndDateTime,
+		Value:       p.SecretText,
+	}
+}
+
+// application returns the application with the application ID
+func (c *RoleService) application(ctx context.Context, appID string) (*application, error) {
+	apps, err := c.findApplications(ctx, appID)
+	if err != nil {
+		return nil, errors.New("Look up application " + appID + " failed, " + err.Error())
+	}
+	if len(apps) != 1 || apps[0].AppID != appID {
+		return nil, errors.New("No application with the application ID " + appID + " found")
+	}
+	return &apps[0], nil
+}
+
+//ListSecrets returns the client secrets of the application, without their value
+func (c *RoleService) ListSecrets(ctx context.Context, appID string) ([]*client.Secret, error) {
+	app, err := c.application(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	credentials := struct {
+		PasswordCredentials []passwordCredential `json:"passwordCredentials"`
+	}{}
+	err = c.api.graph(ctx, "GET", "applications/"+app.ID+"?$select=passwordCredentials", nil, &credentials)
+	if err != nil {
+		return nil, errors.New("List client secrets of " + appID + " failed, " + err.Error())
+	}
+	secrets := make([]*client.Secret, len(credentials.PasswordCredentials))
+	for i := range credentials.PasswordCredentials {
+		secrets[i] = credentials.PasswordCredentials[i].secret()
+	}
+	return secrets, nil
+}
+
+//AddSecret creates a client secret of the application
+func (c *RoleService) AddSecret(ctx context.Context, appID string) (*client.Secret, error) {
+	app, err := c.application(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	credential := &passwordCredential{}
+	err = c.api.graph(ctx, "POST", "applications/"+app.ID+"/addPassword", map[string]interface{}{
+		"passwordCredential": map[string]string{"displayName": "VMware Secure State"},
+	}, credential)
+	if err != nil {
+		return nil, errors.New("Create client secret of " + appID + " failed, " + err.Error())
+	}
+	return credential.secret(), nil
+}
+
+//RemoveSecret deletes the client secret with the key ID of the application
+func (c *RoleService) RemoveSecret(ctx context.Context, appID, keyID string) error {
+	app, err := c.application(ctx, appID)
+	if err != nil {
+		return err
+	}
+	err = c.api.graph(ctx, "POST", "applications/"+app.ID+"/removePassword", map[string]string{"keyId": keyID}, nil)
+	if err != nil {
+		return errors.New("Remove client secret " + keyID + " of " + appID + " failed, " + err.Error())
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package azure
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/CloudCoreo/cli/client"
 )
 
@@ -8,12 +11,17 @@ import (
 type NewServiceInput struct {
 	AuthFile string
 	Region   string
+	// SubscriptionID is the subscription the service principal is assigned roles in
+	SubscriptionID string
+	// HTTPClient sends the webhook requests, http.DefaultClient is used when it is nil.
+	HTTPClient *http.Client
 }
 
-//Service contains setup service and remove service
+//Service contains setup service, remove service and role service
 type Service struct {
 	setup  *SetupService
 	remove *RemoveService
+	role   *RoleService
 }
 
 // NewService returns a new Azure service group
@@ -21,25 +29,57 @@ func NewService(input *NewServiceInput) *Service {
 	return &Service{
 		setup:  NewSetupService(input),
 		remove: NewRemoveService(input),
+		role:   NewRoleService(input),
+	}
+}
+
+func httpClientOrDefault(c *http.Client) *http.Client {
+	if c != nil {
+		return c
 	}
+	return http.DefaultClient
 }
 
 // SetupEventStream calls the SetupEventStream function in SetupService
-func (s *Service) SetupEventStream(input *client.EventStreamConfig) error {
-	return s.setup.SetupEventStream(input)
+func (s *Service) SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error {
+	return s.setup.SetupEventStream(ctx, input)
+}
+
+// CreateNewRole creates the service principal, it returns its application ID and client secret
+func (s *Service) CreateNewRole(ctx context.Context, input *client.RoleCreationInfo) (arn string, externalID string, err error) {
+	sp, err := s.role.CreateServicePrincipal(ctx, input)
+	if sp == nil {
+		return "", "", err
+	}
+	return sp.ApplicationID, sp.KeyValue, err
 }
 
-// CreateNewRole calls the CreateNewRole function in RoleService
-func (s *Service) CreateNewRole(input *client.RoleCreationInfo) (arn string, externalID string, err error) {
-	return "", "", nil
+// CreateServicePrincipal calls the CreateServicePrincipal function in RoleService
+func (s *Service) CreateServicePrincipal(ctx context.Context, input *client.RoleCreationInfo) (*client.ServicePrincipal, error) {
+	return s.role.CreateServicePrincipal(ctx, input)
 }
 
 // DeleteRole calls the DeleteRole function in RoleService
-func (s *Service) DeleteRole(roleName string) {
+func (s *Service) DeleteRole(ctx context.Context, roleName string) error {
+	return s.role.DeleteRole(ctx, roleName)
+}
+
+// ListSecrets calls the ListSecrets function in RoleService
+func (s *Service) ListSecrets(ctx context.Context, applicationID string) ([]*client.Secret, error) {
+	return s.role.ListSecrets(ctx, applicationID)
+}
+
+// AddSecret calls the AddSecret function in RoleService
+func (s *Service) AddSecret(ctx context.Context, applicationID string) (*client.Secret, error) {
+	return s.role.AddSecret(ctx, applicationID)
+}
 
+// RemoveSecret calls the RemoveSecret function in RoleService
+func (s *Service) RemoveSecret(ctx context.Context, applicationID, keyID string) error {
+	return s.role.RemoveSecret(ctx, applicationID, keyID)
 }
 
 //RemoveEventStream perform the same function as event stream removal script
-func (s *Service) RemoveEventStream(input *client.EventRemoveConfig) error {
-	return s.remove.RemoveEventStream(input)
+func (s *Service) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
+	return s.remove.RemoveEventStream(ctx, input)
 }
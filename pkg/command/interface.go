@@ -14,26 +14,77 @@
 
 package command
 
-import "github.com/CloudCoreo/cli/client"
+import (
+	"context"
 
-// Interface for Coreo client for mocking in tests
+	"github.com/CloudCoreo/cli/client"
+)
+
+// Interface for Coreo client for mocking in tests, every method runs under
+// the given context so that it can be cancelled or time out.
 type Interface interface {
-	ListCloudAccounts() ([]*client.CloudAccount, error)
-	ShowCloudAccountByID(cloudID string) (*client.CloudAccount, error)
-	CreateCloudAccount(input *client.CreateCloudAccountInput) (*client.CloudAccount, error)
-	UpdateCloudAccount(input *client.UpdateCloudAccountInput) (*client.CloudAccount, error)
-	DeleteCloudAccountByID(cloudID string) error
-	ReValidateRole(cloudID string) (*client.RoleReValidationResult, error)
+	ListCloudAccounts(ctx context.Context, opts client.ListOptions) ([]*client.CloudAccount, error)
+	ShowCloudAccountByID(ctx context.Context, cloudID string) (*client.CloudAccount, error)
+	CreateCloudAccount(ctx context.Context, input *client.CreateCloudAccountInput) (*client.CloudAccount, error)
+	UpdateCloudAccount(ctx context.Context, input *client.UpdateCloudAccountInput) (*client.CloudAccount, error)
+	DeleteCloudAccountByID(ctx context.Context, cloudID string) error
+	ReValidateRole(ctx context.Context, cloudID string) (*client.RoleReValidationResult, error)
+
+	ListFindings(ctx context.Context, filter *client.FindingFilter, opts client.ListOptions) ([]*client.Finding, error)
 
-	GetEventStreamConfig(cloudID string) (*client.EventStreamConfig, error)
-	GetEventRemoveConfig(cloudID string) (*client.EventRemoveConfig, error)
-	GetRoleCreationInfo(input *client.CreateCloudAccountInput) (*client.RoleCreationInfo, error)
+	GetEventStreamConfig(ctx context.Context, cloudID string) (*client.EventStreamConfig, error)
+	GetEventRemoveConfig(ctx context.Context, cloudID string) (*client.EventRemoveConfig, error)
+	GetRoleCreationInfo(ctx context.Context, input *client.CreateCloudAccountInput) (*client.RoleCreationInfo, error)
+	GetPolicyCatalog(ctx context.Context) (client.PolicyCatalog, error)
 }
 
-//CloudProvider for adding cloud account
+//CloudProvider for adding cloud account. DeleteRole is used for rollback and
+//should be given a context that outlives a cancelled command. CreateNewRole
+//returns the arn of the role along with the error when a step after creating
+//the role failed, so that the caller can delete it again.
 type CloudProvider interface {
-	SetupEventStream(input *client.EventStreamConfig) error
-	CreateNewRole(input *client.RoleCreationInfo) (arn string, externalID string, err error)
-	DeleteRole(roleName string)
-	RemoveEventStream(input *client.EventRemoveConfig) error
+	SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error
+	CreateNewRole(ctx context.Context, input *client.RoleCreationInfo) (arn string, externalID string, err error)
+	DeleteRole(ctx context.Context, roleName string) error
+	RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error
+}
+
+//ServicePrincipalProvider is a CloudProvider creating an Azure service principal
+//in place of a role, the service principal is removed again by DeleteRole. When
+//a later step fails, the created service principal is returned with the error.
+type ServicePrincipalProvider interface {
+	CreateServicePrincipal(ctx context.Context, input *client.RoleCreationInfo) (*client.ServicePrincipal, error)
+}
+
+//ServiceAccountProvider is a CloudProvider creating a GCP service account in
+//place of a role, the service account is removed again by DeleteRole. When a
+//later step fails, the created service account is returned with the error.
+type ServiceAccountProvider interface {
+	CreateServiceAccount(ctx context.Context, input *client.RoleCreationInfo) (*client.ServiceAccount, error)
+}
+
+//ExternalIDUpdater is a CloudProvider changing the external IDs an AWS role
+//trusts, current is replaced with externalIDs in the trust policy
+type ExternalIDUpdater interface {
+	UpdateExternalID(ctx context.Context, roleName, current string, externalIDs []string) error
+}
+
+//InlinePolicyUpdater is a CloudProvider putting the inline policy allowing the
+//actions of a catalog on an AWS role, it tells whether the policy changed
+type InlinePolicyUpdater interface {
+	UpdateInlinePolicy(ctx context.Context, roleName string, catalog client.PolicyCatalog) (bool, error)
+}
+
+//SecretRotator is a CloudProvider managing the client secrets of an Azure application
+type SecretRotator interface {
+	ListSecrets(ctx context.Context, applicationID string) ([]*client.Secret, error)
+	AddSecret(ctx context.Context, applicationID string) (*client.Secret, error)
+	RemoveSecret(ctx context.Context, applicationID, keyID string) error
+}
+
+//Organization lists the member accounts of a cloud provider organization and
+//returns the CloudProvider acting in one of them
+type Organization interface {
+	ListAccounts(ctx context.Context) ([]*client.OrganizationAccount, error)
+	AccountProvider(ctx context.Context, account *client.OrganizationAccount) (CloudProvider, error)
 }
@@ -15,12 +15,23 @@
 package coreo
 
 import (
+	"context"
+	"net/http"
+	"sync"
+
 	"github.com/CloudCoreo/cli/client"
 )
 
+// defaultHTTPClient is shared by clients that are not given one, so that
+// connections to the API are reused.
+var defaultHTTPClient = &http.Client{Transport: client.NewTransport()}
+
 // Client struct
 type Client struct {
 	opts options
+
+	mu  sync.Mutex
+	clt *client.Client
 }
 
 // NewClient creates a new client.
@@ -31,26 +42,61 @@ func NewClient(opts ...Option) *Client {
 
 // Option configures the Coreo client with the provided options
 func (c *Client) Option(opts ...Option) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for _, opt := range opts {
 		opt(&c.opts)
 	}
+	// rebuild the API client with the new options on next use
+	c.clt = nil
 	return c
 }
 
-//MakeClient make client method
+//MakeClient returns the API client, it is built on first use and shared by all calls.
 func (c *Client) MakeClient() (*client.Client, error) {
-	return client.MakeClient(c.opts.refreshToken, c.opts.host)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.clt != nil {
+		return c.clt, nil
+	}
+
+	retry := client.DefaultRetryPolicy
+	retry.MaxAttempts = c.opts.retries + 1
+
+	httpClient := c.opts.httpClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+
+	opts := []client.Option{
+		client.WithHTTPClient(httpClient),
+		client.WithUserAgent(c.opts.userAgent),
+		client.WithTokenCacheFile(c.opts.tokenCacheFile),
+		client.WithCSPEndpoint(c.opts.cspEndpoint),
+		client.WithRetryPolicy(retry),
+	}
+	for _, interceptor := range c.opts.interceptors {
+		opts = append(opts, client.WithInterceptor(interceptor))
+	}
+
+	clt, err := client.MakeClient(c.opts.refreshToken, c.opts.host, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.clt = clt
+	return clt, nil
 }
 
 //ListCloudAccounts Get list of cloud accounts
-func (c *Client) ListCloudAccounts() ([]*client.CloudAccount, error) {
-	ctx := NewContext()
+func (c *Client) ListCloudAccounts(ctx context.Context, opts client.ListOptions) ([]*client.CloudAccount, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
 	}
 
-	cloudAccounts, err := clt.GetCloudAccounts(ctx)
+	cloudAccounts, err := clt.GetCloudAccounts(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +105,7 @@ func (c *Client) ListCloudAccounts() ([]*client.CloudAccount, error) {
 }
 
 //ShowCloudAccountByID show cloud account by ID
-func (c *Client) ShowCloudAccountByID(cloudID string) (*client.CloudAccount, error) {
-	ctx := NewContext()
+func (c *Client) ShowCloudAccountByID(ctx context.Context, cloudID string) (*client.CloudAccount, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -75,8 +120,7 @@ func (c *Client) ShowCloudAccountByID(cloudID string) (*client.CloudAccount, err
 }
 
 //CreateCloudAccount Create cloud account
-func (c *Client) CreateCloudAccount(input *client.CreateCloudAccountInput) (*client.CloudAccount, error) {
-	ctx := NewContext()
+func (c *Client) CreateCloudAccount(ctx context.Context, input *client.CreateCloudAccountInput) (*client.CloudAccount, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -90,8 +134,7 @@ func (c *Client) CreateCloudAccount(input *client.CreateCloudAccountInput) (*cli
 
 }
 
-func (c *Client) UpdateCloudAccount(input *client.UpdateCloudAccountInput) (*client.CloudAccount, error) {
-	ctx := NewContext()
+func (c *Client) UpdateCloudAccount(ctx context.Context, input *client.UpdateCloudAccountInput) (*client.CloudAccount, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -105,8 +148,7 @@ func (c *Client) UpdateCloudAccount(input *client.UpdateCloudAccountInput) (*cli
 }
 
 //DeleteCloudAccountByID Delete cloud by ID
-func (c *Client) DeleteCloudAccountByID(cloudID string) error {
-	ctx := NewContext()
+func (c *Client) DeleteCloudAccountByID(ctx context.Context, cloudID string) error {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return err
@@ -120,8 +162,7 @@ func (c *Client) DeleteCloudAccountByID(cloudID string) error {
 	return nil
 }
 
-func (c *Client) ReValidateRole(cloudID string) (*client.RoleReValidationResult, error) {
-	ctx := NewContext()
+func (c *Client) ReValidateRole(ctx context.Context, cloudID string) (*client.RoleReValidationResult, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -134,9 +175,18 @@ func (c *Client) ReValidateRole(cloudID string) (*client.RoleReValidationResult,
 	return result, nil
 }
 
+//ListFindings lists the findings matching the filter
+func (c *Client) ListFindings(ctx context.Context, filter *client.FindingFilter, opts client.ListOptions) ([]*client.Finding, error) {
+	clt, err := c.MakeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return clt.ListFindings(ctx, filter, opts)
+}
+
 //GetEventStreamConfig gets event stream setup config
-func (c *Client) GetEventStreamConfig(cloudID string) (*client.EventStreamConfig, error) {
-	ctx := NewContext()
+func (c *Client) GetEventStreamConfig(ctx context.Context, cloudID string) (*client.EventStreamConfig, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -145,8 +195,7 @@ func (c *Client) GetEventStreamConfig(cloudID string) (*client.EventStreamConfig
 	return clt.GetSetupConfig(ctx, cloudID)
 }
 
-func (c *Client) GetEventRemoveConfig(cloudID string) (*client.EventRemoveConfig, error) {
-	ctx := NewContext()
+func (c *Client) GetEventRemoveConfig(ctx context.Context, cloudID string) (*client.EventRemoveConfig, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -155,8 +204,7 @@ func (c *Client) GetEventRemoveConfig(cloudID string) (*client.EventRemoveConfig
 	return clt.GetRemoveConfig(ctx, cloudID)
 }
 
-func (c *Client) GetRoleCreationInfo(input *client.CreateCloudAccountInput) (*client.RoleCreationInfo, error) {
-	ctx := NewContext()
+func (c *Client) GetRoleCreationInfo(ctx context.Context, input *client.CreateCloudAccountInput) (*client.RoleCreationInfo, error) {
 	clt, err := c.MakeClient()
 	if err != nil {
 		return nil, err
@@ -164,3 +212,13 @@ func (c *Client) GetRoleCreationInfo(input *client.CreateCloudAccountInput) (*cl
 
 	return clt.GetRoleCreationInfo(ctx, input)
 }
+
+//GetPolicyCatalog returns the policy catalog of the VSS configuration
+func (c *Client) GetPolicyCatalog(ctx context.Context) (client.PolicyCatalog, error) {
+	clt, err := c.MakeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return clt.GetPolicyCatalog(ctx)
+}
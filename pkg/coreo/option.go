@@ -15,7 +15,9 @@
 package coreo
 
 import (
-	"context"
+	"net/http"
+
+	"github.com/CloudCoreo/cli/client"
 )
 
 // Option allows specifying various settings configurable by
@@ -23,8 +25,14 @@ import (
 type Option func(*options)
 
 type options struct {
-	host         string
-	refreshToken string
+	host           string
+	refreshToken   string
+	tokenCacheFile string
+	cspEndpoint    string
+	retries        int
+	httpClient     *http.Client
+	userAgent      string
+	interceptors   []client.Interceptor
 }
 
 // Host specifies the host address of the Coreo API server.
@@ -41,7 +49,44 @@ func RefreshToken(refreshToken string) Option {
 	}
 }
 
-// NewContext creates a versioned context.
-func NewContext() context.Context {
-	return context.Background()
+//CSPEndpoint specifies the CSP identity endpoint, it is discovered from the Coreo API server when empty.
+func CSPEndpoint(endpoint string) Option {
+	return func(opts *options) {
+		opts.cspEndpoint = endpoint
+	}
+}
+
+//TokenCacheFile specifies the file the CSP access token is cached in between runs.
+func TokenCacheFile(path string) Option {
+	return func(opts *options) {
+		opts.tokenCacheFile = path
+	}
+}
+
+//Retries specifies how often a failed request to the Coreo API server is retried.
+func Retries(retries int) Option {
+	return func(opts *options) {
+		opts.retries = retries
+	}
+}
+
+//HTTPClient specifies the http.Client requests are sent with, a client sharing a keep-alive transport is used by default.
+func HTTPClient(httpClient *http.Client) Option {
+	return func(opts *options) {
+		opts.httpClient = httpClient
+	}
+}
+
+//UserAgent specifies the User-Agent header sent to the Coreo API server.
+func UserAgent(userAgent string) Option {
+	return func(opts *options) {
+		opts.userAgent = userAgent
+	}
+}
+
+//Interceptors adds interceptors that are run on every request after it is signed.
+func Interceptors(interceptors ...client.Interceptor) Option {
+	return func(opts *options) {
+		opts.interceptors = append(opts.interceptors, interceptors...)
+	}
 }
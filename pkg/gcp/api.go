@@ -0,0 +1,286 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CloudCoreo/cli/pkg/rest"
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// cloudPlatformScope is the OAuth scope of the access tokens
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// endpoints are the base URLs of the Google Cloud APIs
+type endpoints struct {
+	IAM             string
+	ResourceManager string
+	PubSub          string
+	Logging         string
+	Token           string
+}
+
+var defaultEndpoints = endpoints{
+	IAM:             "https://iam.googleapis.com/v1/",
+	ResourceManager: "https://cloudresourcemanager.googleapis.com/v1/",
+	PubSub:          "https://pubsub.googleapis.com/v1/",
+	Logging:         "https://logging.googleapis.com/v2/",
+	Token:           "https://oauth2.googleapis.com/token",
+}
+
+// restAPI calls the Google Cloud REST APIs
+type restAPI struct {
+	httpClient      *http.Client
+	credentialsFile string
+	endpoints       endpoints
+	// token returns the access token of the requests
+	token func(ctx context.Context) (string, error)
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+func newRESTAPI(input *NewServiceInput) *restAPI {
+	api := &restAPI{
+		httpClient:      input.HTTPClient,
+		credentialsFile: input.CredentialsFile,
+		endpoints:       defaultEndpoints,
+	}
+	if api.httpClient == nil {
+		api.httpClient = http.DefaultClient
+	}
+	api.token = api.cachedToken
+	return api
+}
+
+// do sends a request with a JSON body and decodes the JSON response into out
+func (api *restAPI) do(ctx context.Context, method, url string, in, out interface{}) error {
+	return rest.Do(ctx, api.httpClient, api.authorize, method, url, in, out)
+}
+
+// authorize sets the access token of a request
+func (api *restAPI) authorize(req *http.Request) (*http.Request, error) {
+	token, err := api.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// iamPolicy is the IAM policy of a Google Cloud resource
+type iamPolicy struct {
+	Version  int           `json:"version,omitempty"`
+	Etag     string        `json:"etag,omitempty"`
+	Bindings []*iamBinding `json:"bindings,omitempty"`
+}
+
+type iamBinding struct {
+	Role      string          `json:"role"`
+	Members   []string        `json:"members"`
+	Condition json.RawMessage `json:"condition,omitempty"`
+}
+
+// addMember adds member to the unconditional binding of role, it returns false
+// when the member is already bound
+func (p *iamPolicy) addMember(role, member string) bool {
+	for _, binding := range p.Bindings {
+		if binding.Role != role || len(binding.Condition) > 0 {
+			continue
+		}
+		for _, m := range binding.Members {
+			if m == member {
+				return false
+			}
+		}
+		binding.Members = append(binding.Members, member)
+		return true
+	}
+	p.Bindings = append(p.Bindings, &iamBinding{Role: role, Members: []string{member}})
+	return true
+}
+
+// removeMember removes member from all bindings, it returns false when it isn't bound
+func (p *iamPolicy) removeMember(member string) bool {
+	removed := false
+	bindings := p.Bindings[:0]
+	for _, binding := range p.Bindings {
+		members := binding.Members[:0]
+		for _, m := range binding.Members {
+			if m == member {
+				removed = true
+				continue
+			}
+			members = append(members, m)
+		}
+		binding.Members = members
+		if len(members) > 0 {
+			bindings = append(bindings, binding)
+		}
+	}
+	p.Bindings = bindings
+	return removed
+}
+
+// updatePolicy reads the IAM policy of a resource, changes it with update and
+// writes it back when update returns true. It retries when the policy was
+// changed concurrently.
+func (api *restAPI) updatePolicy(ctx context.Context, getMethod, resourceURL string, update func(*iamPolicy) bool) error {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		policy := &iamPolicy{}
+		var in interface{}
+		if getMethod == "POST" {
+			in = map[string]interface{}{"options": map[string]int{"requestedPolicyVersion": 3}}
+		}
+		if err = api.do(ctx, getMethod, resourceURL+":getIamPolicy", in, policy); err != nil {
+			return err
+		}
+		if !update(policy) {
+			return nil
+		}
+		err = api.do(ctx, "POST", resourceURL+":setIamPolicy", map[string]interface{}{"policy": policy}, nil)
+		if !rest.IsStatus(err, http.StatusConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// credentialsFile is a service account key or an authorized user file as
+// written by 'gcloud auth application-default login'
+type credentialsFile struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// cachedToken returns the cached access token or requests a new one when it expires in less than a minute
+func (api *restAPI) cachedToken(ctx context.Context) (string, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.accessToken != "" && time.Now().Add(time.Minute).Before(api.expiry) {
+		return api.accessToken, nil
+	}
+
+	creds, err := api.readCredentials()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	tokenURL := api.endpoints.Token
+	switch creds.Type {
+	case "service_account":
+		if creds.TokenURI != "" && api.endpoints.Token == defaultEndpoints.Token {
+			tokenURL = creds.TokenURI
+		}
+		assertion, err := signAssertion(creds, tokenURL, time.Now())
+		if err != nil {
+			return "", err
+		}
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+		form.Set("assertion", assertion)
+	case "authorized_user":
+		form.Set("grant_type", "refresh_token")
+		form.Set("client_id", creds.ClientID)
+		form.Set("client_secret", creds.ClientSecret)
+		form.Set("refresh_token", creds.RefreshToken)
+	default:
+		return "", fmt.Errorf("unsupported GCP credentials type %q, expected a service account key or an authorized user file", creds.Type)
+	}
+
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := api.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	token := struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || resp.StatusCode >= 300 || token.AccessToken == "" {
+		return "", fmt.Errorf("GCP token request failed with status %d: %s", resp.StatusCode, token.ErrorDescription)
+	}
+
+	api.accessToken = token.AccessToken
+	api.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return api.accessToken, nil
+}
+
+// readCredentials reads the credentials file, GOOGLE_APPLICATION_CREDENTIALS
+// or the application default credentials of gcloud in that order
+func (api *restAPI) readCredentials() (*credentialsFile, error) {
+	path := api.credentialsFile
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		path = filepath.Join(os.Getenv("HOME"), ".config", "gcloud", "application_default_credentials.json")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no GCP credentials found, use --gcp-credentials or set GOOGLE_APPLICATION_CREDENTIALS: %v", err)
+	}
+	creds := &credentialsFile{}
+	if err := json.Unmarshal(b, creds); err != nil {
+		return nil, fmt.Errorf("invalid GCP credentials file %s: %v", path, err)
+	}
+	return creds, nil
+}
+
+// signAssertion returns the signed JWT exchanged for an access token of a service account
+func signAssertion(creds *credentialsFile, audience string, now time.Time) (string, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   creds.ClientEmail,
+		"scope": cloudPlatformScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = creds.PrivateKeyID
+	return token.SignedString(key)
+}
+
+// ReadServiceAccountKey reads a JSON key file of a service account, it returns
+// the email of the service account and the content of the file
+func ReadServiceAccountKey(path string) (email string, key string, err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	creds := &credentialsFile{}
+	if err := json.Unmarshal(b, creds); err != nil || creds.Type != "service_account" {
+		return "", "", fmt.Errorf("%s is not the JSON key of a service account", path)
+	}
+	return creds.ClientEmail, string(b), nil
+}
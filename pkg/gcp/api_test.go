@@ -0,0 +1,84 @@
+package gcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+		token, err := jwt.Parse(r.FormValue("assertion"), func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+		assert.Nil(t, err)
+		claims := token.Claims.(jwt.MapClaims)
+		assert.Equal(t, "vss@project-1.iam.gserviceaccount.com", claims["iss"])
+		assert.Equal(t, cloudPlatformScope, claims["scope"])
+		assert.Equal(t, "key-1", token.Header["kid"])
+		fmt.Fprint(w, `{"access_token": "token-1", "expires_in": 3600}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	b, _ := json.Marshal(credentialsFile{
+		Type:         "service_account",
+		PrivateKeyID: "key-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		ClientEmail:  "vss@project-1.iam.gserviceaccount.com",
+		TokenURI:     "https://oauth2.googleapis.com/token",
+	})
+	assert.Nil(t, ioutil.WriteFile(path, b, 0600))
+
+	api := newRESTAPI(&NewServiceInput{CredentialsFile: path})
+	api.endpoints.Token = server.URL
+
+	for i := 0; i < 2; i++ {
+		token, err := api.token(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, 1, requests, "the token should be cached")
+
+	email, content, err := ReadServiceAccountKey(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "vss@project-1.iam.gserviceaccount.com", email)
+	assert.Equal(t, string(b), content)
+}
+
+func TestAuthorizedUserToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "refresh_token", r.FormValue("grant_type"))
+		assert.Equal(t, "refresh-1", r.FormValue("refresh_token"))
+		fmt.Fprint(w, `{"access_token": "token-2", "expires_in": 3600}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "application_default_credentials.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"type": "authorized_user", "client_id": "id", "client_secret": "secret", "refresh_token": "refresh-1"}`), 0600))
+
+	api := newRESTAPI(&NewServiceInput{CredentialsFile: path})
+	api.endpoints.Token = server.URL
+	token, err := api.token(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2", token)
+
+	_, _, err = ReadServiceAccountKey(path)
+	assert.NotNil(t, err)
+}
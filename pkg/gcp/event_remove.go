@@ -0,0 +1,43 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/pkg/rest"
+	"github.com/pkg/errors"
+)
+
+//RemoveService removes the GCP event stream
+type RemoveService struct {
+	api       *restAPI
+	projectID string
+}
+
+//RemoveEventStream deletes the subscription, the log sink and the topic of the
+//event stream, resources that are already gone are skipped
+func (a *RemoveService) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
+	projectID := input.ProjectID
+	if projectID == "" {
+		projectID = a.projectID
+	}
+	project := "projects/" + projectID
+
+	resources := []struct{ name, url string }{
+		{input.PubSubSubscription, a.api.endpoints.PubSub + project + "/subscriptions/" + input.PubSubSubscription},
+		{input.SinkName, a.api.endpoints.Logging + project + "/sinks/" + url.PathEscape(input.SinkName)},
+		{input.PubSubTopic, a.api.endpoints.PubSub + project + "/topics/" + input.PubSubTopic},
+	}
+	for _, resource := range resources {
+		if resource.name == "" {
+			continue
+		}
+		err := a.api.do(ctx, "DELETE", resource.url, nil, nil)
+		if err != nil && !rest.IsStatus(err, http.StatusNotFound) {
+			return errors.New("Delete " + resource.name + " failed, " + err.Error())
+		}
+	}
+	return nil
+}
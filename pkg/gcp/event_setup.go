@@ -0,0 +1,80 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/CloudCoreo/cli/pkg/rest"
+	"github.com/pkg/errors"
+)
+
+//SetupService sets up the GCP event stream
+type SetupService struct {
+	api       *restAPI
+	projectID string
+}
+
+//SetupEventStream creates the Pub/Sub topic, the log sink publishing audit logs
+//to it and the subscription pushing them to VSS. Existing resources are updated.
+func (a *SetupService) SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error {
+	projectID := input.ProjectID
+	if projectID == "" {
+		projectID = a.projectID
+	}
+	if projectID == "" || input.PubSubTopic == "" || input.PubSubSubscription == "" || input.SinkName == "" || input.PushEndpoint == "" {
+		return errors.New("The event stream config is missing the project, topic, subscription, sink or push endpoint")
+	}
+	project := "projects/" + projectID
+	topic := project + "/topics/" + input.PubSubTopic
+
+	err := a.api.do(ctx, "PUT", a.api.endpoints.PubSub+topic, map[string]string{}, nil)
+	if err != nil && !rest.IsStatus(err, http.StatusConflict) {
+		return errors.New("Create topic " + input.PubSubTopic + " failed, " + err.Error())
+	}
+
+	writer, err := a.createSink(ctx, project, topic, input)
+	if err != nil {
+		return errors.New("Create log sink " + input.SinkName + " failed, " + err.Error())
+	}
+	err = a.api.updatePolicy(ctx, "GET", a.api.endpoints.PubSub+topic, func(policy *iamPolicy) bool {
+		return policy.addMember("roles/pubsub.publisher", writer)
+	})
+	if err != nil {
+		return errors.New("Allow log sink " + input.SinkName + " to publish failed, " + err.Error())
+	}
+
+	pushConfig := map[string]string{"pushEndpoint": input.PushEndpoint}
+	subscription := project + "/subscriptions/" + input.PubSubSubscription
+	err = a.api.do(ctx, "PUT", a.api.endpoints.PubSub+subscription, map[string]interface{}{
+		"topic":              topic,
+		"pushConfig":         pushConfig,
+		"ackDeadlineSeconds": 60,
+	}, nil)
+	if rest.IsStatus(err, http.StatusConflict) {
+		err = a.api.do(ctx, "POST", a.api.endpoints.PubSub+subscription+":modifyPushConfig", map[string]interface{}{"pushConfig": pushConfig}, nil)
+	}
+	if err != nil {
+		return errors.New("Create subscription " + input.PubSubSubscription + " failed, " + err.Error())
+	}
+	return nil
+}
+
+// createSink creates or updates the log sink, it returns its writer identity
+func (a *SetupService) createSink(ctx context.Context, project, topic string, input *client.EventStreamConfig) (string, error) {
+	sink := map[string]string{
+		"name":        input.SinkName,
+		"destination": "pubsub.googleapis.com/" + topic,
+		"filter":      input.SinkFilter,
+	}
+	created := struct {
+		WriterIdentity string `json:"writerIdentity"`
+	}{}
+	query := "?uniqueWriterIdentity=true"
+	err := a.api.do(ctx, "POST", a.api.endpoints.Logging+project+"/sinks"+query, sink, &created)
+	if rest.IsStatus(err, http.StatusConflict) {
+		err = a.api.do(ctx, "PUT", a.api.endpoints.Logging+project+"/sinks/"+url.PathEscape(input.SinkName)+query, sink, &created)
+	}
+	return created.WriterIdentity, err
+}
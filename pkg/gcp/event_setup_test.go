@@ -0,0 +1,87 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupEventStream(t *testing.T) {
+	var publisher, push interface{}
+	s, requests := newTestService(t, &NewServiceInput{}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "PUT /pubsub/projects/project-1/topics/vss-events":
+			fmt.Fprint(w, `{}`)
+		case "POST /logging/projects/project-1/sinks":
+			// the sink exists and is updated instead
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error": {"code": 409, "message": "Sink vss-sink already exists", "status": "ALREADY_EXISTS"}}`)
+		case "PUT /logging/projects/project-1/sinks/vss-sink":
+			assert.Equal(t, "pubsub.googleapis.com/projects/project-1/topics/vss-events", r.Body["destination"])
+			fmt.Fprint(w, `{"writerIdentity": "serviceAccount:p123-456@gcp-sa-logging.iam.gserviceaccount.com"}`)
+		case "GET /pubsub/projects/project-1/topics/vss-events:getIamPolicy":
+			fmt.Fprint(w, `{}`)
+		case "POST /pubsub/projects/project-1/topics/vss-events:setIamPolicy":
+			bindings := r.Body["policy"].(map[string]interface{})["bindings"].([]interface{})
+			publisher = bindings[0]
+			fmt.Fprint(w, `{}`)
+		case "PUT /pubsub/projects/project-1/subscriptions/vss-push":
+			push = r.Body["pushConfig"]
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	config := &client.EventStreamConfig{Provider: "GCP"}
+	config.ProjectID = "project-1"
+	config.PubSubTopic = "vss-events"
+	config.PubSubSubscription = "vss-push"
+	config.PushEndpoint = "https://events.example.com/gcp"
+	config.SinkName = "vss-sink"
+	config.SinkFilter = `logName:"cloudaudit.googleapis.com"`
+
+	assert.Nil(t, s.SetupEventStream(context.Background(), config))
+	assert.Len(t, *requests, 6)
+	assert.Equal(t, map[string]interface{}{
+		"role":    "roles/pubsub.publisher",
+		"members": []interface{}{"serviceAccount:p123-456@gcp-sa-logging.iam.gserviceaccount.com"},
+	}, publisher)
+	assert.Equal(t, map[string]interface{}{"pushEndpoint": "https://events.example.com/gcp"}, push)
+}
+
+func TestSetupEventStreamMissingConfig(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{}, func(w http.ResponseWriter, r *request) {})
+
+	config := &client.EventStreamConfig{Provider: "GCP"}
+	config.PubSubTopic = "vss-events"
+	assert.NotNil(t, s.SetupEventStream(context.Background(), config))
+	assert.Len(t, *requests, 0)
+}
+
+func TestRemoveEventStream(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{ProjectID: "project-1"}, func(w http.ResponseWriter, r *request) {
+		if r.Path == "/logging/projects/project-1/sinks/vss-sink" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404, "message": "Sink vss-sink does not exist", "status": "NOT_FOUND"}}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	config := &client.EventRemoveConfig{Provider: "GCP"}
+	config.PubSubTopic = "vss-events"
+	config.PubSubSubscription = "vss-push"
+	config.SinkName = "vss-sink"
+
+	assert.Nil(t, s.RemoveEventStream(context.Background(), config))
+	assert.Equal(t, []request{
+		{Method: "DELETE", Path: "/pubsub/projects/project-1/subscriptions/vss-push"},
+		{Method: "DELETE", Path: "/logging/projects/project-1/sinks/vss-sink"},
+		{Method: "DELETE", Path: "/pubsub/projects/project-1/topics/vss-events"},
+	}, *requests)
+}
@@ -0,0 +1,154 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/pkg/errors"
+)
+
+// RoleService manages the service accounts VSS uses to read GCP projects
+type RoleService struct {
+	api                      *restAPI
+	projectID                string
+	workloadIdentityProvider string
+	workloadIdentitySubject  string
+}
+
+// CreateServiceAccount creates a service account named after the role and
+// grants it the roles of the comma separated policy on the project. It gets a
+// new JSON key, or none when the workload identity provider is allowed to
+// impersonate it. When granting the roles or creating the key fails, the
+// service account is returned with the error so that it can be deleted again.
+func (c *RoleService) CreateServiceAccount(ctx context.Context, input *client.RoleCreationInfo) (*client.ServiceAccount, error) {
+	if c.projectID == "" {
+		return nil, errors.New("a GCP project ID is required to create a service account")
+	}
+	var principal string
+	if c.workloadIdentityProvider != "" {
+		var err error
+		if principal, err = workloadIdentityPrincipal(c.workloadIdentityProvider, c.workloadIdentitySubject); err != nil {
+			return nil, err
+		}
+	}
+
+	created := struct {
+		Email string `json:"email"`
+	}{}
+	err := c.api.do(ctx, "POST", c.api.endpoints.IAM+"projects/"+c.projectID+"/serviceAccounts", map[string]interface{}{
+		"accountId": input.RoleName,
+		"serviceAccount": map[string]string{
+			"displayName": input.RoleName,
+			"description": "VMware Secure State",
+		},
+	}, &created)
+	if err != nil {
+		return nil, errors.New("Create service account " + input.RoleName + " failed, " + err.Error())
+	}
+	account := &client.ServiceAccount{Email: created.Email}
+
+	member := "serviceAccount:" + account.Email
+	err = c.api.updatePolicy(ctx, "POST", c.api.endpoints.ResourceManager+"projects/"+c.projectID, func(policy *iamPolicy) bool {
+		changed := false
+		for _, role := range roles(input.Policy) {
+			changed = policy.addMember(role, member) || changed
+		}
+		return changed
+	})
+	if err != nil {
+		return account, errors.New("Grant roles to " + account.Email + " failed, " + err.Error())
+	}
+
+	accountURL := c.serviceAccountURL(account.Email)
+	if principal != "" {
+		err = c.api.updatePolicy(ctx, "POST", accountURL, func(policy *iamPolicy) bool {
+			return policy.addMember("roles/iam.workloadIdentityUser", principal)
+		})
+		if err != nil {
+			return account, errors.New("Allow workload identity for " + account.Email + " failed, " + err.Error())
+		}
+		return account, nil
+	}
+
+	key := struct {
+		PrivateKeyData string `json:"privateKeyData"`
+	}{}
+	if err := c.api.do(ctx, "POST", accountURL+"/keys", map[string]string{}, &key); err != nil {
+		return account, errors.New("Create key for " + account.Email + " failed, " + err.Error())
+	}
+	b, err := base64.StdEncoding.DecodeString(key.PrivateKeyData)
+	if err != nil {
+		return account, err
+	}
+	account.Key = string(b)
+	return account, nil
+}
+
+// DeleteRole removes the roles of the service account named after the role from the project and deletes it
+func (c *RoleService) DeleteRole(ctx context.Context, roleName string) error {
+	email := c.serviceAccountEmail(roleName)
+	member := "serviceAccount:" + email
+	err := c.api.updatePolicy(ctx, "POST", c.api.endpoints.ResourceManager+"projects/"+c.projectID, func(policy *iamPolicy) bool {
+		return policy.removeMember(member)
+	})
+	if err != nil {
+		return errors.New("Remove roles of " + email + " failed, " + err.Error())
+	}
+
+	if err := c.api.do(ctx, "DELETE", c.serviceAccountURL(email), nil, nil); err != nil {
+		return errors.New("Delete service account " + email + " failed, " + err.Error())
+	}
+	return nil
+}
+
+func (c *RoleService) serviceAccountEmail(roleName string) string {
+	if strings.Contains(roleName, "@") {
+		return roleName
+	}
+	return fmt.Sprintf("%s@%s.iam.gserviceaccount.com", roleName, c.projectID)
+}
+
+func (c *RoleService) serviceAccountURL(email string) string {
+	return c.api.endpoints.IAM + "projects/" + c.projectID + "/serviceAccounts/" + url.PathEscape(email)
+}
+
+// roles splits a comma separated policy, it returns DefaultRoles when it is empty
+func roles(policy string) []string {
+	if strings.TrimSpace(policy) == "" {
+		policy = DefaultRoles
+	}
+	var roles []string
+	for _, role := range strings.Split(policy, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// workloadIdentityPrincipal returns the principal of the identities of the pool
+// of a provider projects/NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER
+// with the subject, or with the attribute of an attribute.NAME/VALUE subject.
+// Granting the whole pool would let any identity of it impersonate the account.
+func workloadIdentityPrincipal(provider, subject string) (string, error) {
+	provider = strings.TrimPrefix(provider, "//iam.googleapis.com/")
+	i := strings.Index(provider, "/providers/")
+	if !strings.HasPrefix(provider, "projects/") || !strings.Contains(provider, "/workloadIdentityPools/") || i < 0 {
+		return "", fmt.Errorf("invalid workload identity provider %s, expected projects/NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER", provider)
+	}
+	pool := "iam.googleapis.com/" + provider[:i]
+	if strings.HasPrefix(subject, "attribute.") {
+		if j := strings.Index(subject, "/"); j > len("attribute.") && j < len(subject)-1 {
+			return "principalSet://" + pool + "/" + subject, nil
+		}
+		return "", fmt.Errorf("invalid workload identity attribute %s, expected attribute.NAME/VALUE", subject)
+	}
+	if subject == "" {
+		return "", errors.New("a workload identity subject is required to allow the workload identity provider to impersonate the service account")
+	}
+	return "principal://" + pool + "/subject/" + subject, nil
+}
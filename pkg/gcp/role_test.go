@@ -0,0 +1,209 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// request is a request received by the test server
+type request struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// newTestService returns a Service calling handler for every API, requests
+// are recorded in the returned slice
+func newTestService(t *testing.T, input *NewServiceInput, handler func(w http.ResponseWriter, r *request)) (*Service, *[]request) {
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		req := request{Method: r.Method, Path: r.URL.Path}
+		json.NewDecoder(r.Body).Decode(&req.Body)
+		requests = append(requests, req)
+		handler(w, &req)
+	}))
+	t.Cleanup(server.Close)
+
+	s := NewService(input)
+	api := s.role.api
+	api.endpoints = endpoints{
+		IAM:             server.URL + "/iam/",
+		ResourceManager: server.URL + "/crm/",
+		PubSub:          server.URL + "/pubsub/",
+		Logging:         server.URL + "/logging/",
+		Token:           server.URL + "/token",
+	}
+	api.token = func(ctx context.Context) (string, error) { return "test-token", nil }
+	return s, &requests
+}
+
+func TestCreateNewRole(t *testing.T) {
+	key := `{"type": "service_account", "client_email": "vss@project-1.iam.gserviceaccount.com"}`
+	var policy map[string]interface{}
+	s, requests := newTestService(t, &NewServiceInput{ProjectID: "project-1"}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "POST /iam/projects/project-1/serviceAccounts":
+			fmt.Fprint(w, `{"email": "vss@project-1.iam.gserviceaccount.com"}`)
+		case "POST /crm/projects/project-1:getIamPolicy":
+			fmt.Fprint(w, `{"version": 1, "etag": "abc", "bindings": [{"role": "roles/viewer", "members": ["user:admin@example.com"]}]}`)
+		case "POST /crm/projects/project-1:setIamPolicy":
+			policy = r.Body["policy"].(map[string]interface{})
+			fmt.Fprint(w, `{}`)
+		case "POST /iam/projects/project-1/serviceAccounts/vss@project-1.iam.gserviceaccount.com/keys":
+			fmt.Fprintf(w, `{"privateKeyData": "%s"}`, base64.StdEncoding.EncodeToString([]byte(key)))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	email, created, err := s.CreateNewRole(context.Background(), &client.RoleCreationInfo{RoleName: "vss"})
+	assert.Nil(t, err)
+	assert.Equal(t, "vss@project-1.iam.gserviceaccount.com", email)
+	assert.Equal(t, key, created)
+	assert.Len(t, *requests, 4)
+	assert.Equal(t, "vss", (*requests)[0].Body["accountId"])
+
+	assert.Equal(t, "abc", policy["etag"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"role": "roles/viewer", "members": []interface{}{"user:admin@example.com", "serviceAccount:vss@project-1.iam.gserviceaccount.com"}},
+		map[string]interface{}{"role": "roles/iam.securityReviewer", "members": []interface{}{"serviceAccount:vss@project-1.iam.gserviceaccount.com"}},
+	}, policy["bindings"])
+}
+
+func TestCreateNewRoleWorkloadIdentity(t *testing.T) {
+	var principal interface{}
+	s, _ := newTestService(t, &NewServiceInput{
+		ProjectID:                "project-1",
+		WorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/vss/providers/aws",
+		WorkloadIdentitySubject:  "attribute.aws_role/arn:aws:sts::111111111111:assumed-role/vss",
+	}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "POST /iam/projects/project-1/serviceAccounts":
+			fmt.Fprint(w, `{"email": "vss@project-1.iam.gserviceaccount.com"}`)
+		case "POST /crm/projects/project-1:getIamPolicy", "POST /iam/projects/project-1/serviceAccounts/vss@project-1.iam.gserviceaccount.com:getIamPolicy":
+			fmt.Fprint(w, `{}`)
+		case "POST /crm/projects/project-1:setIamPolicy":
+			fmt.Fprint(w, `{}`)
+		case "POST /iam/projects/project-1/serviceAccounts/vss@project-1.iam.gserviceaccount.com:setIamPolicy":
+			bindings := r.Body["policy"].(map[string]interface{})["bindings"].([]interface{})
+			principal = bindings[0].(map[string]interface{})["members"].([]interface{})[0]
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	email, key, err := s.CreateNewRole(context.Background(), &client.RoleCreationInfo{RoleName: "vss", Policy: "roles/viewer"})
+	assert.Nil(t, err)
+	assert.Equal(t, "vss@project-1.iam.gserviceaccount.com", email)
+	assert.Equal(t, "", key)
+	assert.Equal(t, "principalSet://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/vss/attribute.aws_role/arn:aws:sts::111111111111:assumed-role/vss", principal)
+}
+
+func TestCreateNewRoleFailure(t *testing.T) {
+	s, requests := newTestService(t, &NewServiceInput{ProjectID: "project-1"}, func(w http.ResponseWriter, r *request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error": {"code": 403, "message": "Permission denied", "status": "PERMISSION_DENIED"}}`)
+	})
+
+	_, _, err := s.CreateNewRole(context.Background(), &client.RoleCreationInfo{RoleName: "vss"})
+	assert.EqualError(t, err, "Create service account vss failed, Permission denied (403 PERMISSION_DENIED)")
+	assert.Len(t, *requests, 1)
+}
+
+func TestCreateServiceAccountKeyFailure(t *testing.T) {
+	s, _ := newTestService(t, &NewServiceInput{ProjectID: "project-1"}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "POST /iam/projects/project-1/serviceAccounts":
+			fmt.Fprint(w, `{"email": "vss@project-1.iam.gserviceaccount.com"}`)
+		case "POST /iam/projects/project-1/serviceAccounts/vss@project-1.iam.gserviceaccount.com/keys":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": {"code": 400, "message": "Key creation is disabled", "status": "FAILED_PRECONDITION"}}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	// the service account is returned so that it can be deleted again
+	account, err := s.CreateServiceAccount(context.Background(), &client.RoleCreationInfo{RoleName: "vss"})
+	assert.EqualError(t, err, "Create key for vss@project-1.iam.gserviceaccount.com failed, Key creation is disabled (400 FAILED_PRECONDITION)")
+	assert.Equal(t, &client.ServiceAccount{Email: "vss@project-1.iam.gserviceaccount.com"}, account)
+}
+
+func TestDeleteRole(t *testing.T) {
+	var bindings interface{}
+	s, requests := newTestService(t, &NewServiceInput{ProjectID: "project-1"}, func(w http.ResponseWriter, r *request) {
+		switch r.Method + " " + r.Path {
+		case "POST /crm/projects/project-1:getIamPolicy":
+			fmt.Fprint(w, `{"bindings": [
+				{"role": "roles/viewer", "members": ["user:admin@example.com", "serviceAccount:vss@project-1.iam.gserviceaccount.com"]},
+				{"role": "roles/iam.securityReviewer", "members": ["serviceAccount:vss@project-1.iam.gserviceaccount.com"]}
+			]}`)
+		case "POST /crm/projects/project-1:setIamPolicy":
+			bindings = r.Body["policy"].(map[string]interface{})["bindings"]
+			fmt.Fprint(w, `{}`)
+		case "DELETE /iam/projects/project-1/serviceAccounts/vss@project-1.iam.gserviceaccount.com":
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+	})
+
+	assert.Nil(t, s.role.DeleteRole(context.Background(), "vss"))
+	assert.Len(t, *requests, 3)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"role": "roles/viewer", "members": []interface{}{"user:admin@example.com"}},
+	}, bindings)
+}
+
+func TestUpdatePolicyRetriesConflicts(t *testing.T) {
+	sets := 0
+	s, _ := newTestService(t, &NewServiceInput{ProjectID: "project-1"}, func(w http.ResponseWriter, r *request) {
+		switch r.Path {
+		case "/crm/projects/project-1:getIamPolicy":
+			fmt.Fprint(w, `{}`)
+		case "/crm/projects/project-1:setIamPolicy":
+			if sets++; sets == 1 {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"error": {"code": 409, "message": "Concurrent policy changes", "status": "ABORTED"}}`)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	api := s.role.api
+	err := api.updatePolicy(context.Background(), "POST", api.endpoints.ResourceManager+"projects/project-1", func(policy *iamPolicy) bool {
+		return policy.addMember("roles/viewer", "user:admin@example.com")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, sets)
+}
+
+func TestWorkloadIdentityPrincipal(t *testing.T) {
+	provider := "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/vss/providers/aws"
+	principal, err := workloadIdentityPrincipal(provider, "vss-subject")
+	assert.Nil(t, err)
+	assert.Equal(t, "principal://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/vss/subject/vss-subject", principal)
+
+	principal, err = workloadIdentityPrincipal(provider, "attribute.account/111111111111")
+	assert.Nil(t, err)
+	assert.Equal(t, "principalSet://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/vss/attribute.account/111111111111", principal)
+
+	// the whole pool isn't allowed
+	for _, subject := range []string{"", "attribute.account", "attribute./x", "attribute.account/"} {
+		_, err = workloadIdentityPrincipal(provider, subject)
+		assert.NotNil(t, err, subject)
+	}
+	_, err = workloadIdentityPrincipal("vss/aws", "vss-subject")
+	assert.NotNil(t, err)
+}
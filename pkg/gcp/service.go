@@ -0,0 +1,81 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/CloudCoreo/cli/client"
+)
+
+// DefaultRoles are the roles granted to new service accounts, read access to
+// the project and its IAM policies
+const DefaultRoles = "roles/viewer,roles/iam.securityReviewer"
+
+//NewServiceInput contains the info needed to act in a GCP project
+type NewServiceInput struct {
+	ProjectID string
+	// CredentialsFile is a service account key or an authorized user file,
+	// GOOGLE_APPLICATION_CREDENTIALS or the gcloud application default
+	// credentials are used when it is empty
+	CredentialsFile string
+	// WorkloadIdentityProvider is the resource name of the workload identity
+	// pool provider allowed to impersonate new service accounts, which get no
+	// key when it is set
+	WorkloadIdentityProvider string
+	// WorkloadIdentitySubject is the identity of the pool allowed to
+	// impersonate, a subject or an attribute.NAME/VALUE of its identities
+	WorkloadIdentitySubject string
+	// HTTPClient sends the API requests, http.DefaultClient is used when it is nil.
+	HTTPClient *http.Client
+}
+
+//Service contains the GCP role, setup and remove services
+type Service struct {
+	role   *RoleService
+	setup  *SetupService
+	remove *RemoveService
+}
+
+// NewService returns a new GCP service group
+func NewService(input *NewServiceInput) *Service {
+	api := newRESTAPI(input)
+	return &Service{
+		role: &RoleService{
+			api:                      api,
+			projectID:                input.ProjectID,
+			workloadIdentityProvider: input.WorkloadIdentityProvider,
+			workloadIdentitySubject:  input.WorkloadIdentitySubject,
+		},
+		setup:  &SetupService{api: api, projectID: input.ProjectID},
+		remove: &RemoveService{api: api, projectID: input.ProjectID},
+	}
+}
+
+// SetupEventStream calls the SetupEventStream function in SetupService
+func (s *Service) SetupEventStream(ctx context.Context, input *client.EventStreamConfig) error {
+	return s.setup.SetupEventStream(ctx, input)
+}
+
+// CreateNewRole creates the service account, it returns its email and key
+func (s *Service) CreateNewRole(ctx context.Context, input *client.RoleCreationInfo) (arn string, externalID string, err error) {
+	account, err := s.role.CreateServiceAccount(ctx, input)
+	if account == nil {
+		return "", "", err
+	}
+	return account.Email, account.Key, err
+}
+
+// CreateServiceAccount calls the CreateServiceAccount function in RoleService
+func (s *Service) CreateServiceAccount(ctx context.Context, input *client.RoleCreationInfo) (*client.ServiceAccount, error) {
+	return s.role.CreateServiceAccount(ctx, input)
+}
+
+// DeleteRole calls the DeleteRole function in RoleService
+func (s *Service) DeleteRole(ctx context.Context, roleName string) error {
+	return s.role.DeleteRole(ctx, roleName)
+}
+
+//RemoveEventStream calls the RemoveEventStream function in RemoveService
+func (s *Service) RemoveEventStream(ctx context.Context, input *client.EventRemoveConfig) error {
+	return s.remove.RemoveEventStream(ctx, input)
+}
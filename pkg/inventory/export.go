@@ -0,0 +1,69 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/CloudCoreo/cli/client"
+	"gopkg.in/yaml.v2"
+)
+
+// secretsNote heads exported YAML documents, secrets can't be read back from the API
+const secretsNote = "# Azure keys and GCP service account keys are left out of the export, add them before importing the accounts into another organization.\n"
+
+// FromCloudAccount returns the definition of an existing cloud account.
+// Fields managed by the server are left out and secrets are redacted.
+func FromCloudAccount(cloud *client.CloudAccount) Account {
+	scanEnabled := cloud.ScanEnabled
+	account := Account{
+		Name:           cloud.Name,
+		Provider:       cloud.Provider,
+		AccountID:      cloud.AccountID,
+		Environment:    cloud.Environment,
+		Tags:           cloud.Tags,
+		Draft:          cloud.IsDraft,
+		Email:          cloud.Email,
+		UserName:       cloud.UserName,
+		ScanEnabled:    &scanEnabled,
+		ScanInterval:   cloud.ScanInterval,
+		ScanRegion:     cloud.ScanRegion,
+		RoleArn:        cloud.Arn,
+		ExternalID:     cloud.ExternalID,
+		ApplicationID:  cloud.ApplicationID,
+		DirectoryID:    cloud.DirectoryID,
+		SubscriptionID: cloud.SubscriptionID,
+
+		ProjectID:                cloud.ProjectID,
+		ServiceAccountEmail:      cloud.ServiceAccountEmail,
+		WorkloadIdentityProvider: cloud.WorkloadIdentityProvider,
+	}
+	if account.AccountID == account.SubscriptionID || account.AccountID == account.ProjectID {
+		// Azure and GCP accounts are identified by their subscription and project
+		account.AccountID = ""
+	}
+	return account
+}
+
+// Marshal encodes accounts as a Document in the YAML or JSON format
+func Marshal(accounts []Account, format string) ([]byte, error) {
+	if accounts == nil {
+		accounts = []Account{}
+	}
+	doc := Document{Version: Version, Accounts: accounts}
+
+	switch format {
+	case FormatYAML:
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(secretsNote), b...), nil
+	case FormatJSON:
+		b, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+	return nil, fmt.Errorf("unsupported inventory format %s, expected yaml or json", format)
+}
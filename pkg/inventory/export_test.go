@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportedAccounts() []*client.CloudAccount {
+	return []*client.CloudAccount{
+		{
+			ID:        "id1",
+			AccountID: "111111111111",
+			RoleName:  "vss-role",
+			CloudInfo: client.CloudInfo{
+				Name: "prod", Provider: "AWS", Arn: "arn:aws:iam::111111111111:role/vss-role", ExternalID: "ext",
+				Environment: "Production", Tags: []string{"team:a"}, ScanEnabled: true, ScanInterval: "Weekly", ScanRegion: "All",
+				IsValid: true, LastValidationCheck: "2020-01-01T00:00:00Z",
+			},
+		},
+		{
+			ID:        "id2",
+			AccountID: "sub1",
+			CloudInfo: client.CloudInfo{
+				Name: "azure", Provider: "Azure", KeyValue: "secret-key", ApplicationID: "app1", DirectoryID: "dir1", SubscriptionID: "sub1",
+			},
+		},
+		{
+			ID:        "id3",
+			AccountID: "project-1",
+			CloudInfo: client.CloudInfo{
+				Name: "gcp", Provider: "GCP", ProjectID: "project-1", ServiceAccountEmail: "vss@project-1.iam.gserviceaccount.com",
+				ServiceAccountKey: "secret-sa-key",
+			},
+		},
+	}
+}
+
+func TestMarshalRedactsAndOmitsServerFields(t *testing.T) {
+	var accounts []Account
+	for _, cloud := range exportedAccounts() {
+		accounts = append(accounts, FromCloudAccount(cloud))
+	}
+
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		b, err := Marshal(accounts, format)
+		assert.Nil(t, err)
+		for _, field := range []string{"secret-key", "secret-sa-key", "_id", "id1", "isValid", "lastValidationCheck", "roleName"} {
+			assert.NotContains(t, string(b), field, format)
+		}
+		assert.Contains(t, string(b), "v1")
+	}
+}
+
+func TestMarshalRoundTrips(t *testing.T) {
+	var accounts []Account
+	for _, cloud := range exportedAccounts() {
+		accounts = append(accounts, FromCloudAccount(cloud))
+	}
+
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		b, err := Marshal(accounts, format)
+		assert.Nil(t, err)
+
+		parsed, err := Parse(bytes.NewReader(b), format)
+		assert.Nil(t, err)
+		assert.Equal(t, accounts, parsed, format)
+
+		changes, err := Plan(parsed, exportedAccounts(), true)
+		assert.Nil(t, err)
+		assert.Empty(t, changes, format)
+	}
+}
+
+func TestMarshalUnsupportedFormat(t *testing.T) {
+	_, err := Marshal(nil, FormatCSV)
+	assert.NotNil(t, err)
+}
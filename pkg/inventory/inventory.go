@@ -0,0 +1,265 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Version is the version of the inventory document format
+const Version = "v1"
+
+// Formats of inventory files
+const (
+	FormatCSV  = "csv"
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Account is the definition of a cloud account in an inventory file
+type Account struct {
+	Name        string   `yaml:"name" json:"name"`
+	Provider    string   `yaml:"provider,omitempty" json:"provider,omitempty"`
+	AccountID   string   `yaml:"accountId,omitempty" json:"accountId,omitempty"`
+	Environment string   `yaml:"environment,omitempty" json:"environment,omitempty"`
+	Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Draft       bool     `yaml:"draft,omitempty" json:"draft,omitempty"`
+	Email       string   `yaml:"email,omitempty" json:"email,omitempty"`
+	UserName    string   `yaml:"username,omitempty" json:"username,omitempty"`
+	EventStream bool     `yaml:"eventStream,omitempty" json:"eventStream,omitempty"`
+
+	// Scan settings, left to the server defaults when not set
+	ScanEnabled  *bool  `yaml:"scanEnabled,omitempty" json:"scanEnabled,omitempty"`
+	ScanInterval string `yaml:"scanInterval,omitempty" json:"scanInterval,omitempty"`
+	ScanRegion   string `yaml:"scanRegion,omitempty" json:"scanRegion,omitempty"`
+
+	// AWS role, either RoleName to create a new role or RoleArn and ExternalID of an existing one
+	RoleName       string `yaml:"roleName,omitempty" json:"roleName,omitempty"`
+	RoleArn        string `yaml:"roleArn,omitempty" json:"roleArn,omitempty"`
+	ExternalID     string `yaml:"externalId,omitempty" json:"externalId,omitempty"`
+	Policy         string `yaml:"policy,omitempty" json:"policy,omitempty"`
+	AwsProfile     string `yaml:"awsProfile,omitempty" json:"awsProfile,omitempty"`
+	AwsProfilePath string `yaml:"awsProfilePath,omitempty" json:"awsProfilePath,omitempty"`
+
+	// Azure application credentials
+	KeyValue       string `yaml:"key,omitempty" json:"key,omitempty"`
+	ApplicationID  string `yaml:"applicationId,omitempty" json:"applicationId,omitempty"`
+	DirectoryID    string `yaml:"directoryId,omitempty" json:"directoryId,omitempty"`
+	SubscriptionID string `yaml:"subscriptionId,omitempty" json:"subscriptionId,omitempty"`
+	AuthFile       string `yaml:"authFile,omitempty" json:"authFile,omitempty"`
+	Region         string `yaml:"region,omitempty" json:"region,omitempty"`
+
+	// GCP service account, either RoleName to create a new one with the roles of
+	// Policy, ServiceAccountKey with the path of the JSON key of an existing one
+	// or ServiceAccountEmail with a WorkloadIdentityProvider. A new one is
+	// impersonated by the WorkloadIdentitySubject of the provider's pool.
+	ProjectID                string `yaml:"projectId,omitempty" json:"projectId,omitempty"`
+	ServiceAccountEmail      string `yaml:"serviceAccountEmail,omitempty" json:"serviceAccountEmail,omitempty"`
+	ServiceAccountKey        string `yaml:"serviceAccountKey,omitempty" json:"serviceAccountKey,omitempty"`
+	WorkloadIdentityProvider string `yaml:"workloadIdentityProvider,omitempty" json:"workloadIdentityProvider,omitempty"`
+	WorkloadIdentitySubject  string `yaml:"workloadIdentitySubject,omitempty" json:"workloadIdentitySubject,omitempty"`
+	GCPCredentials           string `yaml:"gcpCredentials,omitempty" json:"gcpCredentials,omitempty"`
+}
+
+// Document is an inventory file
+type Document struct {
+	Version  string    `yaml:"version" json:"version"`
+	Accounts []Account `yaml:"accounts" json:"accounts"`
+}
+
+// ProviderAccountID returns the cloud provider account ID of the account, taken
+// from the subscription, the project or the role ARN when AccountID isn't given
+func (a Account) ProviderAccountID() string {
+	switch {
+	case a.AccountID != "":
+		return a.AccountID
+	case a.SubscriptionID != "":
+		return a.SubscriptionID
+	case a.ProjectID != "":
+		return a.ProjectID
+	case a.RoleArn != "":
+		// arn:aws:iam::123456789012:role/name
+		if parts := strings.Split(a.RoleArn, ":"); len(parts) > 4 {
+			return parts[4]
+		}
+	}
+	return ""
+}
+
+// FormatOf returns the format of an inventory file by its extension
+func FormatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unsupported inventory file %s, expected a .csv, .yaml, .yml or .json file", path)
+}
+
+// Load reads the accounts of an inventory file
+func Load(path string) ([]Account, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(b), format)
+}
+
+// Parse reads the accounts of an inventory in the given format. YAML and JSON
+// inventories are either a Document or a list of accounts, CSV inventories
+// have a header row naming the account fields.
+func Parse(r io.Reader, format string) ([]Account, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatYAML, FormatJSON:
+		// JSON is a subset of YAML
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return parseYAML(b)
+	}
+	return nil, fmt.Errorf("unsupported inventory format %s", format)
+}
+
+func parseYAML(b []byte) ([]Account, error) {
+	var list []Account
+	if err := yaml.UnmarshalStrict(b, &list); err == nil {
+		return list, nil
+	}
+
+	doc := Document{}
+	if err := yaml.UnmarshalStrict(b, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != "" && doc.Version != Version {
+		return nil, fmt.Errorf("unsupported inventory version %s, expected %s", doc.Version, Version)
+	}
+	return doc.Accounts, nil
+}
+
+func parseCSV(r io.Reader) ([]Account, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setters := make([]func(*Account, string) error, len(header))
+	for i, column := range header {
+		setter, ok := csvColumns[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q in CSV header", column)
+		}
+		setters[i] = setter
+	}
+
+	var accounts []Account
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return accounts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		account := Account{}
+		for i, value := range record {
+			if err := setters[i](&account, strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("row %d, column %s: %v", row, header[i], err)
+			}
+		}
+		accounts = append(accounts, account)
+	}
+}
+
+func setString(field func(*Account) *string) func(*Account, string) error {
+	return func(a *Account, value string) error {
+		*field(a) = value
+		return nil
+	}
+}
+
+func setBool(field func(*Account) *bool) func(*Account, string) error {
+	return func(a *Account, value string) error {
+		if value == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(a) = b
+		return nil
+	}
+}
+
+// csvColumns sets the account field of each CSV column, named as in YAML in lower case
+var csvColumns = map[string]func(*Account, string) error{
+	"name":        setString(func(a *Account) *string { return &a.Name }),
+	"provider":    setString(func(a *Account) *string { return &a.Provider }),
+	"accountid":   setString(func(a *Account) *string { return &a.AccountID }),
+	"environment": setString(func(a *Account) *string { return &a.Environment }),
+	"tags": func(a *Account, value string) error {
+		// tags are separated by | as in the --tags flag
+		if value != "" {
+			a.Tags = strings.Split(value, "|")
+		}
+		return nil
+	},
+	"draft":       setBool(func(a *Account) *bool { return &a.Draft }),
+	"email":       setString(func(a *Account) *string { return &a.Email }),
+	"username":    setString(func(a *Account) *string { return &a.UserName }),
+	"eventstream": setBool(func(a *Account) *bool { return &a.EventStream }),
+	"scanenabled": func(a *Account, value string) error {
+		// empty keeps the server default
+		if value == "" {
+			return nil
+		}
+		a.ScanEnabled = new(bool)
+		return setBool(func(a *Account) *bool { return a.ScanEnabled })(a, value)
+	},
+	"scaninterval":   setString(func(a *Account) *string { return &a.ScanInterval }),
+	"scanregion":     setString(func(a *Account) *string { return &a.ScanRegion }),
+	"rolename":       setString(func(a *Account) *string { return &a.RoleName }),
+	"rolearn":        setString(func(a *Account) *string { return &a.RoleArn }),
+	"externalid":     setString(func(a *Account) *string { return &a.ExternalID }),
+	"policy":         setString(func(a *Account) *string { return &a.Policy }),
+	"awsprofile":     setString(func(a *Account) *string { return &a.AwsProfile }),
+	"awsprofilepath": setString(func(a *Account) *string { return &a.AwsProfilePath }),
+	"key":            setString(func(a *Account) *string { return &a.KeyValue }),
+	"applicationid":  setString(func(a *Account) *string { return &a.ApplicationID }),
+	"directoryid":    setString(func(a *Account) *string { return &a.DirectoryID }),
+	"subscriptionid": setString(func(a *Account) *string { return &a.SubscriptionID }),
+	"authfile":       setString(func(a *Account) *string { return &a.AuthFile }),
+	"region":         setString(func(a *Account) *string { return &a.Region }),
+
+	"projectid":                setString(func(a *Account) *string { return &a.ProjectID }),
+	"serviceaccountemail":      setString(func(a *Account) *string { return &a.ServiceAccountEmail }),
+	"serviceaccountkey":        setString(func(a *Account) *string { return &a.ServiceAccountKey }),
+	"workloadidentityprovider": setString(func(a *Account) *string { return &a.WorkloadIdentityProvider }),
+	"workloadidentitysubject":  setString(func(a *Account) *string { return &a.WorkloadIdentitySubject }),
+	"gcpcredentials":           setString(func(a *Account) *string { return &a.GCPCredentials }),
+}
@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	csv := `Name,Provider,RoleArn,ExternalId,Environment,Tags,Draft
+# comment lines are skipped
+"Prod, US",AWS,arn:aws:iam::111111111111:role/vss,ext1,Production,team:a|env:prod,true
+dev,AWS,arn:aws:iam::222222222222:role/vss,ext2,Development,,
+`
+	accounts, err := Parse(strings.NewReader(csv), FormatCSV)
+	assert.Nil(t, err)
+	assert.Equal(t, []Account{
+		{
+			Name:        "Prod, US",
+			Provider:    "AWS",
+			RoleArn:     "arn:aws:iam::111111111111:role/vss",
+			ExternalID:  "ext1",
+			Environment: "Production",
+			Tags:        []string{"team:a", "env:prod"},
+			Draft:       true,
+		},
+		{
+			Name:        "dev",
+			Provider:    "AWS",
+			RoleArn:     "arn:aws:iam::222222222222:role/vss",
+			ExternalID:  "ext2",
+			Environment: "Development",
+		},
+	}, accounts)
+}
+
+func TestParseCSVGCP(t *testing.T) {
+	csv := `name,provider,projectId,roleName,policy,gcpCredentials
+gcp,GCP,project-1,vss,roles/viewer,admin.json
+`
+	accounts, err := Parse(strings.NewReader(csv), FormatCSV)
+	assert.Nil(t, err)
+	assert.Equal(t, []Account{{
+		Name:           "gcp",
+		Provider:       "GCP",
+		ProjectID:      "project-1",
+		RoleName:       "vss",
+		Policy:         "roles/viewer",
+		GCPCredentials: "admin.json",
+	}}, accounts)
+	assert.Equal(t, "project-1", accounts[0].ProviderAccountID())
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("name,color\ndev,blue\n"), FormatCSV)
+	assert.Contains(t, err.Error(), `unknown column "color"`)
+
+	_, err = Parse(strings.NewReader("name,draft\ndev,maybe\n"), FormatCSV)
+	assert.Contains(t, err.Error(), "row 1, column draft")
+}
+
+func TestParseYAML(t *testing.T) {
+	list := `
+- name: dev
+  roleName: vss-role
+  eventStream: true
+`
+	accounts, err := Parse(strings.NewReader(list), FormatYAML)
+	assert.Nil(t, err)
+	assert.Equal(t, []Account{{Name: "dev", RoleName: "vss-role", EventStream: true}}, accounts)
+
+	doc := `
+version: v1
+accounts:
+- name: azure
+  provider: Azure
+  subscriptionId: sub1
+`
+	accounts, err = Parse(strings.NewReader(doc), FormatYAML)
+	assert.Nil(t, err)
+	assert.Equal(t, []Account{{Name: "azure", Provider: "Azure", SubscriptionID: "sub1"}}, accounts)
+
+	accounts, err = Parse(strings.NewReader(`{"version": "v1", "accounts": [{"name": "dev", "draft": true}]}`), FormatJSON)
+	assert.Nil(t, err)
+	assert.Equal(t, []Account{{Name: "dev", Draft: true}}, accounts)
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("version: v2\naccounts: []\n"), FormatYAML)
+	assert.Contains(t, err.Error(), "unsupported inventory version v2")
+
+	_, err = Parse(strings.NewReader("- name: dev\n  colour: blue\n"), FormatYAML)
+	assert.NotNil(t, err)
+
+	_, err = FormatOf("accounts.txt")
+	assert.NotNil(t, err)
+}
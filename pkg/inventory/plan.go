@@ -0,0 +1,145 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CloudCoreo/cli/client"
+)
+
+// Actions of the changes of a plan
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// FieldChange is a field of a cloud account changed by an update
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Change is a change to the cloud accounts needed to match the inventory
+type Change struct {
+	Action  string        `json:"action"`
+	Name    string        `json:"name"`
+	CloudID string        `json:"cloudId,omitempty"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+
+	// Account is the account of the inventory, nil for deletions
+	Account *Account `json:"-"`
+	// Current is the existing cloud account, nil for creations
+	Current *client.CloudAccount `json:"-"`
+}
+
+// Plan returns the changes turning the current cloud accounts into the
+// accounts of the inventory. Accounts are matched by their provider account ID
+// and then by name, fields left out of the inventory are not compared. The
+// event stream isn't compared since the API doesn't report it.
+// Current accounts missing from the inventory are only deleted with prune.
+func Plan(desired []Account, current []*client.CloudAccount, prune bool) ([]Change, error) {
+	byID := make(map[string]*client.CloudAccount)
+	byName := make(map[string]*client.CloudAccount)
+	for _, cloud := range current {
+		if id := currentAccountID(cloud); id != "" {
+			byID[id] = cloud
+		}
+		byName[cloud.Name] = cloud
+	}
+
+	var changes []Change
+	names := make(map[string]bool)
+	matched := make(map[*client.CloudAccount]string)
+	for i := range desired {
+		account := &desired[i]
+		if account.Name == "" {
+			return nil, fmt.Errorf("account %d of the inventory has no name", i+1)
+		}
+		if names[account.Name] {
+			return nil, fmt.Errorf("account %s is listed more than once in the inventory", account.Name)
+		}
+		names[account.Name] = true
+
+		cloud := byID[account.ProviderAccountID()]
+		if cloud == nil {
+			cloud = byName[account.Name]
+		}
+		if cloud == nil {
+			changes = append(changes, Change{Action: ActionCreate, Name: account.Name, Account: account})
+			continue
+		}
+		if other, ok := matched[cloud]; ok {
+			return nil, fmt.Errorf("accounts %s and %s of the inventory both match cloud account %s", other, account.Name, cloud.ID)
+		}
+		matched[cloud] = account.Name
+
+		if account.Provider != "" && !strings.EqualFold(account.Provider, cloud.Provider) {
+			return nil, fmt.Errorf("can't change the provider of %s from %s to %s, delete and add the account instead", account.Name, cloud.Provider, account.Provider)
+		}
+		if fields := diff(account, cloud); len(fields) > 0 {
+			changes = append(changes, Change{Action: ActionUpdate, Name: account.Name, CloudID: cloud.ID, Fields: fields, Account: account, Current: cloud})
+		}
+	}
+
+	if prune {
+		var deletes []Change
+		for _, cloud := range current {
+			if _, ok := matched[cloud]; !ok {
+				deletes = append(deletes, Change{Action: ActionDelete, Name: cloud.Name, CloudID: cloud.ID, Current: cloud})
+			}
+		}
+		sort.SliceStable(deletes, func(i, j int) bool { return deletes[i].Name < deletes[j].Name })
+		changes = append(changes, deletes...)
+	}
+
+	return changes, nil
+}
+
+func currentAccountID(cloud *client.CloudAccount) string {
+	if cloud.AccountID != "" {
+		return cloud.AccountID
+	}
+	if cloud.SubscriptionID != "" {
+		return cloud.SubscriptionID
+	}
+	return cloud.ProjectID
+}
+
+// diff compares the fields set in the inventory to the current account
+func diff(account *Account, cloud *client.CloudAccount) []FieldChange {
+	var fields []FieldChange
+	compare := func(field, from, to string) {
+		if from != to {
+			fields = append(fields, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	compare("name", cloud.Name, account.Name)
+	if account.Environment != "" {
+		compare("environment", cloud.Environment, account.Environment)
+	}
+	if len(account.Tags) > 0 {
+		compare("tags", joinTags(cloud.Tags), joinTags(account.Tags))
+	}
+	if account.ScanEnabled != nil {
+		compare("scanEnabled", strconv.FormatBool(cloud.ScanEnabled), strconv.FormatBool(*account.ScanEnabled))
+	}
+	if account.ScanInterval != "" {
+		compare("scanInterval", cloud.ScanInterval, account.ScanInterval)
+	}
+	if account.ScanRegion != "" {
+		compare("scanRegion", cloud.ScanRegion, account.ScanRegion)
+	}
+	return fields
+}
+
+// joinTags joins tags in sorted order, their order doesn't matter
+func joinTags(tags []string) string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "|")
+}
@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/CloudCoreo/cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func currentAccounts() []*client.CloudAccount {
+	return []*client.CloudAccount{
+		{ID: "id1", AccountID: "111111111111", CloudInfo: client.CloudInfo{Name: "prod", Provider: "AWS", Environment: "Production", Tags: []string{"b", "a"}, ScanEnabled: true, ScanInterval: "Weekly"}},
+		{ID: "id2", AccountID: "222222222222", CloudInfo: client.CloudInfo{Name: "dev", Provider: "AWS", Environment: "Development"}},
+		{ID: "id3", CloudInfo: client.CloudInfo{Name: "azure", Provider: "Azure", SubscriptionID: "sub1"}},
+	}
+}
+
+func TestPlanNoChanges(t *testing.T) {
+	desired := []Account{
+		{Name: "prod", Environment: "Production", Tags: []string{"a", "b"}, ScanInterval: "Weekly"},
+		{Name: "dev"},
+		{Name: "azure", Provider: "azure"},
+	}
+	changes, err := Plan(desired, currentAccounts(), true)
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestPlanChanges(t *testing.T) {
+	disabled := false
+	desired := []Account{
+		{Name: "production", AccountID: "111111111111", Environment: "Production", ScanEnabled: &disabled},
+		{Name: "dev", Environment: "Test", Tags: []string{"team:a"}},
+		{Name: "new", RoleArn: "arn:aws:iam::333333333333:role/vss"},
+	}
+
+	changes, err := Plan(desired, currentAccounts(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(changes))
+	assert.Equal(t, ActionUpdate, changes[0].Action)
+	assert.Equal(t, "id1", changes[0].CloudID)
+	assert.Equal(t, []FieldChange{
+		{Field: "name", From: "prod", To: "production"},
+		{Field: "scanEnabled", From: "true", To: "false"},
+	}, changes[0].Fields)
+	assert.Equal(t, []FieldChange{
+		{Field: "environment", From: "Development", To: "Test"},
+		{Field: "tags", From: "", To: "team:a"},
+	}, changes[1].Fields)
+	assert.Equal(t, ActionCreate, changes[2].Action)
+	assert.Equal(t, "new", changes[2].Account.Name)
+
+	changes, err = Plan(desired, currentAccounts(), true)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(changes))
+	assert.Equal(t, Change{Action: ActionDelete, Name: "azure", CloudID: "id3", Current: currentAccounts()[2]}, changes[3])
+}
+
+func TestPlanEventStream(t *testing.T) {
+	current := currentAccounts()
+	desired := []Account{{Name: current[0].Name, EventStream: true}, {Name: current[1].Name, EventStream: true}}
+
+	changes, err := Plan(desired, current, false)
+	assert.Nil(t, err)
+	assert.Empty(t, changes, "the event stream of existing accounts isn't compared")
+}
+
+func TestPlanErrors(t *testing.T) {
+	_, err := Plan([]Account{{Name: "dev"}, {Name: "dev"}}, currentAccounts(), false)
+	assert.Contains(t, err.Error(), "more than once")
+
+	_, err = Plan([]Account{{Name: "dev", Provider: "Azure"}}, currentAccounts(), false)
+	assert.Contains(t, err.Error(), "can't change the provider")
+
+	_, err = Plan([]Account{{Name: "dev"}, {Name: "other", AccountID: "222222222222"}}, currentAccounts(), false)
+	assert.Contains(t, err.Error(), "both match")
+
+	_, err = Plan([]Account{{Environment: "Test"}}, currentAccounts(), false)
+	assert.Contains(t, err.Error(), "no name")
+}
@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Authorize adds the credentials of the API to a request
+type Authorize func(req *http.Request) (*http.Request, error)
+
+// Error is an error returned by a Google Cloud, Microsoft Graph or Azure
+// Resource Manager API, Code is the status of Google Cloud errors
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// IsStatus reports whether err is an Error with the HTTP status code
+func IsStatus(err error, statusCode int) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == statusCode
+}
+
+// IsCode reports whether err is an Error with the code
+func IsCode(err error, code string) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == code
+}
+
+// Do sends a request with a JSON body to url and decodes the JSON response
+// into out. These are the cloud APIs the vendored dependencies have no client for.
+func Do(ctx context.Context, client *http.Client, authorize Authorize, method, url string, in, out interface{}) error {
+	body := &bytes.Buffer{}
+	if in != nil {
+		if err := json.NewEncoder(body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req, err = authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return decodeError(resp.StatusCode, b)
+	}
+	if out != nil && len(b) > 0 {
+		return json.Unmarshal(b, out)
+	}
+	return nil
+}
+
+// decodeError returns the error of a response body. Azure errors have a code
+// string, Google Cloud errors the status code as code and a status string.
+func decodeError(statusCode int, b []byte) error {
+	e := struct {
+		Error struct {
+			Code    interface{} `json:"code"`
+			Status  string      `json:"status"`
+			Message string      `json:"message"`
+		} `json:"error"`
+	}{}
+	apiErr := &Error{StatusCode: statusCode}
+	if json.Unmarshal(b, &e) == nil {
+		apiErr.Message = e.Error.Message
+		apiErr.Code = e.Error.Status
+		if code, ok := e.Error.Code.(string); ok && apiErr.Code == "" {
+			apiErr.Code = code
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(b))
+	}
+	return apiErr
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		fmt.Fprint(w, `{"name": "vss"}`)
+	}))
+	defer server.Close()
+
+	authorize := func(req *http.Request) (*http.Request, error) {
+		req.Header.Set("Authorization", "Bearer token")
+		return req, nil
+	}
+	out := struct {
+		Name string `json:"name"`
+	}{}
+	assert.Nil(t, Do(context.Background(), http.DefaultClient, authorize, "POST", server.URL, map[string]string{}, &out))
+	assert.Equal(t, "vss", out.Name)
+}
+
+func TestDecodeError(t *testing.T) {
+	// Azure
+	err := decodeError(http.StatusBadRequest, []byte(`{"error": {"code": "PrincipalNotFound", "message": "Principal does not exist"}}`))
+	assert.EqualError(t, err, "Principal does not exist (400 PrincipalNotFound)")
+	assert.True(t, IsCode(err, "PrincipalNotFound"))
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	// Google Cloud
+	err = decodeError(http.StatusForbidden, []byte(`{"error": {"code": 403, "message": "Permission denied", "status": "PERMISSION_DENIED"}}`))
+	assert.EqualError(t, err, "Permission denied (403 PERMISSION_DENIED)")
+
+	err = decodeError(http.StatusBadGateway, []byte("Bad Gateway\n"))
+	assert.EqualError(t, err, "Bad Gateway (502 )")
+}
@@ -1,4 +1,5 @@
 #!/bin/bash
+# Deprecated: use `vss cloud import -f accounts.csv --setup-events` instead, see README.md
 # input file format: account name,account id,environment,profile
 # To use this script, execute `sh cloud_add_wrapper_aws.sh < input_file` in Terminal
 # You may customize this script by modifying line 10, 23, 26, 27
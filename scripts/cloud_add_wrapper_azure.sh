@@ -1,4 +1,5 @@
 #!/bin/bash
+# Deprecated: use `vss cloud import -f accounts.csv` instead, see README.md
 # account_name,application_id,key_value,subscription_id,environment
 # To use this script, execute `sh cloud_add.sh < input_file` in Terminal
 # This script assumes account name is unique, so you can run it multiple times if adding any account fails.